@@ -36,9 +36,24 @@ type DFR2D struct {
 	IInII                utils.Matrix    // Mag face normal divided by unit triangle face norm mag, Kx3 dimension
 	EdgeNumber           []types.EdgeKey // Edge number for each edge, used to index into edge structures, Kx3 dimension
 	SolutionBasis        Basis2D
+	ElementOrder         []int // Element number in the mesh file of each element, nil unless renumbered
+	VertexOrder          []int // Vertex number in the mesh file of each vertex, nil unless renumbered
 }
 
 func NewDFR2D(N int, plotMesh bool, verbose bool, meshFileO ...string) (dfr *DFR2D) {
+	var err error
+	if dfr, err = NewDFR2DWithOrdering(N, ORDER_File, plotMesh, verbose, meshFileO...); err != nil {
+		panic(err)
+	}
+	return
+}
+
+func NewDFR2DWithOrdering(N int, mo MeshOrdering, plotMesh bool, verbose bool, meshFileO ...string) (dfr *DFR2D,
+	err error) {
+	/*
+		The elements and vertices of the mesh file are renumbered using the mesh ordering after reading. An error is
+		returned if the mesh has boundaries that can not be used, e.g. an unknown BC name
+	*/
 	if N < 0 {
 		panic(fmt.Errorf("Polynomial order must be >= 0, have %d", N))
 	}
@@ -79,40 +94,55 @@ func NewDFR2D(N int, plotMesh bool, verbose bool, meshFileO ...string) (dfr *DFR
 		case SU2_FILE:
 			dfr.K, dfr.VX, dfr.VY, EToV, dfr.BCEdges =
 				readfiles.ReadSU2(meshFileO[0], verbose)
+		case GMSH_FILE:
+			dfr.K, dfr.VX, dfr.VY, EToV, dfr.BCEdges =
+				readfiles.ReadGmsh(meshFileO[0], verbose)
 		}
 		//dfr.BCEdges.Print()
-		dfr.Tris = NewTriangulation(dfr.VX, dfr.VY, EToV, dfr.BCEdges)
-		// Build connectivity matrices
-		dfr.FluxX, dfr.FluxY =
-			CalculateElementLocalGeometry(dfr.Tris.EToV, dfr.VX, dfr.VY, dfr.FluxElement.R, dfr.FluxElement.S)
-		dfr.SolutionX, dfr.SolutionY =
-			CalculateElementLocalGeometry(dfr.Tris.EToV, dfr.VX, dfr.VY, dfr.SolutionElement.R, dfr.SolutionElement.S)
+		EToV = dfr.reorderMesh(mo, EToV, verbose)
+		if err = dfr.initializeMesh(EToV); err != nil {
+			return
+		}
 		if plotMesh {
 			readfiles.PlotMesh(dfr.VX, dfr.VY, EToV, dfr.SolutionX, dfr.SolutionY, true)
 			utils.SleepFor(50000)
 		}
-		// Calculate RT based derivative metrics for use in calculating Dx and Dy using the RT element
-		dfr.CalculateJacobian()
-		dfr.CalculateFaceNorms()
-		dfr.CalculateRTBasedDerivativeMetrics()
-		dfr.DXMetric.SetReadOnly("DXMetric")
-		dfr.DYMetric.SetReadOnly("DYMetric")
-		dfr.J.SetReadOnly("GeometricJacobian")
-		dfr.Jdet.SetReadOnly("GeometricJacobianDeterminant")
-		dfr.Jinv.SetReadOnly("GeometricJacobianInverse")
-		dfr.FluxX.SetReadOnly("FluxX")
-		dfr.FluxY.SetReadOnly("FluxY")
-		dfr.SolutionX.SetReadOnly("SolutionX")
-		dfr.SolutionY.SetReadOnly("SolutionY")
 	}
 	return
 }
 
+func (dfr *DFR2D) initializeMesh(EToV utils.Matrix) (err error) {
+	// Builds the connectivity, geometry and metrics of the mesh from the element to vertex map
+	if dfr.Tris, err = NewTriangulation(dfr.VX, dfr.VY, EToV, dfr.BCEdges); err != nil {
+		return
+	}
+	// Build connectivity matrices
+	dfr.FluxX, dfr.FluxY =
+		CalculateElementLocalGeometry(dfr.Tris.EToV, dfr.VX, dfr.VY, dfr.FluxElement.R, dfr.FluxElement.S)
+	dfr.SolutionX, dfr.SolutionY =
+		CalculateElementLocalGeometry(dfr.Tris.EToV, dfr.VX, dfr.VY, dfr.SolutionElement.R, dfr.SolutionElement.S)
+	// Calculate RT based derivative metrics for use in calculating Dx and Dy using the RT element
+	dfr.CalculateJacobian()
+	dfr.CalculateFaceNorms()
+	dfr.CalculateRTBasedDerivativeMetrics()
+	dfr.DXMetric.SetReadOnly("DXMetric")
+	dfr.DYMetric.SetReadOnly("DYMetric")
+	dfr.J.SetReadOnly("GeometricJacobian")
+	dfr.Jdet.SetReadOnly("GeometricJacobianDeterminant")
+	dfr.Jinv.SetReadOnly("GeometricJacobianInverse")
+	dfr.FluxX.SetReadOnly("FluxX")
+	dfr.FluxY.SetReadOnly("FluxY")
+	dfr.SolutionX.SetReadOnly("SolutionX")
+	dfr.SolutionY.SetReadOnly("SolutionY")
+	return
+}
+
 type MeshFileType uint8
 
 const (
 	GAMBIT_FILE MeshFileType = iota
 	SU2_FILE
+	GMSH_FILE
 )
 
 func getFileTypeFromExtension(fileName string) (t MeshFileType) {
@@ -131,6 +161,8 @@ func getFileTypeFromExtension(fileName string) (t MeshFileType) {
 		return GAMBIT_FILE
 	case "su2": // SU2 file
 		return SU2_FILE
+	case "msh": // Gmsh file
+		return GMSH_FILE
 	default:
 		err = fmt.Errorf("unsupported file type: %s", fileName)
 		panic(err)
@@ -228,12 +260,23 @@ func (dfr *DFR2D) CalculateFaceNorms() {
 	dfr.IInII = utils.NewMatrix(3, Kmax)
 	dfr.EdgeNumber = make([]types.EdgeKey, 3*Kmax)
 	for en, e := range dfr.Tris.Edges {
+		isPeriodic := e.BCType == types.BC_Periodic || e.BCType == types.BC_PeriodicReversed
 		for connNum := 0; connNum < int(e.NumConnectedTris); connNum++ {
 			k := int(e.ConnectedTris[connNum])
 			edgeNum := e.ConnectedTriEdgeNumber[connNum].Index() // one of [0,1,2], aka "First", "Second", "Third"
 			ind := k + Kmax*edgeNum
+			/*
+				A periodic pair shares one edge for flux storage: the first edge carries both tris, the second edge
+				carries only its own tri. The geometry of a tri comes from its own edge and the storage from the
+				shared edge, so that the result does not depend on the map traversal order
+			*/
+			if !isPeriodic || e.NumConnectedTris == 2 {
+				dfr.EdgeNumber[ind] = en
+			}
+			if isPeriodic && connNum == 1 {
+				continue
+			}
 			dfr.IInII.DataP[ind] = e.IInII[connNum]
-			dfr.EdgeNumber[ind] = en
 			fnD1, fnD2 := dfr.FaceNorm[0].DataP, dfr.FaceNorm[1].DataP
 			x1, x2 := GetEdgeCoordinates(en, bool(e.ConnectedTriDirection[connNum]), dfr.VX, dfr.VY)
 			dx, dy := x2[0]-x1[0], x2[1]-x1[1]
@@ -350,8 +393,6 @@ func (dfr *DFR2D) OutputMesh() (gm *graphics2D.TriMesh) {
 				Given there is no function data for the corners of the RT element, these will have to be supplied when
 				constructing the indexed function data to complement this output mesh
 	*/
-	// Triangulate the unit RT triangle: start with the bounding triangle, which includes the corners to constrain
-	// the Delaunay triangulation
 	var (
 		Kmax   = dfr.K
 		Nint   = dfr.FluxElement.NpInt
@@ -361,22 +402,7 @@ func (dfr *DFR2D) OutputMesh() (gm *graphics2D.TriMesh) {
 		ind = k + i*Kmax
 		return
 	}
-	R := []float64{-1, 1, -1} // Vertices of unit triangle
-	S := []float64{-1, -1, 1}
-	tm := geometry2D.NewTriMesh(R, S)
-	tri := &geometry2D.Tri{}
-	tri.AddEdge(tm.NewEdge([2]int{0, 1}, true))
-	e2 := tm.NewEdge([2]int{1, 2}, true)
-	tri.AddEdge(e2)
-	tri.AddEdge(tm.NewEdge([2]int{2, 0}, true))
-	tm.AddBoundingTriangle(tri)
-	// Now we add points to incrementally define the triangulation
-	for i := Nint; i < NpFlux; i++ {
-		r := dfr.FluxElement.R.DataP[i]
-		s := dfr.FluxElement.S.DataP[i]
-		tm.AddPoint(r, s)
-	}
-	gmB := tm.ToGraphMesh()
+	gmB := dfr.triangulateFluxElement()
 	gm = &gmB
 
 	// Build the X,Y coordinates to support the triangulation index
@@ -422,3 +448,50 @@ func (dfr *DFR2D) OutputMesh() (gm *graphics2D.TriMesh) {
 	gm.Attributes = nil
 	return
 }
+
+func (dfr *DFR2D) triangulateFluxElement() (gm graphics2D.TriMesh) {
+	// Triangulate the unit RT triangle: start with the bounding triangle, which includes the corners to constrain
+	// the Delaunay triangulation
+	var (
+		Nint   = dfr.FluxElement.NpInt
+		NpFlux = dfr.FluxElement.Np
+	)
+	R := []float64{-1, 1, -1} // Vertices of unit triangle
+	S := []float64{-1, -1, 1}
+	tm := geometry2D.NewTriMesh(R, S)
+	tri := &geometry2D.Tri{}
+	tri.AddEdge(tm.NewEdge([2]int{0, 1}, true))
+	e2 := tm.NewEdge([2]int{1, 2}, true)
+	tri.AddEdge(e2)
+	tri.AddEdge(tm.NewEdge([2]int{2, 0}, true))
+	tm.AddBoundingTriangle(tri)
+	// Now we add points to incrementally define the triangulation
+	for i := Nint; i < NpFlux; i++ {
+		r := dfr.FluxElement.R.DataP[i]
+		s := dfr.FluxElement.S.DataP[i]
+		tm.AddPoint(r, s)
+	}
+	gm = tm.ToGraphMesh()
+	return
+}
+
+func (dfr *DFR2D) OutputMeshTriangles() (tris [][3]int) {
+	/*
+		The triangles of OutputMesh, indexing the same vertices, without the graphics types. The order of the
+		triangles within an element follows the Delaunay triangulation and may differ from one call to the next.
+		Triangle i of element k is tris[k + i*K], with vertex indices laid out as in ConvertScalarToOutputMesh
+	*/
+	var (
+		Kmax     = dfr.K
+		baseTris = dfr.triangulateFluxElement().Triangles
+	)
+	tris = make([][3]int, Kmax*len(baseTris))
+	for k := 0; k < Kmax; k++ {
+		for i, tri := range baseTris {
+			for ii := 0; ii < 3; ii++ {
+				tris[k+i*Kmax][ii] = k + int(tri.Nodes[ii])*Kmax
+			}
+		}
+	}
+	return
+}
@@ -3,7 +3,10 @@ package DG2D
 import (
 	"fmt"
 	"image/color"
+	"io/ioutil"
 	"math"
+	"path/filepath"
+	"sort"
 	"testing"
 
 	"github.com/notargets/gocfd/types"
@@ -231,6 +234,20 @@ func TestDFR2D(t *testing.T) {
 		}
 		fI := dfr.ConvertScalarToOutputMesh(f)
 		assert.Equal(t, len(fI), len(gm.Geometry))
+		// The triangle index list matches the output mesh, including the interior points
+		tris := dfr.OutputMeshTriangles()
+		assert.Equal(t, len(gm.Triangles), len(tris))
+		sorted := func(tri [3]int) [3]int {
+			sort.Ints(tri[:])
+			return tri
+		}
+		triSet := make(map[[3]int]bool)
+		for _, tri := range gm.Triangles {
+			triSet[sorted([3]int{int(tri.Nodes[0]), int(tri.Nodes[1]), int(tri.Nodes[2])})] = true
+		}
+		for _, tri := range tris {
+			assert.True(t, triSet[sorted(tri)])
+		}
 		fs := functions.NewFSurface(gm, [][]float32{fI}, 0)
 		if plotFunc {
 			PlotFS(fs, 0, 1)
@@ -516,3 +533,103 @@ func SetNormalFluxOnEdges(dfr *DFR2D, Fx, Fy utils.Matrix, Fp *utils.Matrix) {
 	}
 	return
 }
+
+func TestMeshOrdering(t *testing.T) {
+	assert.Equal(t, ORDER_File, NewMeshOrdering(""))
+	assert.Equal(t, ORDER_RCM, NewMeshOrdering(" RCM "))
+	assert.Equal(t, ORDER_Hilbert, NewMeshOrdering("hilbert"))
+	assert.Panics(t, func() { NewMeshOrdering("metis") })
+	var (
+		tol  = 1.e-12
+		dfr0 = NewDFR2D(1, false, false, "vortex-new.su2")
+		K    = dfr0.K
+	)
+	assert.Nil(t, dfr0.ElementOrder)
+	assert.Nil(t, dfr0.VertexOrder)
+	// Returns the other element connected to edge edgeNum of element k, or -1 for a boundary
+	neighbor := func(dfr *DFR2D, k, edgeNum int) (kk int) {
+		e := dfr.Tris.Edges[dfr.EdgeNumber[k+K*edgeNum]]
+		kk = -1
+		for conn := 0; conn < int(e.NumConnectedTris); conn++ {
+			if int(e.ConnectedTris[conn]) != k {
+				kk = int(e.ConnectedTris[conn])
+			}
+		}
+		return
+	}
+	for _, mo := range []MeshOrdering{ORDER_RCM, ORDER_Hilbert} {
+		dfr, err := NewDFR2DWithOrdering(1, mo, false, false, "vortex-new.su2")
+		assert.Nil(t, err)
+		assert.Equal(t, K, len(dfr.ElementOrder))
+		assert.Equal(t, dfr0.VX.Len(), len(dfr.VertexOrder))
+		for v, vFile := range dfr.VertexOrder {
+			assert.Equal(t, dfr0.VX.DataP[vFile], dfr.VX.DataP[v])
+			assert.Equal(t, dfr0.VY.DataP[vFile], dfr.VY.DataP[v])
+		}
+		for tag, edges := range dfr.BCEdges {
+			assert.Equal(t, len(dfr0.BCEdges[tag]), len(edges))
+			for i, e := range edges {
+				verts := e.GetVertices()
+				assert.Equal(t, dfr0.BCEdges[tag][i],
+					types.NewEdgeInt([2]int{dfr.VertexOrder[verts[0]], dfr.VertexOrder[verts[1]]}))
+			}
+		}
+		// The renumbered mesh has the same geometry and connectivity, including periodic boundaries
+		for k, kFile := range dfr.ElementOrder {
+			assert.InDelta(t, dfr0.Jdet.DataP[kFile], dfr.Jdet.DataP[k], tol)
+			for edgeNum := 0; edgeNum < 3; edgeNum++ {
+				ind, indFile := k+K*edgeNum, kFile+K*edgeNum
+				assert.InDelta(t, dfr0.FaceNorm[0].DataP[indFile], dfr.FaceNorm[0].DataP[ind], tol)
+				assert.InDelta(t, dfr0.FaceNorm[1].DataP[indFile], dfr.FaceNorm[1].DataP[ind], tol)
+				assert.Equal(t, dfr0.Tris.Edges[dfr0.EdgeNumber[indFile]].BCType,
+					dfr.Tris.Edges[dfr.EdgeNumber[ind]].BCType)
+				kk, kkFile := neighbor(dfr, k, edgeNum), neighbor(dfr0, kFile, edgeNum)
+				if kk == -1 {
+					assert.Equal(t, -1, kkFile)
+				} else {
+					assert.Equal(t, kkFile, dfr.ElementOrder[kk])
+				}
+			}
+		}
+	}
+	// A mesh with an unknown BC name returns an error naming the tag
+	meshFile := filepath.Join(t.TempDir(), "bogus.msh")
+	mesh := `$MeshFormat
+2.2 0 8
+$EndMeshFormat
+$PhysicalNames
+2
+1 1 "Bogus-side"
+2 2 "fluid"
+$EndPhysicalNames
+$Nodes
+4
+1 0 0 0
+2 1 0 0
+3 1 1 0
+4 0 1 0
+$EndNodes
+$Elements
+3
+1 1 2 1 1 1 2
+2 2 2 2 1 1 2 3
+3 2 2 2 1 1 3 4
+$EndElements
+`
+	if err := ioutil.WriteFile(meshFile, []byte(mesh), 0644); err != nil {
+		panic(err)
+	}
+	_, err := NewDFR2DWithOrdering(1, ORDER_File, false, false, meshFile)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "bogus-side")
+	}
+	assert.Panics(t, func() { NewDFR2D(1, false, false, meshFile) })
+	// Reverse Cuthill-McKee reduces the bandwidth of the element connectivity
+	dfr, err := NewDFR2DWithOrdering(1, ORDER_RCM, false, false, "vortex-new.su2")
+	assert.Nil(t, err)
+	assert.Less(t, ElementBandwidth(dfr.Tris.EtoE), ElementBandwidth(dfr0.Tris.EtoE))
+	// The Hilbert curve visits the four quadrants of a 2x2 grid in order
+	for d, xy := range [][2]int{{0, 0}, {0, 1}, {1, 1}, {1, 0}} {
+		assert.Equal(t, uint64(d), hilbertDistance(2, xy[0], xy[1]))
+	}
+}
@@ -0,0 +1,147 @@
+package DG2D
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/notargets/gocfd/utils"
+)
+
+func (dfr *DFR2D) ElementCentroids() (X, Y []float64) {
+	X, Y = elementCentroids(dfr.VX, dfr.VY, dfr.Tris.EToV)
+	return
+}
+
+func elementCentroids(VX, VY utils.Vector, EToV utils.Matrix) (X, Y []float64) {
+	var (
+		K, _ = EToV.Dims()
+	)
+	X, Y = make([]float64, K), make([]float64, K)
+	for k := 0; k < K; k++ {
+		for _, v := range EToV.DataP[3*k : 3*k+3] {
+			X[k] += VX.DataP[int(v)] / 3.
+			Y[k] += VY.DataP[int(v)] / 3.
+		}
+	}
+	return
+}
+
+/*
+Recursive inertial bisection of a set of points, used to partition the elements using their centroids.
+
+The points are split in two across the principal axis of inertia of the point cloud, then each half is split again
+until there is one group for each partition. The number of points in partition n is sizes[n], the sizes must sum to the
+number of points. The returned slice holds the partition number of each point.
+*/
+func InertialBisection(X, Y []float64, sizes []int) (part []int) {
+	var (
+		total int
+	)
+	for _, s := range sizes {
+		total += s
+	}
+	if total != len(X) || len(X) != len(Y) {
+		panic(fmt.Errorf("partition sizes sum to %d, should be the number of points %d", total, len(X)))
+	}
+	points := make([]int, len(X))
+	for i := range points {
+		points[i] = i
+	}
+	part = make([]int, len(X))
+	inertialBisect(X, Y, points, sizes, 0, part)
+	return
+}
+
+func inertialBisect(X, Y []float64, points, sizes []int, first int, part []int) {
+	if len(sizes) == 1 {
+		for _, i := range points {
+			part[i] = first
+		}
+		return
+	}
+	if len(points) == 0 {
+		return
+	}
+	var (
+		nLeft, countLeft = len(sizes) / 2, 0
+		n                = float64(len(points))
+		xm, ym           float64
+		sxx, syy, sxy    float64
+	)
+	for _, s := range sizes[:nLeft] {
+		countLeft += s
+	}
+	for _, i := range points {
+		xm += X[i] / n
+		ym += Y[i] / n
+	}
+	for _, i := range points {
+		dx, dy := X[i]-xm, Y[i]-ym
+		sxx += dx * dx
+		syy += dy * dy
+		sxy += dx * dy
+	}
+	// Direction of the largest extent of the points
+	theta := 0.5 * math.Atan2(2*sxy, sxx-syy)
+	cosT, sinT := math.Cos(theta), math.Sin(theta)
+	sort.SliceStable(points, func(a, b int) bool {
+		ia, ib := points[a], points[b]
+		return X[ia]*cosT+Y[ia]*sinT < X[ib]*cosT+Y[ib]*sinT
+	})
+	inertialBisect(X, Y, points[:countLeft], sizes[:nLeft], first, part)
+	inertialBisect(X, Y, points[countLeft:], sizes[nLeft:], first+nLeft, part)
+}
+
+func PartitionOrder(part []int, nParts int) (order []int) {
+	// Returns the elements ordered by partition, keeping the relative order of the elements within each partition
+	var (
+		start = make([]int, nParts+1)
+	)
+	for _, p := range part {
+		start[p+1]++
+	}
+	for p := 0; p < nParts; p++ {
+		start[p+1] += start[p]
+	}
+	order = make([]int, len(part))
+	for k, p := range part {
+		order[start[p]] = k
+		start[p]++
+	}
+	return
+}
+
+/*
+Renumbers the elements of the mesh, element k is the element numbered order[k] before the call. The mesh connectivity,
+geometry and metrics are rebuilt in the new order. ElementOrder keeps the number of each element in the mesh file, so
+that element data can be mapped back to the original order after any number of renumberings.
+*/
+func (dfr *DFR2D) RenumberElements(order []int) {
+	var (
+		seen     = make([]bool, dfr.K)
+		EToV     = utils.NewMatrix(dfr.K, 3)
+		original = make([]int, dfr.K)
+	)
+	if len(order) != dfr.K {
+		panic(fmt.Errorf("element order has length %d, should be %d", len(order), dfr.K))
+	}
+	for k, kOld := range order {
+		if kOld < 0 || kOld >= dfr.K || seen[kOld] {
+			panic(fmt.Errorf("element order is not a permutation, element %d is invalid or repeated", kOld))
+		}
+		seen[kOld] = true
+		for i, v := range dfr.Tris.GetTriVerts(uint32(kOld)) {
+			EToV.DataP[i+3*k] = float64(v)
+		}
+		if dfr.ElementOrder != nil {
+			original[k] = dfr.ElementOrder[kOld]
+		} else {
+			original[k] = kOld
+		}
+	}
+	dfr.ElementOrder = original
+	if err := dfr.initializeMesh(EToV); err != nil { // The BCs were checked when the mesh was read
+		panic(err)
+	}
+}
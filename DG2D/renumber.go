@@ -0,0 +1,285 @@
+package DG2D
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/notargets/gocfd/types"
+
+	"github.com/notargets/gocfd/utils"
+)
+
+/*
+The elements of a mesh can be renumbered after reading to improve the memory locality of the Np x K matrices, so that
+neighboring elements are near each other in memory. Reverse Cuthill-McKee orders the elements by a breadth first
+traversal of the element adjacency, which minimizes the bandwidth of the connectivity. The Hilbert ordering sorts the
+element centroids along a space filling curve. The vertices are then numbered in the order they are first used by
+the renumbered elements.
+*/
+type MeshOrdering uint8
+
+const (
+	ORDER_File MeshOrdering = iota
+	ORDER_RCM
+	ORDER_Hilbert
+)
+
+var (
+	MeshOrderingNames = map[string]MeshOrdering{
+		"file":                  ORDER_File,
+		"none":                  ORDER_File,
+		"rcm":                   ORDER_RCM,
+		"reverse-cuthill-mckee": ORDER_RCM,
+		"hilbert":               ORDER_Hilbert,
+		"sfc":                   ORDER_Hilbert,
+	}
+	MeshOrderingPrintNames = []string{"Mesh File", "Reverse Cuthill-McKee", "Hilbert Curve"}
+)
+
+func (mo MeshOrdering) Print() string {
+	return MeshOrderingPrintNames[mo]
+}
+
+func NewMeshOrdering(label string) (mo MeshOrdering) {
+	var (
+		ok  bool
+		err error
+	)
+	if len(label) == 0 {
+		return ORDER_File
+	}
+	label = strings.ToLower(strings.TrimSpace(label))
+	if mo, ok = MeshOrderingNames[label]; !ok {
+		err = fmt.Errorf("unable to use mesh ordering named [%s]", label)
+		panic(err)
+	}
+	return
+}
+
+func (dfr *DFR2D) reorderMesh(mo MeshOrdering, EToV utils.Matrix, verbose bool) (EToVNew utils.Matrix) {
+	// Renumbers the elements and vertices read from the mesh file, prior to building the mesh structures
+	var (
+		elementOrder []int
+	)
+	switch mo {
+	case ORDER_File:
+		return EToV
+	case ORDER_RCM:
+		elementOrder = ReverseCuthillMcKee(elementAdjacency(dfr.VX, dfr.VY, EToV))
+	case ORDER_Hilbert:
+		elementOrder = HilbertOrder(elementCentroids(dfr.VX, dfr.VY, EToV))
+	}
+	var (
+		K, _        = EToV.Dims()
+		Nv          = dfr.VX.Len()
+		vertexMap   = make([]int, Nv) // File vertex to new vertex
+		vertexOrder = make([]int, 0, Nv)
+	)
+	EToVNew = utils.NewMatrix(K, 3)
+	for v := range vertexMap {
+		vertexMap[v] = -1
+	}
+	for k, kFile := range elementOrder {
+		for i := 0; i < 3; i++ {
+			v := int(EToV.DataP[i+3*kFile])
+			if vertexMap[v] == -1 {
+				vertexMap[v] = len(vertexOrder)
+				vertexOrder = append(vertexOrder, v)
+			}
+			EToVNew.DataP[i+3*k] = float64(vertexMap[v])
+		}
+	}
+	// Vertices not used by any element keep their relative order at the end
+	for v := range vertexMap {
+		if vertexMap[v] == -1 {
+			vertexMap[v] = len(vertexOrder)
+			vertexOrder = append(vertexOrder, v)
+		}
+	}
+	var bandwidth int
+	if verbose {
+		bandwidth = ElementBandwidth(elementAdjacency(dfr.VX, dfr.VY, EToV))
+	}
+	VX, VY := utils.NewVector(Nv), utils.NewVector(Nv)
+	for v, vFile := range vertexOrder {
+		VX.DataP[v], VY.DataP[v] = dfr.VX.DataP[vFile], dfr.VY.DataP[vFile]
+	}
+	dfr.VX, dfr.VY = VX, VY
+	dfr.BCEdges = renumberBCEdges(dfr.BCEdges, vertexMap)
+	dfr.ElementOrder, dfr.VertexOrder = elementOrder, vertexOrder
+	if verbose {
+		fmt.Printf("Mesh renumbered using [%s], element adjacency bandwidth %d -> %d\n", mo.Print(), bandwidth,
+			ElementBandwidth(elementAdjacency(VX, VY, EToVNew)))
+	}
+	return
+}
+
+func renumberBCEdges(BCEdges types.BCMAP, vertexMap []int) (bcNew types.BCMAP) {
+	// Edges keep their direction and their order within each BC, which matters for pairing periodic boundaries
+	bcNew = make(types.BCMAP, len(BCEdges))
+	for tag, edges := range BCEdges {
+		bcNew[tag] = make([]types.EdgeInt, len(edges))
+		for i, e := range edges {
+			verts := e.GetVertices()
+			bcNew[tag][i] = types.NewEdgeInt([2]int{vertexMap[verts[0]], vertexMap[verts[1]]})
+		}
+	}
+	return
+}
+
+func elementAdjacency(VX, VY utils.Vector, EToV utils.Matrix) (EtoE [][3]int) {
+	// Element to element connectivity through shared edges, boundaries are not connected
+	tmesh, err := NewTriangulation(VX, VY, EToV, nil)
+	if err != nil {
+		panic(err)
+	}
+	EtoE = tmesh.EtoE
+	return
+}
+
+func ElementBandwidth(EtoE [][3]int) (bw int) {
+	// Largest difference in element number between two connected elements
+	for k, conn := range EtoE {
+		for _, kk := range conn {
+			if kk >= 0 && int(math.Abs(float64(kk-k))) > bw {
+				bw = int(math.Abs(float64(kk - k)))
+			}
+		}
+	}
+	return
+}
+
+/*
+Reverse Cuthill-McKee ordering of the element adjacency graph. Each connected component is traversed breadth first
+from a pseudo-peripheral element, found by repeated traversals starting at an element of minimum degree, visiting the
+neighbors of each element in order of increasing degree. The returned slice holds the original element number of each
+element in the new order.
+*/
+func ReverseCuthillMcKee(EtoE [][3]int) (order []int) {
+	var (
+		K      = len(EtoE)
+		degree = make([]int, K)
+		seen   = make([]int, K) // Traversal number of the last traversal to reach each element
+		pass   int
+		done   = make([]bool, K)
+	)
+	for k, conn := range EtoE {
+		for _, kk := range conn {
+			if kk >= 0 {
+				degree[k]++
+			}
+		}
+	}
+	minDegree := func(ks []int) (kMin int) {
+		kMin = ks[0]
+		for _, k := range ks {
+			if degree[k] < degree[kMin] {
+				kMin = k
+			}
+		}
+		return
+	}
+	bfs := func(start int) (traversal, lastLevel []int, nLevels int) {
+		pass++
+		seen[start] = pass
+		level := []int{start}
+		for len(level) != 0 {
+			traversal = append(traversal, level...)
+			lastLevel = level
+			nLevels++
+			var next []int
+			for _, k := range level {
+				first := len(next)
+				for _, kk := range EtoE[k] {
+					if kk >= 0 && seen[kk] != pass {
+						seen[kk] = pass
+						next = append(next, kk)
+					}
+				}
+				nbrs := next[first:]
+				sort.SliceStable(nbrs, func(i, j int) bool { return degree[nbrs[i]] < degree[nbrs[j]] })
+			}
+			level = next
+		}
+		return
+	}
+	order = make([]int, 0, K)
+	for seed := 0; seed < K; seed++ {
+		if done[seed] {
+			continue
+		}
+		component, _, _ := bfs(seed)
+		start := minDegree(component)
+		traversal, lastLevel, depth := bfs(start)
+		for iter := 0; iter < 5; iter++ {
+			candidate := minDegree(lastLevel)
+			t, l, d := bfs(candidate)
+			if d <= depth {
+				break
+			}
+			start, traversal, lastLevel, depth = candidate, t, l, d
+		}
+		for _, k := range traversal {
+			done[k] = true
+		}
+		order = append(order, traversal...)
+	}
+	// Reverse the Cuthill-McKee order
+	for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+		order[i], order[j] = order[j], order[i]
+	}
+	return
+}
+
+func HilbertOrder(X, Y []float64) (order []int) {
+	// Orders the points by their distance along a Hilbert curve covering the bounding square of the points
+	var (
+		n                      = len(X)
+		side                   = 1 << 16
+		xMin, xMax, yMin, yMax = math.Inf(1), math.Inf(-1), math.Inf(1), math.Inf(-1)
+		dist                   = make([]uint64, n)
+	)
+	for i := 0; i < n; i++ {
+		xMin, xMax = math.Min(xMin, X[i]), math.Max(xMax, X[i])
+		yMin, yMax = math.Min(yMin, Y[i]), math.Max(yMax, Y[i])
+	}
+	scale := math.Max(xMax-xMin, yMax-yMin)
+	if scale == 0 {
+		scale = 1
+	}
+	for i := 0; i < n; i++ {
+		ix := int(float64(side-1) * (X[i] - xMin) / scale)
+		iy := int(float64(side-1) * (Y[i] - yMin) / scale)
+		dist[i] = hilbertDistance(side, ix, iy)
+	}
+	order = make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return dist[order[i]] < dist[order[j]] })
+	return
+}
+
+func hilbertDistance(side, x, y int) (d uint64) {
+	// Distance along the Hilbert curve of the point (x,y) on a side x side grid, side is a power of two
+	for s := side / 2; s > 0; s /= 2 {
+		var rx, ry int
+		if x&s > 0 {
+			rx = 1
+		}
+		if y&s > 0 {
+			ry = 1
+		}
+		d += uint64(s) * uint64(s) * uint64((3*rx)^ry)
+		// Rotate the quadrant so that the curve is continuous
+		if ry == 0 {
+			if rx == 1 {
+				x, y = side-1-x, side-1-y
+			}
+			x, y = y, x
+		}
+	}
+	return
+}
@@ -15,7 +15,7 @@ type Triangulation struct {
 	EtoE  [][3]int                // For each element in [k], the connected element for each of the three edges [0,1,2]
 }
 
-func NewTriangulation(VX, VY utils.Vector, EToV utils.Matrix, BCEdges types.BCMAP) (tmesh *Triangulation) {
+func NewTriangulation(VX, VY utils.Vector, EToV utils.Matrix, BCEdges types.BCMAP) (tmesh *Triangulation, err error) {
 	var (
 		K, _ = EToV.Dims()
 	)
@@ -47,12 +47,15 @@ func NewTriangulation(VX, VY utils.Vector, EToV utils.Matrix, BCEdges types.BCMA
 		}
 	}
 	// Insert BCs into edges map
-	var err error
 	var reversedLastEdge bool
 	for key, edges := range BCEdges {
-		flag := key.GetFLAG()
+		var flag types.BCFLAG
+		if flag, err = key.FindFLAG(); err != nil {
+			return
+		}
 		switch flag {
-		case types.BC_Far, types.BC_IVortex, types.BC_Wall, types.BC_In, types.BC_Out, types.BC_Cyl:
+		case types.BC_Far, types.BC_IVortex, types.BC_Wall, types.BC_In, types.BC_Out, types.BC_Cyl,
+			types.BC_Slip, types.BC_Dirichlet, types.BC_Neuman:
 			for _, e := range edges {
 				ee := tmesh.Edges[e.GetKey()]
 				ee.BCType = flag
@@ -95,8 +98,8 @@ func NewTriangulation(VX, VY utils.Vector, EToV utils.Matrix, BCEdges types.BCMA
 				ee1.AddTri(e2.GetKey(), k2, 1, en2, dir, VX, VY)
 			}
 		default:
-			err = fmt.Errorf("BC type %s not implemented yet", flag.String())
-			panic(err)
+			err = fmt.Errorf("BC [%s] has type %s, which is not supported", key, flag.String())
+			return
 		}
 	}
 	return
@@ -22,6 +22,7 @@ import (
 
 	"github.com/notargets/gocfd/model_problems/Advection1D"
 	"github.com/notargets/gocfd/model_problems/Euler1D"
+	"github.com/notargets/gocfd/model_problems/Euler1D/exact_riemann"
 	"github.com/notargets/gocfd/model_problems/Maxwell1D"
 	"github.com/spf13/cobra"
 )
@@ -34,7 +35,13 @@ var OneDCmd = &cobra.Command{
 Executes the Nodal Discontinuous Galerkin solver for a variety of model problems, with
 optional live plots of the solutions. For example:
 
-gocfd 1D -graph`,
+gocfd 1D -graph
+
+The Euler shock tube is Sod's problem by default, other Riemann problems are set using the left and right states
+[rho, u, p], the diaphragm location and gamma, or one of Toro's tests 1 to 5, which also sets the final time:
+
+gocfd 1D -m 5 --left 1,0,1000 --right 1,0,0.01 --finalTime 0.012
+gocfd 1D -m 5 --toro 3`,
 	Run: func(cmd *cobra.Command, args []string) {
 		m1d := &Model1D{}
 		fmt.Println("1D called")
@@ -51,6 +58,24 @@ gocfd 1D -graph`,
 		m1d.N, _ = cmd.Flags().GetInt("n")
 		m1d.K, _ = cmd.Flags().GetInt("k")
 		m1d.CFL = LimitCFL(m1d.ModelRun, m1d.CFL)
+		left, _ := cmd.Flags().GetFloat64Slice("left")
+		right, _ := cmd.Flags().GetFloat64Slice("right")
+		x0, _ := cmd.Flags().GetFloat64("x0")
+		gamma, _ := cmd.Flags().GetFloat64("gamma")
+		if toro, _ := cmd.Flags().GetInt("toro"); toro != 0 {
+			var finalTime float64
+			m1d.Riemann, finalTime = exact_riemann.NewToroTest(toro)
+			if !cmd.Flags().Changed("finalTime") {
+				m1d.FinalTime = finalTime
+			}
+		} else {
+			if len(left) != 3 || len(right) != 3 {
+				panic(fmt.Errorf("left and right states must be [rho, u, p], have %v and %v", left, right))
+			}
+			m1d.Riemann = exact_riemann.NewRiemannProblem(
+				exact_riemann.State{Rho: left[0], U: left[1], P: left[2]},
+				exact_riemann.State{Rho: right[0], U: right[1], P: right[2]}, gamma, x0)
+		}
 		Run1D(m1d)
 	},
 }
@@ -76,6 +101,11 @@ func init() {
 	OneDCmd.Flags().Float64("CFL", CFL, "CFL - increase for speedup, decrease for stability")
 	OneDCmd.Flags().Float64("finalTime", FinalTime, "FinalTime - the target end time for the sim")
 	OneDCmd.Flags().Float64("xMax", XMax, "Maximum X coordinate (for Euler) - make sure to increase K with XMax")
+	OneDCmd.Flags().Float64Slice("left", []float64{1, 0, 1}, "Euler shock tube state left of the diaphragm: rho,u,p")
+	OneDCmd.Flags().Float64Slice("right", []float64{0.125, 0, 0.1}, "Euler shock tube state right of the diaphragm: rho,u,p")
+	OneDCmd.Flags().Float64("x0", 0.5, "Euler shock tube diaphragm location")
+	OneDCmd.Flags().Float64("gamma", 1.4, "Euler ratio of specific heats")
+	OneDCmd.Flags().Int("toro", 0, "Euler shock tube from Toro's tests 1 to 5, overrides the states, diaphragm and gamma")
 }
 
 type Model1D struct {
@@ -85,6 +115,7 @@ type Model1D struct {
 	CFL, FinalTime, XMax float64
 	Case                 Euler1D.CaseType
 	Graph                bool
+	Riemann              *exact_riemann.RiemannProblem // Euler shock tube states and diaphragm
 }
 
 type ModelType1D uint8
@@ -125,15 +156,15 @@ func Run1D(m1d *Model1D) {
 	case M_1DMaxwellDFR:
 		C = Maxwell1D.NewMaxwell(m1d.CFL, m1d.FinalTime, m1d.N, m1d.K, Maxwell1D.DFR)
 	case M_1DEulerDFR_Roe:
-		C = Euler1D.NewEuler(m1d.CFL, m1d.FinalTime, m1d.XMax, m1d.N, m1d.K, Euler1D.DFR_Roe, m1d.Case)
+		C = Euler1D.NewEuler(m1d.CFL, m1d.FinalTime, m1d.XMax, m1d.N, m1d.K, Euler1D.DFR_Roe, m1d.Case, m1d.Riemann)
 	case M_1DEulerDFR_LF:
-		C = Euler1D.NewEuler(m1d.CFL, m1d.FinalTime, m1d.XMax, m1d.N, m1d.K, Euler1D.DFR_LaxFriedrichs, m1d.Case)
+		C = Euler1D.NewEuler(m1d.CFL, m1d.FinalTime, m1d.XMax, m1d.N, m1d.K, Euler1D.DFR_LaxFriedrichs, m1d.Case, m1d.Riemann)
 	case M_1DEulerDFR_Ave:
-		C = Euler1D.NewEuler(m1d.CFL, m1d.FinalTime, m1d.XMax, m1d.N, m1d.K, Euler1D.DFR_Average, m1d.Case)
+		C = Euler1D.NewEuler(m1d.CFL, m1d.FinalTime, m1d.XMax, m1d.N, m1d.K, Euler1D.DFR_Average, m1d.Case, m1d.Riemann)
 	case M_1DEuler:
 		fallthrough
 	default:
-		C = Euler1D.NewEuler(m1d.CFL, m1d.FinalTime, m1d.XMax, m1d.N, m1d.K, Euler1D.Galerkin_LF, m1d.Case)
+		C = Euler1D.NewEuler(m1d.CFL, m1d.FinalTime, m1d.XMax, m1d.N, m1d.K, Euler1D.Galerkin_LF, m1d.Case, m1d.Riemann)
 	}
 	C.Run(m1d.Graph, m1d.Delay*time.Millisecond)
 }
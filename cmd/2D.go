@@ -39,6 +39,19 @@ type Model2D struct {
 	Profile                      bool
 	Zoom, TranslateX, TranslateY float64
 	fminP, fmaxP                 *float64
+	RestartFile                  string
+	CheckpointFile               string
+	CheckpointEvery              int
+	VTKPrefix                    string
+	VTKEvery                     int
+	VTKFields                    []int
+	ForceHistoryFile             string
+	SurfaceFile                  string
+	RenderPrefix                 string
+	RenderFormat                 string
+	RenderWidth, RenderHeight    int
+	RenderMesh                   bool
+	MonitorAddress               string
 }
 
 // TwoDCmd represents the 2D command
@@ -71,6 +84,20 @@ var TwoDCmd = &cobra.Command{
 		fmin, _ := cmd.Flags().GetFloat64("plotMin")
 		fmax, _ := cmd.Flags().GetFloat64("plotMax")
 		m2d.Profile, _ = cmd.Flags().GetBool("profile")
+		m2d.RestartFile, _ = cmd.Flags().GetString("restart")
+		m2d.CheckpointFile, _ = cmd.Flags().GetString("checkpointFile")
+		m2d.CheckpointEvery, _ = cmd.Flags().GetInt("checkpointEvery")
+		m2d.VTKPrefix, _ = cmd.Flags().GetString("vtu")
+		m2d.VTKEvery, _ = cmd.Flags().GetInt("vtuEvery")
+		m2d.VTKFields, _ = cmd.Flags().GetIntSlice("vtuFields")
+		m2d.ForceHistoryFile, _ = cmd.Flags().GetString("forceHistory")
+		m2d.SurfaceFile, _ = cmd.Flags().GetString("surfaceFile")
+		m2d.RenderPrefix, _ = cmd.Flags().GetString("render")
+		m2d.RenderFormat, _ = cmd.Flags().GetString("renderFormat")
+		m2d.RenderWidth, _ = cmd.Flags().GetInt("renderWidth")
+		m2d.RenderHeight, _ = cmd.Flags().GetInt("renderHeight")
+		m2d.RenderMesh, _ = cmd.Flags().GetBool("renderMesh")
+		m2d.MonitorAddress, _ = cmd.Flags().GetString("monitor")
 		if fmin != -1000 {
 			m2d.fminP = &fmin
 		}
@@ -92,7 +119,7 @@ func processInput(m2d *Model2D) (ip *Euler2D.InputParameters) {
 		fmt.Printf("error: %s\n", err.Error())
 		willExit = true
 	}
-	if len(m2d.ICFile) == 0 {
+	if len(m2d.ICFile) == 0 && len(m2d.RestartFile) == 0 {
 		err := fmt.Errorf("must supply an input parameters file (-I, --inputConditionsFile) in .neu (Gambit neutral file) format")
 		fmt.Printf("error: %s\n", err.Error())
 		exampleFile := `
@@ -122,13 +149,20 @@ FinalTime: 4
 		if err = ip.Parse(data); err != nil {
 			panic(err)
 		}
+	} else {
+		// Use the input parameters stored in the restart checkpoint
+		var cp *Euler2D.Checkpoint
+		if cp, err = Euler2D.ReadCheckpoint(m2d.RestartFile); err != nil {
+			panic(err)
+		}
+		ip = &cp.Parameters
 	}
 	return
 }
 
 func init() {
 	rootCmd.AddCommand(TwoDCmd)
-	TwoDCmd.Flags().StringP("gridFile", "F", "", "Grid file to read in Gambit (.neu) format")
+	TwoDCmd.Flags().StringP("gridFile", "F", "", "Grid file to read in Gambit (.neu), SU2 (.su2) or Gmsh (.msh) format")
 	TwoDCmd.Flags().StringP("inputConditionsFile", "I", "", "YAML file for input parameters like:\n\t- CFL\n\t- NPR (nozzle pressure ratio)")
 	TwoDCmd.Flags().BoolP("graph", "g", false, "display a graph while computing solution")
 	TwoDCmd.Flags().IntP("delay", "d", 0, "milliseconds of delay for plotting")
@@ -141,10 +175,33 @@ func init() {
 	TwoDCmd.Flags().Float64P("plotMin", "k", -1000, "field min for plotting")
 	TwoDCmd.Flags().Float64P("plotMax", "l", -1000, "field max for plotting")
 	TwoDCmd.Flags().Bool("profile", false, "generate a runtime profile of the solver, can be converted to PDF using 'go tool pprof -pdf filename'")
+	TwoDCmd.Flags().String("restart", "", "checkpoint file to restart the solution from, input parameters are taken from the checkpoint if no input file is given")
+	TwoDCmd.Flags().String("checkpointFile", "gocfd-checkpoint.gob", "name of the checkpoint file to write")
+	TwoDCmd.Flags().Int("checkpointEvery", 0, "number of iterations between checkpoints, 0 disables checkpointing")
+	TwoDCmd.Flags().String("vtu", "", "prefix for VTU solution files and the PVD time series collection, for use in ParaView")
+	TwoDCmd.Flags().Int("vtuEvery", 0, "number of iterations between VTU files, 0 writes only the final solution")
+	TwoDCmd.Flags().String("forceHistory", "", "file for the history of CL, CD and CM on wall boundaries, not written if empty")
+	TwoDCmd.Flags().String("surfaceFile", "", "prefix for CSV files of x, y, arc length, Cp, Mach, pressure and curve segment along each wall, written at the end of the run")
+	TwoDCmd.Flags().String("render", "", "prefix for image files of the graph field, written every plotSteps iterations without a display")
+	TwoDCmd.Flags().String("renderFormat", "png", "image format for rendering, png writes a file per frame, gif writes one animation")
+	TwoDCmd.Flags().Int("renderWidth", 1920, "width in pixels of rendered images")
+	TwoDCmd.Flags().Int("renderHeight", 1080, "height in pixels of rendered images")
+	TwoDCmd.Flags().Bool("renderMesh", false, "draw the element edges over rendered images")
+	TwoDCmd.Flags().String("monitor", "", "address to serve the solver status as JSON on /status and the graph field on /image.png, e.g. :8080")
+	TwoDCmd.Flags().IntSlice("vtuFields", []int{4, 5, 13}, "flow functions written to VTU in addition to the conserved variables, e.g. 4=Mach, 5=pressure, 13=entropy")
 }
 
 func Run2D(m2d *Model2D, ip *Euler2D.InputParameters) {
 	c := Euler2D.NewEuler(ip, m2d.GridFile, m2d.ParallelProcLimit, false, true, m2d.Profile)
+	c.RestartFile = m2d.RestartFile
+	c.CheckpointFile = m2d.CheckpointFile
+	c.CheckpointEvery = m2d.CheckpointEvery
+	c.VTKPrefix, c.VTKEvery = m2d.VTKPrefix, m2d.VTKEvery
+	c.ForceHistoryFile = m2d.ForceHistoryFile
+	c.SurfaceFile = m2d.SurfaceFile
+	for _, f := range m2d.VTKFields {
+		c.VTKFields = append(c.VTKFields, Euler2D.FlowFunction(f))
+	}
 	pm := &Euler2D.PlotMeta{
 		Plot:            m2d.Graph,
 		Field:           Euler2D.FlowFunction(m2d.GraphField),
@@ -157,12 +214,21 @@ func Run2D(m2d *Model2D, ip *Euler2D.InputParameters) {
 		TranslateX:      m2d.TranslateX,
 		TranslateY:      m2d.TranslateY,
 	}
-	/*
-		if ip.ImplicitSolver {
-			c.SolveImplicit(pm)
-		} else {
-			c.Solve(pm)
+	if len(m2d.RenderPrefix) != 0 {
+		pm.Render = Euler2D.NewFrameRenderer(m2d.RenderPrefix, Euler2D.NewImageFormat(m2d.RenderFormat),
+			m2d.RenderWidth, m2d.RenderHeight, m2d.RenderMesh)
+	}
+	if len(m2d.MonitorAddress) != 0 {
+		var err error
+		if pm.Monitor, err = Euler2D.NewMonitor(m2d.MonitorAddress, m2d.RenderWidth, m2d.RenderHeight); err != nil {
+			panic(err)
 		}
-	*/
-	c.Solve(pm)
+		defer pm.Monitor.Close()
+		fmt.Printf("Serving solver status on http://%s/status\n", pm.Monitor.Address())
+	}
+	if ip.ImplicitSolver {
+		c.SolveImplicit(pm)
+	} else {
+		c.Solve(pm)
+	}
 }
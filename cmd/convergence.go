@@ -0,0 +1,83 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/notargets/gocfd/model_problems/Euler2D"
+
+	"github.com/spf13/cobra"
+)
+
+// ConvergenceCmd represents the convergence command
+var ConvergenceCmd = &cobra.Command{
+	Use:   "convergence",
+	Short: "Measures the order of accuracy of the 2D solver using an analytic case on a sequence of meshes",
+	Long: `Measures the order of accuracy of the 2D solver using an analytic case on a sequence of meshes.
+The input file must use an initial condition with an exact solution, e.g. "InitType: IVortex", or a manufactured
+solution with "InitType: MMS". Each mesh is solved for each polynomial order until the final time, then the L1, L2
+and Linf errors of the conserved variables are measured and the observed orders are printed. For example:
+
+gocfd convergence -I input.yaml -M vnew-coarse.su2,vnew.su2,vnew-dense.su2 -N 1,2
+
+Meshes compressed with bzip2 (.bz2) are uncompressed before reading. The errors are also written to a CSV file that
+can be read by tools/convOrder.
+
+The steady exact solutions, "InitType: Ringleb" and "InitType: SupersonicVortex", use meshes from the mesh command
+and are solved to a steady state with "ImplicitSolver: true".`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			err error
+		)
+		fmt.Println("convergence called")
+		m2d := &Model2D{}
+		if m2d.ICFile, err = cmd.Flags().GetString("inputConditionsFile"); err != nil {
+			panic(err)
+		}
+		meshFiles, _ := cmd.Flags().GetStringSlice("meshFiles")
+		orders, _ := cmd.Flags().GetIntSlice("orders")
+		csvFile, _ := cmd.Flags().GetString("csvFile")
+		m2d.ParallelProcLimit, _ = cmd.Flags().GetInt("parallelProcs")
+		m2d.PlotSteps, _ = cmd.Flags().GetInt("plotSteps")
+		if len(meshFiles) < 2 {
+			panic(fmt.Errorf("must supply at least two mesh files (-M, --meshFiles)"))
+		}
+		m2d.GridFile = meshFiles[0]
+		ip := processInput(m2d)
+		pm := &Euler2D.PlotMeta{StepsBeforePlot: m2d.PlotSteps}
+		results := Euler2D.RunConvergenceStudy(ip, meshFiles, orders, m2d.ParallelProcLimit, pm)
+		Euler2D.PrintConvergenceStudy(results)
+		title := ip.Title
+		if len(title) == 0 {
+			title = ip.InitType
+		}
+		if err = Euler2D.WriteConvergenceStudyCSV(csvFile, title, results); err != nil {
+			panic(err)
+		}
+		fmt.Printf("Errors written to %s\n", csvFile)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ConvergenceCmd)
+	ConvergenceCmd.Flags().StringP("inputConditionsFile", "I", "", "YAML file for the input parameters, with an analytic InitType like IVortex, MMS or Ringleb")
+	ConvergenceCmd.Flags().StringSliceP("meshFiles", "M", nil, "comma separated list of mesh files, from coarse to fine")
+	ConvergenceCmd.Flags().IntSliceP("orders", "N", nil, "comma separated list of polynomial orders, default is the order in the input file")
+	ConvergenceCmd.Flags().String("csvFile", "gocfd-convergence.csv", "CSV file for the errors on each mesh, in the format read by tools/convOrder")
+	ConvergenceCmd.Flags().IntP("parallelProcs", "p", 0, "limits the parallelism to the number of specified processes")
+	ConvergenceCmd.Flags().IntP("plotSteps", "s", 100, "number of steps between printed updates")
+}
@@ -0,0 +1,63 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/notargets/gocfd/model_problems/Euler2D"
+
+	"github.com/spf13/cobra"
+)
+
+// MeshCmd represents the mesh command
+var MeshCmd = &cobra.Command{
+	Use:   "mesh",
+	Short: "Generates a mesh of the domain of an exact solution",
+	Long: `Generates a mesh of the domain of an exact solution in SU2 format, with the walls, inflow and outflow
+marked for the solver. The domains are:
+
+Ringleb:          between two streamlines of Ringleb flow, cut by inflow and outflow at a constant speed
+SupersonicVortex: a quarter annulus with walls on the inner and outer arcs
+
+The mesh has the number of elements across the domain given by -n and four times as many along it, each quadrilateral
+is split into two triangles when read. For example, to generate a sequence of meshes for a convergence study:
+
+gocfd mesh -c Ringleb -n 4 -o ringleb-4.su2
+gocfd mesh -c Ringleb -n 8 -o ringleb-8.su2`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			err error
+		)
+		caseName, _ := cmd.Flags().GetString("case")
+		n, _ := cmd.Flags().GetInt("elements")
+		outFile, _ := cmd.Flags().GetString("outputFile")
+		if len(outFile) == 0 {
+			outFile = fmt.Sprintf("%s-%d.su2", caseName, n)
+		}
+		if err = Euler2D.WriteExactSolutionMesh(Euler2D.NewInitType(caseName), outFile, n); err != nil {
+			panic(err)
+		}
+		fmt.Printf("Mesh written to %s\n", outFile)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(MeshCmd)
+	MeshCmd.Flags().StringP("case", "c", "Ringleb", "exact solution of the domain, Ringleb or SupersonicVortex")
+	MeshCmd.Flags().IntP("elements", "n", 8, "number of elements across the domain")
+	MeshCmd.Flags().StringP("outputFile", "o", "", "SU2 file for the mesh, default is <case>-<elements>.su2")
+}
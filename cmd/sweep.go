@@ -0,0 +1,84 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/notargets/gocfd/model_problems/Euler2D"
+
+	"github.com/spf13/cobra"
+)
+
+// SweepCmd represents the sweep command
+var SweepCmd = &cobra.Command{
+	Use:   "sweep",
+	Short: "Runs the 2D solver for each combination of swept flow parameters, e.g. to generate drag polars",
+	Long: `Runs the 2D solver for each combination of swept flow parameters, e.g. to generate drag polars.
+The base input file supplies all parameters, the sweep file lists the values of Minf, Alpha, PolynomialOrder and
+FluxType to run, for example:
+
+Alpha: {From: -4, To: 8, Step: 2}
+Minf: [0.5, 0.7]
+WarmStart: true
+
+The final residuals, iterations and forces of each case are written to the summary CSV file.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			err error
+		)
+		fmt.Println("sweep called")
+		m2d := &Model2D{}
+		if m2d.GridFile, err = cmd.Flags().GetString("gridFile"); err != nil {
+			panic(err)
+		}
+		if m2d.ICFile, err = cmd.Flags().GetString("inputConditionsFile"); err != nil {
+			panic(err)
+		}
+		m2d.ParallelProcLimit, _ = cmd.Flags().GetInt("parallelProcs")
+		m2d.PlotSteps, _ = cmd.Flags().GetInt("plotSteps")
+		sweepFile, _ := cmd.Flags().GetString("sweepFile")
+		summaryFile, _ := cmd.Flags().GetString("summary")
+		if len(sweepFile) == 0 {
+			panic(fmt.Errorf("must supply a sweep file (-S, --sweepFile)"))
+		}
+		ip := processInput(m2d)
+		var data []byte
+		if data, err = ioutil.ReadFile(sweepFile); err != nil {
+			panic(err)
+		}
+		var ss *Euler2D.SweepSpec
+		if ss, err = Euler2D.ParseSweepSpec(data); err != nil {
+			panic(err)
+		}
+		pm := &Euler2D.PlotMeta{StepsBeforePlot: m2d.PlotSteps}
+		if _, err = Euler2D.RunSweep(ip, ss, m2d.GridFile, m2d.ParallelProcLimit, pm, summaryFile); err != nil {
+			panic(err)
+		}
+		fmt.Printf("Sweep summary written to %s\n", summaryFile)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(SweepCmd)
+	SweepCmd.Flags().StringP("gridFile", "F", "", "Grid file to read in Gambit (.neu), SU2 (.su2) or Gmsh (.msh) format")
+	SweepCmd.Flags().StringP("inputConditionsFile", "I", "", "YAML file for the base input parameters of all cases")
+	SweepCmd.Flags().StringP("sweepFile", "S", "", "YAML file with the values of Minf, Alpha, PolynomialOrder and FluxType to sweep")
+	SweepCmd.Flags().String("summary", "gocfd-sweep.csv", "CSV file for the residuals, iterations and forces of each case")
+	SweepCmd.Flags().IntP("parallelProcs", "p", 0, "limits the parallelism to the number of specified processes")
+	SweepCmd.Flags().IntP("plotSteps", "s", 100, "number of steps between printed updates")
+}
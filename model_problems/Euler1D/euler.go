@@ -6,7 +6,7 @@ import (
 	"sync"
 	"time"
 
-	"github.com/notargets/gocfd/model_problems/Euler1D/sod_shock_tube"
+	"github.com/notargets/gocfd/model_problems/Euler1D/exact_riemann"
 
 	"github.com/notargets/avs/chart2d"
 	utils2 "github.com/notargets/avs/utils"
@@ -23,6 +23,7 @@ type Euler struct {
 	State           *FieldState
 	Rho, RhoU, Ener utils.Matrix
 	In, Out         *State
+	Riemann         *exact_riemann.RiemannProblem // Exact solution of the shock tube, from its left and right states
 	plotOnce        sync.Once
 	chart           *chart2d.Chart2D
 	colorMap        *utils2.ColorMap
@@ -63,7 +64,9 @@ var (
 	}
 )
 
-func NewEuler(CFL, FinalTime, XMax float64, N, K int, model ModelType, Case CaseType) (c *Euler) {
+func NewEuler(CFL, FinalTime, XMax float64, N, K int, model ModelType, Case CaseType,
+	rpI ...*exact_riemann.RiemannProblem) (c *Euler) {
+	// The optional Riemann problem sets the states and diaphragm of the shock tube, Sod's problem by default
 	switch Case {
 	case DENSITY_WAVE:
 		XMax = math.Max(XMax, 2)
@@ -75,6 +78,13 @@ func NewEuler(CFL, FinalTime, XMax float64, N, K int, model ModelType, Case Case
 		FinalTime: FinalTime,
 		model:     model,
 		Case:      Case,
+		Riemann:   exact_riemann.NewSod(),
+	}
+	if len(rpI) != 0 && rpI[0] != nil {
+		c.Riemann = rpI[0]
+	}
+	if c.Riemann.X0 <= 0 || c.Riemann.X0 >= XMax {
+		panic(fmt.Errorf("shock tube diaphragm at %g is outside of the domain [0,%g]", c.Riemann.X0, XMax))
 	}
 	switch model {
 	case DFR_Roe, DFR_LaxFriedrichs, DFR_Average:
@@ -86,7 +96,7 @@ func NewEuler(CFL, FinalTime, XMax float64, N, K int, model ModelType, Case Case
 		c.El_S = c.El
 	}
 	c.MapSolutionSubset()
-	c.State.Gamma = 1.4
+	c.State.Gamma = c.Riemann.Gamma
 	fmt.Printf("Euler Equations in 1 Dimension\n")
 	switch c.Case {
 	case DENSITY_WAVE:
@@ -97,7 +107,7 @@ func NewEuler(CFL, FinalTime, XMax float64, N, K int, model ModelType, Case Case
 		c.InitializeSOD()
 		c.bc = RIEMANN
 		c.useLimiter = true
-		fmt.Printf("Solving Sod's Shock Tube\n")
+		fmt.Printf("Solving the Shock Tube\n%s\n", c.Riemann.Print())
 	case FREESTREAM:
 		c.InitializeFS()
 		c.bc = RIEMANN
@@ -140,27 +150,29 @@ func (c *Euler) InitializeSOD() {
 		err               error
 		npOnes            = utils.NewVectorConstant(el.Np, 1)
 		s                 = c.State
+		L, R              = c.Riemann.Left, c.Riemann.Right
+		x0                = c.Riemann.X0
 	)
-	c.In = NewStateP(c.State.Gamma, 1, 0, 1)
-	c.Out = NewStateP(c.State.Gamma, 0.125, 0, 0.1)
+	c.In = NewStateP(s.Gamma, L.Rho, L.Rho*L.U, L.P)
+	c.Out = NewStateP(s.Gamma, R.Rho, R.Rho*R.U, R.P)
 	if VtInv, err = el.V.Transpose().Inverse(); err != nil {
 		panic(err)
 	}
 	MassMatrix = VtInv.Mul(el.Vinv)
 	CellCenterRValues := MassMatrix.Mul(el.X).SumCols().Scale(0.5)
 	cx := npOnes.Outer(CellCenterRValues)
-	leftHalf := cx.Find(utils.Less, 0.5, false)
-	rightHalf := cx.Find(utils.GreaterOrEqual, 0.5, false)
+	leftHalf := cx.Find(utils.Less, x0, false)
+	rightHalf := cx.Find(utils.GreaterOrEqual, x0, false)
 	// Initialize field variables
 	c.Rho = utils.NewMatrix(el.Np, el.K)
-	c.Rho.AssignScalar(leftHalf, 1)
-	c.Rho.AssignScalar(rightHalf, 0.125)
+	c.Rho.AssignScalar(leftHalf, c.In.Rho)
+	c.Rho.AssignScalar(rightHalf, c.Out.Rho)
 	c.RhoU = utils.NewMatrix(el.Np, el.K)
-	c.RhoU.Scale(0)
-	rDiv := 1. / (s.Gamma - 1.)
+	c.RhoU.AssignScalar(leftHalf, c.In.RhoU)
+	c.RhoU.AssignScalar(rightHalf, c.Out.RhoU)
 	c.Ener = utils.NewMatrix(el.Np, el.K)
-	c.Ener.AssignScalar(leftHalf, rDiv)
-	c.Ener.AssignScalar(rightHalf, 0.1*rDiv)
+	c.Ener.AssignScalar(leftHalf, c.In.Ener)
+	c.Ener.AssignScalar(rightHalf, c.Out.Ener)
 }
 
 func (c *Euler) InitializeDWave() {
@@ -265,13 +277,14 @@ func (c *Euler) Run(showGraph bool, graphDelay ...time.Duration) {
 			if isDone {
 				switch c.Case {
 				case SOD_TUBE:
-					sod := sod_shock_tube.NewSOD(Time)
-					x, rho, _, _, _ := sod.Get()
-					fmt.Printf("SOD Shock Location = %5.4f\n", x[len(x)-2])
+					x, rho, _, _, _ := c.Riemann.GetProfile(Time, el.X.Min(), el.X.Max(), 1001)
+					fmt.Printf("%s\n", c.Riemann.Print())
 					iRho = integrate(x, rho)
 					iRhoModel := integrate(elS.X.M.RawMatrix().Data, c.Rho.RawMatrix().Data)
 					logErr := math.Log10(math.Abs(iRho - iRhoModel))
-					rms_rho, rms_rhou, rms_e, max_rho, max_rhou, max_e := sod_error_calc(elS.X, c.Rho, c.RhoU, c.Ener, Time)
+					// The window left of the diaphragm excludes the shock and contact of Sod's problem
+					rms_rho, rms_rhou, rms_e, max_rho, max_rhou, max_e := shockTubeErrorCalc(c.Riemann, elS.X, c.Rho, c.RhoU, c.Ener, Time, 0.05, c.Riemann.X0)
+					frms_rho, frms_rhou, frms_e, fmax_rho, fmax_rhou, fmax_e := shockTubeErrorCalc(c.Riemann, elS.X, c.Rho, c.RhoU, c.Ener, Time, math.Inf(-1), math.Inf(1))
 					if math.Abs(Time-c.FinalTime) < 0.001 {
 						fmt.Printf("Rho Integration Check: Exact = %5.4f, Model = %5.4f, Log10 Error = %5.4f\n", iRho, iRhoModel, logErr)
 						/*
@@ -283,6 +296,10 @@ func (c *Euler) Run(showGraph bool, graphDelay ...time.Duration) {
 						fmt.Printf("\"%s\",%d,%d,%5.4f,%5.4f,%5.4f,%5.4f,%5.4f,%5.4f,%5.4f\n",
 							model_names[c.model], el.K, elS.Np-1, c.CFL, math.Log10(rms_rho), math.Log10(rms_rhou), math.Log10(rms_e),
 							math.Log10(max_rho), math.Log10(max_rhou), math.Log10(max_e))
+						fmt.Printf("Errors over the whole tube, including the shock and contact\n")
+						fmt.Printf("\"%s\",%d,%d,%5.4f,%5.4f,%5.4f,%5.4f,%5.4f,%5.4f,%5.4f\n",
+							model_names[c.model], el.K, elS.Np-1, c.CFL, math.Log10(frms_rho), math.Log10(frms_rhou), math.Log10(frms_e),
+							math.Log10(fmax_rho), math.Log10(fmax_rhou), math.Log10(fmax_e))
 					}
 				case DENSITY_WAVE:
 					rms_rho, max_rho := dwaveErrorCalc(elS.X, c.Rho, Time)
@@ -537,7 +554,7 @@ func (c *Euler) Plot(timeT float64, showGraph bool, graphDelay []time.Duration)
 	if c.frameCount%check == 0 || math.Abs(timeT-c.FinalTime) < 0.001 {
 		switch c.Case {
 		case SOD_TUBE:
-			iRho = AddAnalyticSod(c.chart, c.colorMap, timeT)
+			iRho = AddAnalyticShockTube(c.chart, c.colorMap, c.Riemann, timeT, el.X.Min(), el.X.Max())
 		case DENSITY_WAVE:
 			AddAnalyticDWave(c.chart, c.colorMap, elS.X, timeT)
 		}
@@ -580,9 +597,9 @@ func dwaveErrorCalc(X, Rho utils.Matrix, t float64) (rms_rho, max_rho float64) {
 	return
 }
 
-func AddAnalyticSod(chart *chart2d.Chart2D, colorMap *utils2.ColorMap, timeT float64) (iRho float64) {
-	sod := sod_shock_tube.NewSOD(timeT)
-	X, Rho, _, RhoU, E := sod.Get()
+func AddAnalyticShockTube(chart *chart2d.Chart2D, colorMap *utils2.ColorMap, rp *exact_riemann.RiemannProblem,
+	timeT, xMin, xMax float64) (iRho float64) {
+	X, Rho, _, RhoU, E := rp.GetProfile(timeT, xMin, xMax, 1001)
 	if err := chart.AddSeries("ExactRho", X, Rho, chart2d.XGlyph, chart2d.NoLine, colorMap.GetRGB(-0.7)); err != nil {
 		panic("unable to add exact solution Rho")
 	}
@@ -606,30 +623,30 @@ func integrate(x, u []float64) (result float64) {
 	return
 }
 
-func sod_error_calc(X, Rho, RhoU, E utils.Matrix, t float64) (rms_rho, rms_rhou, rms_e, max_rho, max_rhou, max_e float64) {
+func shockTubeErrorCalc(rp *exact_riemann.RiemannProblem, X, Rho, RhoU, E utils.Matrix, t, xMin, xMax float64) (rms_rho, rms_rhou, rms_e, max_rho, max_rhou, max_e float64) {
 	var (
 		Xdata    = X.RawMatrix().Data
 		RhoData  = Rho.RawMatrix().Data
 		RhoUData = RhoU.RawMatrix().Data
 		EData    = E.RawMatrix().Data
 	)
-	sod := sod_shock_tube.NewSOD(t)
+	// Only the points within xMin < x < xMax are used, the RMS is taken over all points as in the original Sod window
 	for i, x := range Xdata {
-		// Only validate using flow left of center, excluding the shock and contact discontinuity
-		if x < 0.5 && x > 0.05 {
-			sod_rho, _, _, sod_e, sod_rhou := sod.Getx(x)
-			rho, rhou, e := RhoData[i], RhoUData[i], EData[i]
-			rho_err := utils.POW(rho-sod_rho, 2)
-			rhou_err := utils.POW(rhou-sod_rhou, 2)
-			e_err := utils.POW(e-sod_e, 2)
-			rms_rho += rho_err
-			rms_rhou += rhou_err
-			rms_e += e_err
-			rho_err, rhou_err, e_err = math.Sqrt(rho_err), math.Sqrt(rhou_err), math.Sqrt(e_err)
-			max_rho = math.Max(rho_err, max_rho)
-			max_rhou = math.Max(rhou_err, max_rhou)
-			max_e = math.Max(e_err, max_e)
+		if x <= xMin || x >= xMax {
+			continue
 		}
+		exRho, exRhoU, exE := rp.GetStateC(t, x)
+		rho, rhou, e := RhoData[i], RhoUData[i], EData[i]
+		rho_err := utils.POW(rho-exRho, 2)
+		rhou_err := utils.POW(rhou-exRhoU, 2)
+		e_err := utils.POW(e-exE, 2)
+		rms_rho += rho_err
+		rms_rhou += rhou_err
+		rms_e += e_err
+		rho_err, rhou_err, e_err = math.Sqrt(rho_err), math.Sqrt(rhou_err), math.Sqrt(e_err)
+		max_rho = math.Max(rho_err, max_rho)
+		max_rhou = math.Max(rhou_err, max_rhou)
+		max_e = math.Max(e_err, max_e)
 	}
 	rms_rho = math.Sqrt(rms_rho / float64(len(Xdata)))
 	rms_rhou = math.Sqrt(rms_rhou / float64(len(Xdata)))
@@ -837,7 +854,7 @@ func (fs *FieldState) Update(Rho, RhoU, Ener utils.Matrix, c *Euler) (RhoFull, R
 		Calorically Perfect Gas, R = 1
 	*/
 	var (
-		Gamma                  = 1.4
+		Gamma                  = fs.Gamma
 		Cv                     = 1. / (Gamma - 1.)
 		Cp                     = Gamma * Cv
 		FluxRanger, FluxSubset = c.FluxRanger, c.FluxSubset
@@ -959,7 +976,7 @@ func NewState(gamma, rho, rhoU, ener float64) (s *State) {
 
 func NewStateP(gamma, rho, rhoU, p float64) *State {
 	q := 0.5 * rhoU * rhoU / rho
-	ener := (p - q) / (gamma - 1.)
+	ener := p/(gamma-1.) + q
 	return NewState(gamma, rho, rhoU, ener)
 }
 
@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/notargets/gocfd/model_problems/Euler1D/exact_riemann"
 	"github.com/notargets/gocfd/utils"
 )
 
@@ -107,3 +108,59 @@ func TestFlux(t *testing.T) {
 		assert.Less(t, rhoufCheck.Subtract(RhoUF).Apply(math.Abs).Max(), 0.0001)
 	}
 }
+
+func TestShockTube(t *testing.T) {
+	// The shock tube is initialized from the states, diaphragm and gamma of the Riemann problem
+	var (
+		L, R  = exact_riemann.State{Rho: 5.99924, U: 19.5975, P: 460.894}, exact_riemann.State{Rho: 5.99242, U: -6.19633, P: 46.0950}
+		gamma = 1.2
+		x0    = 0.3
+	)
+	rp := exact_riemann.NewRiemannProblem(L, R, gamma, x0)
+	for _, model := range []ModelType{Galerkin_LF, DFR_Roe} {
+		c := NewEuler(1, 0.01, 1, 2, 10, model, SOD_TUBE, rp)
+		assert.Equal(t, gamma, c.State.Gamma)
+		for _, check := range []struct {
+			s     exact_riemann.State
+			state *State
+		}{{L, c.In}, {R, c.Out}} {
+			rho, rhoU, E := check.s.Conserved(gamma)
+			assert.InDelta(t, rho, check.state.Rho, 1.e-12)
+			assert.InDelta(t, rhoU, check.state.RhoU, 1.e-12)
+			assert.InDelta(t, E, check.state.Ener, 1.e-9)
+		}
+		// Elements with centers left of the diaphragm have the left state
+		X := c.El_S.X
+		Np, K := X.Dims()
+		for k := 0; k < K; k++ {
+			state := c.Out
+			if 0.5*(X.At(0, k)+X.At(Np-1, k)) < x0 {
+				state = c.In
+			}
+			for i := 0; i < Np; i++ {
+				assert.Equal(t, state.Rho, c.Rho.At(i, k))
+				assert.Equal(t, state.RhoU, c.RhoU.At(i, k))
+				assert.Equal(t, state.Ener, c.Ener.At(i, k))
+			}
+		}
+	}
+	// Sod's problem is the default
+	c := NewEuler(1, 0.01, 1, 2, 10, DFR_Roe, SOD_TUBE)
+	assert.Equal(t, exact_riemann.NewSod(), c.Riemann)
+	assert.Equal(t, 1., c.In.Rho)
+	assert.Equal(t, 0.125, c.Out.Rho)
+	assert.InDelta(t, 0.25, c.Out.Ener, 1.e-12)
+	/*
+		Against the exact solution at t = 0.1 the initial state differs within the rarefaction left of the diaphragm,
+		the errors over the whole tube also include the shock and contact and are larger
+	*/
+	X := c.El_S.X
+	rmsRho, _, _, maxRho, _, _ := shockTubeErrorCalc(c.Riemann, X, c.Rho, c.RhoU, c.Ener, 0, 0.05, c.Riemann.X0)
+	assert.Equal(t, [2]float64{0, 0}, [2]float64{rmsRho, maxRho})
+	rmsRho, _, _, maxRho, _, _ = shockTubeErrorCalc(c.Riemann, X, c.Rho, c.RhoU, c.Ener, 0.1, 0.05, c.Riemann.X0)
+	rmsRhoAll, _, _, maxRhoAll, _, _ := shockTubeErrorCalc(c.Riemann, X, c.Rho, c.RhoU, c.Ener, 0.1, math.Inf(-1),
+		math.Inf(1))
+	assert.Greater(t, rmsRho, 0.)
+	assert.Greater(t, rmsRhoAll, rmsRho)
+	assert.GreaterOrEqual(t, maxRhoAll, maxRho)
+}
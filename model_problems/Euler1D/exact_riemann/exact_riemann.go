@@ -0,0 +1,339 @@
+package exact_riemann
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+The exact solution of the Riemann problem for the 1D Euler equations of an ideal gas, following chapter 4 of Toro,
+"Riemann Solvers and Numerical Methods for Fluid Dynamics", 3rd ed., 2009.
+
+A diaphragm at X0 separates the Left and Right states at t = 0. Each state is connected to the star region between
+them by a shock or a rarefaction, and the two sides of the star region share the pressure PStar and velocity UStar
+across the contact. The star pressure is the root of the pressure function, found by Newton iteration.
+
+When one of the states is a vacuum, or the states separate fast enough to generate one, there is no star region: the
+waves are rarefactions into a vacuum between them.
+*/
+type State struct {
+	Rho, U, P float64
+}
+
+func (s State) C(gamma float64) float64 {
+	return math.Sqrt(gamma * s.P / s.Rho)
+}
+
+func (s State) Conserved(gamma float64) (rho, rhoU, E float64) {
+	rho, rhoU, E = s.Rho, s.Rho*s.U, s.P/(gamma-1.)+0.5*s.Rho*s.U*s.U
+	return
+}
+
+type WaveType uint8
+
+const (
+	RAREFACTION WaveType = iota
+	SHOCK
+)
+
+var (
+	WavePrintNames = []string{"Rarefaction", "Shock"}
+)
+
+func (wt WaveType) Print() string {
+	return WavePrintNames[wt]
+}
+
+type RiemannProblem struct {
+	Left, Right         State
+	Gamma, X0           float64
+	PStar, UStar        float64  // Pressure and velocity of the star region, zero when there is a vacuum
+	LeftWave, RightWave WaveType // Waves on each side of the contact, rarefactions when there is a vacuum
+	Vacuum              bool     // A vacuum lies between the waves, either initially or generated by them
+}
+
+func NewRiemannProblem(left, right State, gamma, X0 float64) (rp *RiemannProblem) {
+	rp = &RiemannProblem{
+		Left:  left,
+		Right: right,
+		Gamma: gamma,
+		X0:    X0,
+	}
+	if left.Rho < 0 || right.Rho < 0 || left.P < 0 || right.P < 0 {
+		panic(fmt.Errorf("unable to solve the Riemann problem with negative density or pressure, left = %v, right = %v",
+			left, right))
+	}
+	var (
+		cL, cR = rp.sound(left), rp.sound(right)
+		G4     = 2. / (gamma - 1.)
+	)
+	switch {
+	case left.Rho == 0 || right.Rho == 0:
+		rp.Vacuum = true
+	case G4*(cL+cR) <= right.U-left.U: // Pressure positivity condition
+		rp.Vacuum = true
+	default:
+		rp.PStar, rp.UStar = rp.starState()
+		if rp.PStar > left.P {
+			rp.LeftWave = SHOCK
+		}
+		if rp.PStar > right.P {
+			rp.RightWave = SHOCK
+		}
+	}
+	return
+}
+
+func NewSod() (rp *RiemannProblem) {
+	return NewRiemannProblem(State{1, 0, 1}, State{0.125, 0, 0.1}, 1.4, 0.5)
+}
+
+/*
+The tests of table 4.1 in Toro, solved on [0,1] with gamma = 1.4 and the diaphragm at 0.5:
+
+	1: Sod's problem, a left rarefaction, a contact and a right shock
+	2: The 123 problem, two strong rarefactions with a near vacuum between them
+	3: The left half of the blast wave problem of Woodward and Colella, a strong right shock
+	4: The right half of the blast wave problem, a strong left shock
+	5: The collision of the shocks of tests 3 and 4, a left and a right shock
+*/
+var (
+	ToroTestStates = [5][2]State{
+		{{1, 0, 1}, {0.125, 0, 0.1}},
+		{{1, -2, 0.4}, {1, 2, 0.4}},
+		{{1, 0, 1000}, {1, 0, 0.01}},
+		{{1, 0, 0.01}, {1, 0, 100}},
+		{{5.99924, 19.5975, 460.894}, {5.99242, -6.19633, 46.0950}},
+	}
+	ToroTestTimes = [5]float64{0.25, 0.15, 0.012, 0.035, 0.035}
+)
+
+func NewToroTest(n int) (rp *RiemannProblem, finalTime float64) {
+	if n < 1 || n > len(ToroTestStates) {
+		panic(fmt.Errorf("unable to use Toro test %d, must be 1 to %d", n, len(ToroTestStates)))
+	}
+	rp = NewRiemannProblem(ToroTestStates[n-1][0], ToroTestStates[n-1][1], 1.4, 0.5)
+	finalTime = ToroTestTimes[n-1]
+	return
+}
+
+func (rp *RiemannProblem) sound(s State) float64 {
+	if s.Rho == 0 {
+		return 0
+	}
+	return s.C(rp.Gamma)
+}
+
+func (rp *RiemannProblem) pressureFunction(p float64, s State) (f, df float64) {
+	// The velocity change across the wave connecting state s to pressure p, and its derivative
+	var (
+		gamma = rp.Gamma
+		c     = rp.sound(s)
+	)
+	if p > s.P { // Shock
+		var (
+			A  = 2. / ((gamma + 1.) * s.Rho)
+			B  = s.P * (gamma - 1.) / (gamma + 1.)
+			qr = math.Sqrt(A / (B + p))
+		)
+		f = (p - s.P) * qr
+		df = qr * (1. - 0.5*(p-s.P)/(B+p))
+	} else { // Rarefaction
+		pRatio := p / s.P
+		f = 2. * c / (gamma - 1.) * (math.Pow(pRatio, 0.5*(gamma-1.)/gamma) - 1.)
+		df = math.Pow(pRatio, -0.5*(gamma+1.)/gamma) / (s.Rho * c)
+	}
+	return
+}
+
+func (rp *RiemannProblem) guessPressure() (p float64) {
+	// The adaptive initial guess of Toro section 9.5.1, from the linearized, two rarefaction or two shock solutions
+	var (
+		gamma          = rp.Gamma
+		L, R           = rp.Left, rp.Right
+		cL, cR         = rp.sound(L), rp.sound(R)
+		pMin, pMax     = math.Min(L.P, R.P), math.Max(L.P, R.P)
+		pPV            = 0.5*(L.P+R.P) - 0.125*(R.U-L.U)*(L.Rho+R.Rho)*(cL+cR)
+		z              = 0.5 * (gamma - 1.) / gamma
+		quser          = 2.
+		pTiny          = 1.e-12 * pMax
+		linearizedOkay = pMax/pMin <= quser && pMin <= pPV && pPV <= pMax
+	)
+	pPV = math.Max(pTiny, pPV)
+	switch {
+	case linearizedOkay:
+		p = pPV
+	case pPV < pMin: // Two rarefactions
+		p = math.Pow((cL+cR-0.5*(gamma-1.)*(R.U-L.U))/(cL/math.Pow(L.P, z)+cR/math.Pow(R.P, z)), 1./z)
+	default: // Two shocks
+		var (
+			gL = math.Sqrt(2. / ((gamma + 1.) * L.Rho) / (pPV + (gamma-1.)/(gamma+1.)*L.P))
+			gR = math.Sqrt(2. / ((gamma + 1.) * R.Rho) / (pPV + (gamma-1.)/(gamma+1.)*R.P))
+		)
+		p = (gL*L.P + gR*R.P - (R.U - L.U)) / (gL + gR)
+	}
+	return math.Max(pTiny, p)
+}
+
+func (rp *RiemannProblem) starState() (pStar, uStar float64) {
+	var (
+		L, R  = rp.Left, rp.Right
+		p     = rp.guessPressure()
+		tol   = 1.e-14
+		pTiny = 1.e-14 * math.Max(L.P, R.P)
+	)
+	for i := 0; i < 100; i++ {
+		fL, dfL := rp.pressureFunction(p, L)
+		fR, dfR := rp.pressureFunction(p, R)
+		pNew := math.Max(pTiny, p-(fL+fR+R.U-L.U)/(dfL+dfR))
+		change := 2. * math.Abs(pNew-p) / (pNew + p)
+		p = pNew
+		if change < tol {
+			break
+		}
+	}
+	fL, _ := rp.pressureFunction(p, L)
+	fR, _ := rp.pressureFunction(p, R)
+	pStar, uStar = p, 0.5*(L.U+R.U+fR-fL)
+	return
+}
+
+func (rp *RiemannProblem) StarDensities() (rhoL, rhoR float64) {
+	// The densities on the left and right of the contact in the star region
+	if rp.Vacuum {
+		return
+	}
+	return rp.starDensity(rp.Left, rp.LeftWave), rp.starDensity(rp.Right, rp.RightWave)
+}
+
+func (rp *RiemannProblem) starDensity(s State, wt WaveType) (rho float64) {
+	var (
+		gamma  = rp.Gamma
+		pRatio = rp.PStar / s.P
+		G6     = (gamma - 1.) / (gamma + 1.)
+	)
+	if wt == SHOCK {
+		return s.Rho * (pRatio + G6) / (pRatio*G6 + 1.)
+	}
+	return s.Rho * math.Pow(pRatio, 1./gamma)
+}
+
+func (rp *RiemannProblem) Get(t, x float64) (s State) {
+	// The state at location x and time t
+	if t <= 0 {
+		if x < rp.X0 {
+			return rp.Left
+		}
+		return rp.Right
+	}
+	return rp.Sample((x - rp.X0) / t)
+}
+
+func (rp *RiemannProblem) GetStateC(t, x float64) (rho, rhoU, E float64) {
+	return rp.Get(t, x).Conserved(rp.Gamma)
+}
+
+func (rp *RiemannProblem) Sample(S float64) (s State) {
+	// The self similar solution at the speed S = (x - X0) / t
+	var (
+		gamma  = rp.Gamma
+		L, R   = rp.Left, rp.Right
+		cL, cR = rp.sound(L), rp.sound(R)
+		G4     = 2. / (gamma - 1.)
+	)
+	if rp.Vacuum {
+		switch {
+		case L.Rho != 0 && S <= L.U+G4*cL: // Left of the left vacuum front
+			return rp.sampleLeft(S, L.U+G4*cL)
+		case R.Rho != 0 && S >= R.U-G4*cR: // Right of the right vacuum front
+			return rp.sampleRight(S, R.U-G4*cR)
+		}
+		return State{}
+	}
+	if S <= rp.UStar {
+		return rp.sampleLeft(S, rp.UStar-cL*math.Pow(rp.PStar/L.P, 0.5*(gamma-1.)/gamma))
+	}
+	return rp.sampleRight(S, rp.UStar+cR*math.Pow(rp.PStar/R.P, 0.5*(gamma-1.)/gamma))
+}
+
+func (rp *RiemannProblem) sampleLeft(S, tailSpeed float64) (s State) {
+	// Left of the contact, tailSpeed is the speed of the tail of a rarefaction
+	var (
+		gamma = rp.Gamma
+		L     = rp.Left
+		cL    = rp.sound(L)
+	)
+	if rp.LeftWave == SHOCK && !rp.Vacuum {
+		shockSpeed := L.U - cL*math.Sqrt(0.5*(gamma+1.)/gamma*rp.PStar/L.P+0.5*(gamma-1.)/gamma)
+		if S <= shockSpeed {
+			return L
+		}
+		return State{rp.starDensity(L, SHOCK), rp.UStar, rp.PStar}
+	}
+	switch {
+	case S <= L.U-cL: // Ahead of the head of the rarefaction
+		return L
+	case S >= tailSpeed: // Behind the tail of the rarefaction
+		return State{rp.starDensity(L, RAREFACTION), rp.UStar, rp.PStar}
+	}
+	c := 2. / (gamma + 1.) * (cL + 0.5*(gamma-1.)*(L.U-S))
+	s.U = 2. / (gamma + 1.) * (cL + 0.5*(gamma-1.)*L.U + S)
+	s.Rho = L.Rho * math.Pow(c/cL, 2./(gamma-1.))
+	s.P = L.P * math.Pow(c/cL, 2.*gamma/(gamma-1.))
+	return
+}
+
+func (rp *RiemannProblem) sampleRight(S, tailSpeed float64) (s State) {
+	// Right of the contact, tailSpeed is the speed of the tail of a rarefaction
+	var (
+		gamma = rp.Gamma
+		R     = rp.Right
+		cR    = rp.sound(R)
+	)
+	if rp.RightWave == SHOCK && !rp.Vacuum {
+		shockSpeed := R.U + cR*math.Sqrt(0.5*(gamma+1.)/gamma*rp.PStar/R.P+0.5*(gamma-1.)/gamma)
+		if S >= shockSpeed {
+			return R
+		}
+		return State{rp.starDensity(R, SHOCK), rp.UStar, rp.PStar}
+	}
+	switch {
+	case S >= R.U+cR: // Ahead of the head of the rarefaction
+		return R
+	case S <= tailSpeed: // Behind the tail of the rarefaction
+		return State{rp.starDensity(R, RAREFACTION), rp.UStar, rp.PStar}
+	}
+	c := 2. / (gamma + 1.) * (cR - 0.5*(gamma-1.)*(R.U-S))
+	s.U = 2. / (gamma + 1.) * (-cR + 0.5*(gamma-1.)*R.U + S)
+	s.Rho = R.Rho * math.Pow(c/cR, 2./(gamma-1.))
+	s.P = R.P * math.Pow(c/cR, 2.*gamma/(gamma-1.))
+	return
+}
+
+func (rp *RiemannProblem) GetProfile(t, xMin, xMax float64, nPts int) (X, Rho, P, RhoU, E []float64) {
+	// The solution at nPts equally spaced locations from xMin to xMax
+	X = make([]float64, nPts)
+	Rho = make([]float64, nPts)
+	P = make([]float64, nPts)
+	RhoU = make([]float64, nPts)
+	E = make([]float64, nPts)
+	for i := range X {
+		X[i] = xMin + (xMax-xMin)*float64(i)/float64(nPts-1)
+		s := rp.Get(t, X[i])
+		Rho[i], RhoU[i], E[i] = s.Conserved(rp.Gamma)
+		P[i] = s.P
+	}
+	return
+}
+
+func (rp *RiemannProblem) Print() (txt string) {
+	txt = fmt.Sprintf("Left [rho, u, p] = [%g, %g, %g], Right [rho, u, p] = [%g, %g, %g], Gamma = %g, X0 = %g\n",
+		rp.Left.Rho, rp.Left.U, rp.Left.P, rp.Right.Rho, rp.Right.U, rp.Right.P, rp.Gamma, rp.X0)
+	if rp.Vacuum {
+		txt += "Rarefactions into a vacuum"
+		return
+	}
+	txt += fmt.Sprintf("Left %s, Right %s, Star pressure = %g, Star velocity = %g",
+		rp.LeftWave.Print(), rp.RightWave.Print(), rp.PStar, rp.UStar)
+	return
+}
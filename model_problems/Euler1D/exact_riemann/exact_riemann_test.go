@@ -0,0 +1,118 @@
+package exact_riemann
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToroTests(t *testing.T) {
+	// The exact star region solutions of table 4.3 in Toro
+	starCheck := [5][4]float64{
+		{0.30313, 0.92745, 0.42632, 0.26557},
+		{0.00189, 0, 0.02185, 0.02185},
+		{460.894, 19.5975, 0.57506, 5.99924},
+		{46.0950, -6.19633, 5.99242, 0.57511},
+		{1691.64, 8.68975, 14.2823, 31.0426},
+	}
+	waveCheck := [5][2]WaveType{
+		{RAREFACTION, SHOCK},
+		{RAREFACTION, RAREFACTION},
+		{RAREFACTION, SHOCK},
+		{SHOCK, RAREFACTION},
+		{SHOCK, SHOCK},
+	}
+	for n := 1; n <= 5; n++ {
+		rp, finalTime := NewToroTest(n)
+		rhoL, rhoR := rp.StarDensities()
+		check := starCheck[n-1]
+		for i, val := range []float64{rp.PStar, rp.UStar, rhoL, rhoR} {
+			assert.InDelta(t, check[i], val, 1.e-5*math.Max(1, math.Abs(check[i])), "test %d, value %d", n, i)
+		}
+		assert.False(t, rp.Vacuum)
+		assert.Equal(t, waveCheck[n-1][0], rp.LeftWave)
+		assert.Equal(t, waveCheck[n-1][1], rp.RightWave)
+		// The initial states are unchanged outside of the waves and the star state is on both sides of the contact
+		assert.Equal(t, rp.Left, rp.Get(finalTime, 0))
+		assert.Equal(t, rp.Right, rp.Get(finalTime, 1))
+		sL := rp.Get(finalTime, rp.X0+(rp.UStar-1.e-6)*finalTime)
+		sR := rp.Get(finalTime, rp.X0+(rp.UStar+1.e-6)*finalTime)
+		assert.InDelta(t, rhoL, sL.Rho, 1.e-10*rhoL)
+		assert.InDelta(t, rhoR, sR.Rho, 1.e-10*rhoR)
+		for _, s := range []State{sL, sR} {
+			assert.InDelta(t, rp.PStar, s.P, 1.e-10*rp.PStar)
+			assert.InDelta(t, rp.UStar, s.U, 1.e-10*math.Max(1, math.Abs(rp.UStar)))
+		}
+	}
+}
+
+func TestSod(t *testing.T) {
+	// The density profile within the rarefaction, the star region and the shock at t = 0.1
+	rp := NewSod()
+	xCheck := []float64{0, 0.3815784043380077, 0.39280783577858336, 0.40393726721915907, 0.4150666986597348,
+		0.42619613010031043, 0.4373255615408861, 0.4484549929814618, 0.4595844244220375, 0.47071385586261316,
+		0.4818432873031888, 0.4930727187437645, 0.5926452620047974, 0.5928452620047974, 0.675115573202932,
+		0.675315573202932, 1}
+	rhoCheck := []float64{1, 1, 0.9240353444481086, 0.852758969991083, 0.7859504402212434, 0.7233963393812908,
+		0.6648901587403833, 0.6102321829702019, 0.5592293765210307, 0.5116952699978237, 0.467449846536279,
+		0.4263194281781805, 0.4263194281781805, 0.26557371170513905, 0.26557371170513905, 0.125, 0.125}
+	for i, x := range xCheck {
+		assert.InDelta(t, rhoCheck[i], rp.Get(0.1, x).Rho, 0.001, "x = %v", x)
+	}
+	// The shock location at t = 0.1 and 0.2
+	for _, check := range [][2]float64{{0.1, 0.6752}, {0.2, 0.8504}} {
+		tt, xShock := check[0], check[1]
+		assert.InDelta(t, 0.26557, rp.Get(tt, xShock-0.0001).Rho, 0.0001)
+		assert.Equal(t, 0.125, rp.Get(tt, xShock+0.0001).Rho)
+	}
+	// The initial condition
+	assert.Equal(t, rp.Left, rp.Get(0, 0.4999))
+	assert.Equal(t, rp.Right, rp.Get(0, 0.5))
+}
+
+func TestVacuum(t *testing.T) {
+	var (
+		gamma = 1.4
+		G4    = 2. / (gamma - 1.)
+		L     = State{1, 0, 1}
+		cL    = L.C(gamma)
+	)
+	{ // A vacuum on the right, the left state expands into it
+		rp := NewRiemannProblem(L, State{}, gamma, 0)
+		assert.True(t, rp.Vacuum)
+		assert.Equal(t, L, rp.Sample(-cL-1.e-6))
+		assert.Equal(t, State{}, rp.Sample(G4*cL+1.e-6))
+		s := rp.Sample(G4*cL - 1.e-6)
+		assert.InDelta(t, 0, s.Rho, 1.e-6)
+		assert.InDelta(t, G4*cL, s.U, 1.e-5)
+		s = rp.Sample(0)
+		assert.True(t, s.Rho > 0 && s.Rho < 1)
+		assert.InDelta(t, s.C(gamma), s.U, 1.e-12) // Sonic at the diaphragm
+	}
+	{ // A vacuum on the left, mirroring the vacuum on the right
+		rpR := NewRiemannProblem(L, State{}, gamma, 0)
+		rpL := NewRiemannProblem(State{}, L, gamma, 0)
+		assert.True(t, rpL.Vacuum)
+		for _, S := range []float64{-4, -2, -1, -0.5, 0, 0.5, 1, 2, 4} {
+			sR, sL := rpR.Sample(S), rpL.Sample(-S)
+			assert.InDelta(t, sR.Rho, sL.Rho, 1.e-12)
+			assert.InDelta(t, sR.U, -sL.U, 1.e-12)
+			assert.InDelta(t, sR.P, sL.P, 1.e-12)
+		}
+	}
+	{ // The states separate fast enough to generate a vacuum between two rarefactions
+		uR := 2*G4*cL + 0.5
+		rp := NewRiemannProblem(State{1, 0, 1}, State{1, uR, 1}, gamma, 0)
+		assert.True(t, rp.Vacuum)
+		assert.Equal(t, State{}, rp.Sample(0.5*uR))
+		assert.Equal(t, rp.Left, rp.Sample(-cL))
+		assert.Equal(t, rp.Right, rp.Sample(uR+cL))
+		assert.True(t, rp.Sample(0.1).Rho > 0)
+		assert.True(t, rp.Sample(uR-0.1).Rho > 0)
+		// Just short of the pressure positivity condition there is a near vacuum star region
+		rp = NewRiemannProblem(State{1, 0, 1}, State{1, 2*G4*cL - 0.01, 1}, gamma, 0)
+		assert.False(t, rp.Vacuum)
+		assert.True(t, rp.PStar > 0 && rp.PStar < 1.e-6)
+	}
+}
@@ -1,11 +1,12 @@
 package Euler2D
 
 import (
+	"fmt"
 	"math"
 
-	"github.com/notargets/gocfd/utils"
+	"github.com/notargets/gocfd/types"
 
-	"github.com/notargets/gocfd/model_problems/Euler2D/isentropic_vortex"
+	"github.com/notargets/gocfd/utils"
 )
 
 func (c *Euler) WallBC(k, Kmax int, Q_Face [4]utils.Matrix, ishift int, normal [2]float64, normalFlux [][4]float64) {
@@ -22,21 +23,22 @@ func (c *Euler) WallBC(k, Kmax int, Q_Face [4]utils.Matrix, ishift int, normal [
 	}
 }
 
-func (c *Euler) IVortexBC(Time float64, k, Kmax, ishift int, Q_Face [4]utils.Matrix, normal [2]float64) {
+func (c *Euler) ExactStateBC(Time float64, k, Kmax, myThread, ishift int, Q_Face [4]utils.Matrix, normal [2]float64) {
 	var (
 		Nedge   = c.dfr.FluxElement.NpEdge
 		Nint    = c.dfr.FluxElement.NpInt
 		qfD     = [4][]float64{Q_Face[0].DataP, Q_Face[1].DataP, Q_Face[2].DataP, Q_Face[3].DataP}
 		riemann = true
+		// The flux point coordinates are not sharded
+		kGlobal, KmaxGlobal = c.Partitions.GetGlobalK(k, myThread), c.dfr.K
 	)
 	// Set the flow variables to the exact solution
 	X, Y := c.dfr.FluxX.DataP, c.dfr.FluxY.DataP
 	for i := 0; i < Nedge; i++ {
 		iL := i + ishift
-		indFlux := k + (2*Nint+iL)*Kmax
+		indFlux := kGlobal + (2*Nint+iL)*KmaxGlobal
 		x, y := X[indFlux], Y[indFlux]
-		iv := c.AnalyticSolution.(*isentropic_vortex.IVortex)
-		rho, rhoU, rhoV, E := iv.GetStateC(Time, x, y)
+		rho, rhoU, rhoV, E := c.AnalyticSolution.GetStateC(Time, x, y)
 		ind := k + iL*Kmax
 		var QBC [4]float64
 		if riemann {
@@ -51,6 +53,31 @@ func (c *Euler) IVortexBC(Time float64, k, Kmax, ishift int, Q_Face [4]utils.Mat
 	}
 }
 
+func (c *Euler) ExactStateFlux(Time float64, k, Kmax, myThread, ishift int, Q_Face [4]utils.Matrix,
+	normal [2]float64, normalFlux [][4]float64) {
+	/*
+		The normal flux is the numerical flux between the interior and the exact solution outside of the boundary, the
+		same as for an interior edge, which imposes the exact state weakly on both inflow and outflow boundaries
+	*/
+	var (
+		Nedge  = c.dfr.FluxElement.NpEdge
+		Nint   = c.dfr.FluxElement.NpInt
+		X, Y   = c.dfr.FluxX.DataP, c.dfr.FluxY.DataP
+		QExact = c.exactFace[myThread]
+		// The flux point coordinates are not sharded
+		kGlobal, KmaxGlobal = c.Partitions.GetGlobalK(k, myThread), c.dfr.K
+	)
+	for i := 0; i < Nedge; i++ {
+		indFlux := kGlobal + (2*Nint+i+ishift)*KmaxGlobal
+		// Stored in reverse order, like the other element of a shared edge
+		iR := Nedge - 1 - i
+		QExact[0].DataP[iR], QExact[1].DataP[iR], QExact[2].DataP[iR], QExact[3].DataP[iR] =
+			c.AnalyticSolution.GetStateC(Time, X[indFlux], Y[indFlux])
+	}
+	// There are no interpolated fluxes for the exact state, the flux is calculated from the states on both sides
+	c.calculateNumericalFlux(k, 0, Kmax, 1, ishift, 0, Q_Face, QExact, nil, nil, normal, normalFlux)
+}
+
 func (c *Euler) FarBC(FS *FreeStream, k, Kmax, ishift int, Q_Face [4]utils.Matrix, normal [2]float64) {
 	var (
 		Nedge = c.dfr.FluxElement.NpEdge
@@ -117,3 +144,290 @@ func (c *Euler) RiemannBC(FS *FreeStream, k, Kmax, i int, QQ [4][]float64, QInf
 	Q[3] = p*OOGM1 + 0.5*rho*(u*u+v*v)
 	return
 }
+
+/*
+Inflow and outflow boundaries for internal flows, with parameters read from the BCs section of the input file using the
+boundary's tag, e.g. "In-1" or "Out-exit". Pressures are relative to the freestream static pressure and temperatures are
+relative to the freestream static temperature.
+
+Inflow parameters:
+
+	Pt    - Total pressure
+	Tt    - Total temperature
+	Alpha - Flow angle in degrees from the X axis, if omitted the flow enters normal to the boundary
+	Mach  - If 1 or greater the inflow is supersonic and the state is fixed by Pt, Tt and Mach
+
+Outflow parameters:
+
+	P     - Static back pressure, if omitted or the outflow is supersonic all variables are extrapolated
+*/
+type PressureBC struct {
+	Tag                                                 types.BCTAG
+	Inflow                                              bool
+	TotalPressure, TotalTemperature, Mach, BackPressure float64
+	Direction                                           [2]float64
+	NormalDirection                                     bool // Inflow is normal to the boundary
+}
+
+func NewPressureBC(tag types.BCTAG, params map[string]float64, fs *FreeStream) (pbc *PressureBC) {
+	var (
+		pt, okPt       = params["pt"]
+		tt, okTt       = params["tt"]
+		alpha, okAlpha = params["alpha"]
+	)
+	pbc = &PressureBC{
+		Tag:    tag,
+		Inflow: tag.GetFLAG() == types.BC_In,
+		Mach:   params["mach"],
+	}
+	if pbc.Inflow {
+		if !okPt || !okTt {
+			panic(fmt.Errorf("inflow BC [%s] requires both Pt and Tt", tag))
+		}
+		pbc.TotalPressure, pbc.TotalTemperature = pt*fs.Pinf, tt
+		pbc.NormalDirection = !okAlpha
+		pbc.Direction = [2]float64{math.Cos(alpha * math.Pi / 180.), math.Sin(alpha * math.Pi / 180.)}
+	} else {
+		pbc.BackPressure = params["p"] * fs.Pinf
+	}
+	return
+}
+
+func (pbc *PressureBC) Print() string {
+	if pbc.Inflow {
+		return fmt.Sprintf("Inflow BC [%s]: Pt = %8.5f, Tt = %8.5f, Mach = %5.3f", pbc.Tag, pbc.TotalPressure,
+			pbc.TotalTemperature, pbc.Mach)
+	}
+	return fmt.Sprintf("Outflow BC [%s]: P = %8.5f", pbc.Tag, pbc.BackPressure)
+}
+
+func (c *Euler) NewPressureBCs(ip *InputParameters) (pbcs map[types.EdgeKey]*PressureBC) {
+	/*
+		Only inflow boundaries with Pt or Tt and outflow boundaries with P use a PressureBC, the others use the FarBC.
+		Boundaries with other parameters, e.g. NPR from the nozzle inputs, keep the FarBC.
+	*/
+	pbcs = make(map[types.EdgeKey]*PressureBC)
+	for tag, edges := range c.dfr.BCEdges {
+		switch tag.GetFLAG() {
+		case types.BC_In, types.BC_Out:
+			params := ip.GetBCParameters(tag)
+			if !hasPressureBCParameters(tag, params) {
+				continue
+			}
+			pbc := NewPressureBC(tag, params, c.FSFar)
+			for _, e := range edges {
+				pbcs[e.GetKey()] = pbc
+			}
+		}
+	}
+	return
+}
+
+func hasPressureBCParameters(tag types.BCTAG, params map[string]float64) bool {
+	var (
+		_, okPt = params["pt"]
+		_, okTt = params["tt"]
+		_, okP  = params["p"]
+	)
+	if tag.GetFLAG() == types.BC_In {
+		return okPt || okTt
+	}
+	return okP
+}
+
+func (c *Euler) PressureBoundaryBC(pbc *PressureBC, k, Kmax, ishift int, Q_Face [4]utils.Matrix, normal [2]float64) {
+	var (
+		Nedge = c.dfr.FluxElement.NpEdge
+		qfD   = [4][]float64{Q_Face[0].DataP, Q_Face[1].DataP, Q_Face[2].DataP, Q_Face[3].DataP}
+	)
+	for i := 0; i < Nedge; i++ {
+		iL := i + ishift
+		ind := k + iL*Kmax
+		QInt := [4]float64{qfD[0][ind], qfD[1][ind], qfD[2][ind], qfD[3][ind]}
+		var QBC [4]float64
+		if pbc.Inflow {
+			QBC = c.InflowState(pbc, QInt, normal)
+		} else {
+			QBC = c.OutflowState(pbc, QInt, normal)
+		}
+		qfD[0][ind] = QBC[0]
+		qfD[1][ind] = QBC[1]
+		qfD[2][ind] = QBC[2]
+		qfD[3][ind] = QBC[3]
+	}
+}
+
+func (c *Euler) InflowState(pbc *PressureBC, QInt [4]float64, normal [2]float64) (Q [4]float64) {
+	/*
+		Subsonic inflow uses the outgoing Riemann invariant from the interior, R = Vn + 2*C/(Gamma-1), along with the
+		total enthalpy and flow direction to find the speed of sound and velocity magnitude, from Carlson (NASA TM 2011
+		217181). The static pressure follows from the total pressure and the Mach number.
+	*/
+	var (
+		Gamma = c.FSFar.Gamma
+		GM1   = Gamma - 1
+		Ct2   = pbc.TotalTemperature // Square of the total speed of sound, the freestream temperature is C*C = 1
+		dir   = pbc.Direction
+		C, U  float64
+	)
+	if pbc.NormalDirection {
+		dir = [2]float64{-normal[0], -normal[1]}
+	}
+	cosTheta := dir[0]*normal[0] + dir[1]*normal[1]
+	if pbc.Mach >= 1 {
+		C = math.Sqrt(Ct2 / (1 + 0.5*GM1*pbc.Mach*pbc.Mach))
+		U = pbc.Mach * C
+	} else {
+		var (
+			CInt  = c.FSFar.GetFlowFunctionQQ(QInt, SoundSpeed)
+			VnInt = (QInt[1]*normal[0] + QInt[2]*normal[1]) / QInt[0]
+			R     = VnInt + 2*CInt/GM1
+			cos2  = math.Max(cosTheta*cosTheta, 1.e-6)
+			a     = 1 + 2/(GM1*cos2)
+			b     = -2 * R / cos2
+			cc    = 0.5*GM1*R*R/cos2 - Ct2
+		)
+		C = (-b + math.Sqrt(math.Max(b*b-4*a*cc, 0))) / (2 * a)
+		C = math.Min(C, math.Sqrt(Ct2))
+		U = math.Sqrt(math.Max(2*(Ct2-C*C)/GM1, 0))
+	}
+	M2 := U * U / (C * C)
+	p := pbc.TotalPressure / math.Pow(1+0.5*GM1*M2, Gamma/GM1)
+	rho := Gamma * p / (C * C)
+	u, v := U*dir[0], U*dir[1]
+	Q = [4]float64{rho, rho * u, rho * v, p/GM1 + 0.5*rho*(u*u+v*v)}
+	return
+}
+
+func (c *Euler) OutflowState(pbc *PressureBC, QInt [4]float64, normal [2]float64) (Q [4]float64) {
+	/*
+		Subsonic outflow imposes the back pressure, with the entropy, tangential velocity and outgoing Riemann invariant
+		from the interior. Supersonic outflow extrapolates the interior state.
+	*/
+	var (
+		Gamma      = c.FSFar.Gamma
+		GM1        = Gamma - 1
+		rhoInt     = QInt[0]
+		uInt, vInt = QInt[1] / rhoInt, QInt[2] / rhoInt
+		pInt       = c.FSFar.GetFlowFunctionQQ(QInt, StaticPressure)
+		CInt       = c.FSFar.GetFlowFunctionQQ(QInt, SoundSpeed)
+		VnInt      = uInt*normal[0] + vInt*normal[1]
+	)
+	if pbc.BackPressure == 0 || VnInt >= CInt {
+		Q = QInt
+		return
+	}
+	p := pbc.BackPressure
+	rho := rhoInt * math.Pow(p/pInt, 1/Gamma)
+	C := math.Sqrt(Gamma * p / rho)
+	Vn := VnInt + 2*(CInt-C)/GM1
+	u, v := uInt+(Vn-VnInt)*normal[0], vInt+(Vn-VnInt)*normal[1]
+	Q = [4]float64{rho, rho * u, rho * v, p/GM1 + 0.5*rho*(u*u+v*v)}
+	return
+}
+
+func (c *Euler) SlipBC(k, Kmax, ishift int, Q_Face [4]utils.Matrix, normal [2]float64) {
+	/*
+		Symmetry plane or inviscid slip wall. The normal velocity is brought to zero along the outgoing characteristic,
+		keeping the Riemann invariant R = Vn + 2*C/(Gamma-1), the entropy and the tangential velocity from the interior
+	*/
+	var (
+		Nedge = c.dfr.FluxElement.NpEdge
+		qfD   = [4][]float64{Q_Face[0].DataP, Q_Face[1].DataP, Q_Face[2].DataP, Q_Face[3].DataP}
+		Gamma = c.FSFar.Gamma
+		GM1   = Gamma - 1
+	)
+	for i := 0; i < Nedge; i++ {
+		var (
+			ind    = k + (i+ishift)*Kmax
+			QInt   = [4]float64{qfD[0][ind], qfD[1][ind], qfD[2][ind], qfD[3][ind]}
+			rhoInt = QInt[0]
+			u, v   = QInt[1] / rhoInt, QInt[2] / rhoInt
+			pInt   = c.FSFar.GetFlowFunctionQQ(QInt, StaticPressure)
+			CInt   = c.FSFar.GetFlowFunctionQQ(QInt, SoundSpeed)
+			Vn     = u*normal[0] + v*normal[1]
+		)
+		C := math.Max(CInt+0.5*GM1*Vn, 0.01*CInt) // Limited for strong expansions away from the plane
+		rho := rhoInt * math.Pow(C/CInt, 2/GM1)
+		p := pInt * math.Pow(C/CInt, 2*Gamma/GM1)
+		u, v = u-Vn*normal[0], v-Vn*normal[1]
+		qfD[0][ind] = rho
+		qfD[1][ind] = rho * u
+		qfD[2][ind] = rho * v
+		qfD[3][ind] = p/GM1 + 0.5*rho*(u*u+v*v)
+	}
+}
+
+/*
+Fixed state boundary, with parameters read from the BCs section of the input file using the boundary's tag, e.g.
+"Dirichlet-left". Any omitted parameter takes the freestream value.
+
+	Rho   - Density relative to the freestream density
+	U, V  - Velocity in solver units, where the freestream speed of sound is 1
+	P     - Static pressure relative to the freestream static pressure
+*/
+type DirichletBC struct {
+	Tag types.BCTAG
+	Q   [4]float64
+}
+
+func NewDirichletBC(tag types.BCTAG, params map[string]float64, fs *FreeStream) (dbc *DirichletBC) {
+	var (
+		GM1 = fs.Gamma - 1
+		rho = fs.Qinf[0]
+		u   = fs.Qinf[1] / fs.Qinf[0]
+		v   = fs.Qinf[2] / fs.Qinf[0]
+		p   = fs.Pinf
+	)
+	if val, ok := params["rho"]; ok {
+		rho = val * fs.Qinf[0]
+	}
+	if val, ok := params["u"]; ok {
+		u = val
+	}
+	if val, ok := params["v"]; ok {
+		v = val
+	}
+	if val, ok := params["p"]; ok {
+		p = val * fs.Pinf
+	}
+	if rho <= 0 || p <= 0 {
+		panic(fmt.Errorf("dirichlet BC [%s] requires positive density and pressure, have %8.5f, %8.5f", tag, rho, p))
+	}
+	dbc = &DirichletBC{
+		Tag: tag,
+		Q:   [4]float64{rho, rho * u, rho * v, p/GM1 + 0.5*rho*(u*u+v*v)},
+	}
+	return
+}
+
+func (dbc *DirichletBC) Print() string {
+	return fmt.Sprintf("Dirichlet BC [%s]: Q = [%8.5f, %8.5f, %8.5f, %8.5f]", dbc.Tag,
+		dbc.Q[0], dbc.Q[1], dbc.Q[2], dbc.Q[3])
+}
+
+func (c *Euler) NewDirichletBCs(ip *InputParameters) (dbcs map[types.EdgeKey]*DirichletBC) {
+	dbcs = make(map[types.EdgeKey]*DirichletBC)
+	for tag, edges := range c.dfr.BCEdges {
+		if tag.GetFLAG() != types.BC_Dirichlet {
+			continue
+		}
+		dbc := NewDirichletBC(tag, ip.GetBCParameters(tag), c.FSFar)
+		for _, e := range edges {
+			dbcs[e.GetKey()] = dbc
+		}
+	}
+	return
+}
+
+func (c *Euler) DirichletBoundaryBC(dbc *DirichletBC, k, Kmax, ishift int, Q_Face [4]utils.Matrix) {
+	var (
+		Nedge = c.dfr.FluxElement.NpEdge
+	)
+	for i := 0; i < Nedge; i++ {
+		ind := k + (i+ishift)*Kmax
+		for n := 0; n < 4; n++ {
+			Q_Face[n].DataP[ind] = dbc.Q[n]
+		}
+	}
+}
@@ -50,6 +50,34 @@ func BenchmarkEulerSolve(b *testing.B) {
 	}
 }
 
+func BenchmarkEulerStep(b *testing.B) {
+	/*
+		Time per explicit time step for a range of shard counts on a small and a larger mesh. On small meshes the
+		synchronization of the shards between the substeps is a large part of the cost of a step. Each case is run with
+		the persistent worker pool and with new goroutines spawned for every substep, as the solver did before the pool.
+	*/
+	ip := *ipDefault
+	ip.Minf = 0.5
+	ip.FinalTime = 1.e6
+	ip.PolynomialOrder = 2
+	for _, mesh := range []string{"test_tris_9.neu", "vortex-new.su2"} {
+		for _, np := range []int{1, 2, 4} {
+			for _, workers := range []string{"spawn", "pool"} {
+				c := Euler2D.NewEuler(&ip, "../../../DG2D/"+mesh, np, false, false, false)
+				rk := c.NewRungeKuttaSSP()
+				rk.Pool.Spawn = workers == "spawn"
+				b.Run(fmt.Sprintf("%s/partitions=%d/workers=%s", mesh, c.Partitions.ParallelDegree, workers),
+					func(b *testing.B) {
+						for i := 0; i < b.N; i++ {
+							rk.Step(c)
+						}
+					})
+				rk.Close()
+			}
+		}
+	}
+}
+
 func BenchmarkEulerGetFlowFunction(b *testing.B) {
 	ip := ipDefault
 	ip.Minf = 1.
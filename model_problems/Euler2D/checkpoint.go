@@ -0,0 +1,192 @@
+package Euler2D
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/notargets/gocfd/utils"
+)
+
+/*
+A checkpoint stores the solution in global element order (Np x K) so that it is independent of the number of
+parallel partitions used when it was written. On restart, the solution is resharded through the current
+PartitionMap, which allows a run to be resumed with a different ParallelProcLimit. Elements renumbered by the
+partitioner are stored in the order of the mesh file, as the renumbering depends on the number of partitions.
+*/
+type Checkpoint struct {
+	MeshFile        string
+	MeshChecksum    string // SHA256 of the mesh file contents, used to verify the mesh identity on restart
+	K, Np           int    // Element count and number of solution points per element
+	PolynomialOrder int
+	Time            float64 // Not advanced with local time stepping, where StepCount is the iteration
+	StepCount       int
+	Parameters      InputParameters
+	Q               [4][]float64 // Global solution, row major Np x K
+}
+
+func GetMeshChecksum(meshFile string) (sum string, err error) {
+	var (
+		data []byte
+	)
+	if data, err = ioutil.ReadFile(meshFile); err != nil {
+		return
+	}
+	hash := sha256.Sum256(data)
+	sum = hex.EncodeToString(hash[:])
+	return
+}
+
+func (c *Euler) NewCheckpoint(rk *RungeKutta4SSP) (cp *Checkpoint, err error) {
+	cp = &Checkpoint{
+		MeshFile:        c.MeshFile,
+		K:               c.dfr.K,
+		Np:              c.dfr.SolutionElement.Np,
+		PolynomialOrder: c.dfr.N,
+		Time:            rk.Time,
+		StepCount:       rk.StepCount,
+	}
+	if c.InputParams != nil {
+		cp.Parameters = *c.InputParams
+	}
+	if cp.MeshChecksum, err = GetMeshChecksum(c.MeshFile); err != nil {
+		return
+	}
+	Q := c.RecombineShardsKBy4(c.Q)
+	for n := 0; n < 4; n++ {
+		cp.Q[n] = c.toMeshFileOrder(Q[n]).DataP
+	}
+	return
+}
+
+func (c *Euler) MeshFileElements() (elements []int) {
+	// Returns the solver element number of each element of the mesh file
+	elements = make([]int, c.dfr.K)
+	for k := range elements {
+		elements[k] = k
+	}
+	for k, kFile := range c.dfr.ElementOrder {
+		elements[kFile] = k
+	}
+	return
+}
+
+func (c *Euler) toMeshFileOrder(A utils.Matrix) (R utils.Matrix) {
+	// Moves the columns of an Np x K matrix from the solver element order to the mesh file element order
+	var (
+		order = c.dfr.ElementOrder
+	)
+	if order == nil {
+		return A
+	}
+	Imax, Kmax := A.Dims()
+	R = utils.NewMatrix(Imax, Kmax)
+	for k, kFile := range order {
+		for i := 0; i < Imax; i++ {
+			R.DataP[kFile+i*Kmax] = A.DataP[k+i*Kmax]
+		}
+	}
+	return
+}
+
+func (c *Euler) fromMeshFileOrder(A utils.Matrix) (R utils.Matrix) {
+	// Moves the columns of an Np x K matrix from the mesh file element order to the solver element order
+	var (
+		order = c.dfr.ElementOrder
+	)
+	if order == nil {
+		return A
+	}
+	Imax, Kmax := A.Dims()
+	R = utils.NewMatrix(Imax, Kmax)
+	for k, kFile := range order {
+		for i := 0; i < Imax; i++ {
+			R.DataP[k+i*Kmax] = A.DataP[kFile+i*Kmax]
+		}
+	}
+	return
+}
+
+func (c *Euler) WriteCheckpoint(fileName string, rk *RungeKutta4SSP) (err error) {
+	var (
+		cp  *Checkpoint
+		tmp *os.File
+	)
+	if cp, err = c.NewCheckpoint(rk); err != nil {
+		return
+	}
+	// Write to a temporary file first, so that a job killed while writing leaves the previous checkpoint intact
+	if tmp, err = ioutil.TempFile(filepath.Dir(fileName), filepath.Base(fileName)+".tmp"); err != nil {
+		return
+	}
+	if err = gob.NewEncoder(tmp).Encode(cp); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return
+	}
+	if err = tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return
+	}
+	err = os.Rename(tmp.Name(), fileName)
+	return
+}
+
+func ReadCheckpoint(fileName string) (cp *Checkpoint, err error) {
+	var (
+		f *os.File
+	)
+	if f, err = os.Open(fileName); err != nil {
+		return
+	}
+	defer f.Close()
+	cp = &Checkpoint{}
+	if err = gob.NewDecoder(f).Decode(cp); err != nil {
+		err = fmt.Errorf("unable to read checkpoint file %s: %s", fileName, err.Error())
+	}
+	return
+}
+
+func (c *Euler) RestoreCheckpoint(cp *Checkpoint, rk *RungeKutta4SSP) (err error) {
+	var (
+		Np = c.dfr.SolutionElement.Np
+	)
+	switch {
+	case cp.PolynomialOrder != c.dfr.N:
+		err = fmt.Errorf("checkpoint polynomial order %d does not match solver order %d",
+			cp.PolynomialOrder, c.dfr.N)
+	case cp.K != c.dfr.K || cp.Np != Np:
+		err = fmt.Errorf("checkpoint dimensions [Np,K] = [%d,%d] do not match the mesh [%d,%d]",
+			cp.Np, cp.K, Np, c.dfr.K)
+	}
+	if err != nil {
+		return
+	}
+	var sum string
+	if sum, err = GetMeshChecksum(c.MeshFile); err != nil {
+		return
+	}
+	if sum != cp.MeshChecksum {
+		err = fmt.Errorf("mesh file %s differs from the mesh used to write the checkpoint (%s)",
+			c.MeshFile, cp.MeshFile)
+		return
+	}
+	// Reshard the global solution using the current partitions
+	for n := 0; n < 4; n++ {
+		if len(cp.Q[n]) != Np*cp.K {
+			err = fmt.Errorf("checkpoint solution variable %d has length %d, should be %d", n, len(cp.Q[n]), Np*cp.K)
+			return
+		}
+		Qs := c.ShardByK(c.fromMeshFileOrder(utils.NewMatrix(Np, cp.K, cp.Q[n])))
+		for np := 0; np < c.Partitions.ParallelDegree; np++ {
+			c.Q[np][n] = Qs[np]
+		}
+	}
+	rk.Time = cp.Time
+	rk.StepCount = cp.StepCount
+	return
+}
@@ -0,0 +1,222 @@
+package Euler2D
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/notargets/gocfd/utils"
+)
+
+type ResidualNorm uint8
+
+const (
+	Res0 ResidualNorm = iota // Max residual of each of the four equations
+	Res1
+	Res2
+	Res3
+	ResL1 // Max of the four equation residuals
+	ResL2 // RMS of the four equation residuals
+)
+
+var (
+	ResidualNormNames = map[string]ResidualNorm{
+		"res0": Res0,
+		"res1": Res1,
+		"res2": Res2,
+		"res3": Res3,
+		"l1":   ResL1,
+		"l2":   ResL2,
+	}
+	ResidualNormPrintNames = []string{"Res0", "Res1", "Res2", "Res3", "L1", "L2"}
+)
+
+func (rn ResidualNorm) Print() string {
+	return ResidualNormPrintNames[rn]
+}
+
+func NewResidualNorm(label string) (rn ResidualNorm) {
+	var (
+		ok  bool
+		err error
+	)
+	if len(label) == 0 {
+		return ResL2
+	}
+	label = strings.ToLower(strings.TrimSpace(label))
+	if rn, ok = ResidualNormNames[label]; !ok {
+		err = fmt.Errorf("unable to use residual norm named [%s]", label)
+		panic(err)
+	}
+	return
+}
+
+func (c *Euler) GetResidualNorms(Residual [][4]utils.Matrix) (res [6]float64) {
+	shardMax := make([][4]float64, c.Partitions.ParallelDegree)
+	for np := range shardMax {
+		shardMax[np] = GetShardResidualMax(Residual[np])
+	}
+	return GetResidualNormsFromMax(shardMax)
+}
+
+func GetShardResidualMax(Residual [4]utils.Matrix) (maxR [4]float64) {
+	// The max residual of each equation within one shard, computed by the shard's worker
+	for n := 0; n < 4; n++ {
+		maxR[n] = Residual[n].Max()
+	}
+	return
+}
+
+func GetResidualNormsFromMax(shardMax [][4]float64) (res [6]float64) {
+	/*
+		The norms printed by PrintUpdate: the max residual for each equation, followed by the max and the RMS of those
+	*/
+	var l1, l2 float64
+	for n := 0; n < 4; n++ {
+		var maxR float64
+		for np := range shardMax {
+			if m := shardMax[np][n]; m > maxR {
+				maxR = m
+			}
+		}
+		res[n] = maxR
+		if maxR > l1 {
+			l1 = maxR
+		}
+		l2 += maxR * maxR
+	}
+	res[ResL1] = l1
+	res[ResL2] = math.Sqrt(l2) / 4.
+	return
+}
+
+/*
+Convergence is reached when the monitored residual norm drops below the absolute target, or has been reduced by the
+requested orders of magnitude relative to its value at the first iteration. A zero target disables that test.
+*/
+type ConvergenceMonitor struct {
+	Norm            ResidualNorm
+	Target          float64 // Absolute residual target
+	OrdersOfMag     float64 // Reduction in orders of magnitude relative to the first iteration
+	InitialResidual float64
+}
+
+func NewConvergenceMonitor(ip *InputParameters) (cm *ConvergenceMonitor) {
+	if ip.ResidualTarget == 0 && ip.ResidualReduction == 0 {
+		return
+	}
+	cm = &ConvergenceMonitor{
+		Norm:        NewResidualNorm(ip.ResidualNorm),
+		Target:      ip.ResidualTarget,
+		OrdersOfMag: ip.ResidualReduction,
+	}
+	return
+}
+
+func (cm *ConvergenceMonitor) Converged(res [6]float64) (converged bool) {
+	r := res[cm.Norm]
+	if cm.InitialResidual == 0 {
+		cm.InitialResidual = r
+	}
+	if cm.Target != 0 && r <= cm.Target {
+		converged = true
+	}
+	if cm.OrdersOfMag != 0 && cm.InitialResidual != 0 && r > 0 &&
+		math.Log10(cm.InitialResidual/r) >= cm.OrdersOfMag {
+		converged = true
+	}
+	return
+}
+
+func (cm *ConvergenceMonitor) Print() string {
+	var txt string
+	if cm.Target != 0 {
+		txt += fmt.Sprintf("%s <= %8.3e", cm.Norm.Print(), cm.Target)
+	}
+	if cm.OrdersOfMag != 0 {
+		if len(txt) != 0 {
+			txt += " or "
+		}
+		txt += fmt.Sprintf("%s reduced by %5.2f orders of magnitude", cm.Norm.Print(), cm.OrdersOfMag)
+	}
+	return txt
+}
+
+type ResidualRecord struct {
+	Iteration     int        `json:"iteration"`
+	Time          float64    `json:"time"`
+	DT            float64    `json:"dt"`
+	Residual      [6]float64 `json:"residual"` // Res0, Res1, Res2, Res3, L1, L2
+	LimitedPoints int        `json:"limitedPoints"`
+	WallClock     float64    `json:"wallClock"` // Seconds of solver time
+}
+
+/*
+The residual history is written as CSV or JSON depending on the file extension. The CSV file is written as the
+solution progresses, the JSON file contains an array of records and is written when the history is closed.
+*/
+type ResidualHistory struct {
+	FileName string
+	Records  []ResidualRecord
+	isJSON   bool
+	file     *os.File
+}
+
+func NewResidualHistory(fileName string) (rh *ResidualHistory, err error) {
+	rh = &ResidualHistory{
+		FileName: fileName,
+		isJSON:   strings.ToLower(filepath.Ext(fileName)) == ".json",
+	}
+	if rh.file, err = os.Create(fileName); err != nil {
+		return
+	}
+	if !rh.isJSON {
+		_, err = fmt.Fprintln(rh.file, "iter,time,dt,Res0,Res1,Res2,Res3,L1,L2,limited,wallclock")
+	}
+	return
+}
+
+func (rh *ResidualHistory) Add(rec ResidualRecord) (err error) {
+	rh.Records = append(rh.Records, rec)
+	if !rh.isJSON {
+		r := rec.Residual
+		_, err = fmt.Fprintf(rh.file, "%d,%.10e,%.10e,%.10e,%.10e,%.10e,%.10e,%.10e,%.10e,%d,%.6f\n",
+			rec.Iteration, rec.Time, rec.DT, r[0], r[1], r[2], r[3], r[4], r[5], rec.LimitedPoints, rec.WallClock)
+	}
+	return
+}
+
+func (rh *ResidualHistory) Close() (err error) {
+	if rh.file == nil {
+		return
+	}
+	if rh.isJSON {
+		enc := json.NewEncoder(rh.file)
+		enc.SetIndent("", " ")
+		err = enc.Encode(rh.Records)
+	}
+	if errC := rh.file.Close(); err == nil {
+		err = errC
+	}
+	rh.file = nil
+	return
+}
+
+func (c *Euler) NewResidualRecord(Time, dt float64, steps int, residual [6]float64, limitedPoints []int,
+	elapsed time.Duration) (rec ResidualRecord) {
+	rec = ResidualRecord{
+		Iteration: steps,
+		Time:      Time,
+		DT:        dt,
+		Residual:  residual,
+		WallClock: elapsed.Seconds(),
+	}
+	for _, val := range limitedPoints {
+		rec.LimitedPoints += val
+	}
+	return
+}
@@ -0,0 +1,264 @@
+package Euler2D
+
+import (
+	"compress/bzip2"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+/*
+Norms of the difference between the solution and an exact solution, for each conserved variable. The L1 and L2 norms
+are integrals over the domain divided by its area, using the quadrature of the solution points, so the L2 norm is the
+RMS error. The Linf norm is the largest error at a solution point.
+*/
+type SolutionError struct {
+	L1, L2, Linf [4]float64
+	Area         float64
+}
+
+func (c *Euler) GetSolutionError(exact ExactState, Time float64) (se SolutionError) {
+	var (
+		w      = c.solutionPointWeights()
+		Jdet   = c.ShardByKTranspose(c.dfr.Jdet)
+		SX, SY = c.ShardByK(c.dfr.SolutionX), c.ShardByK(c.dfr.SolutionY)
+	)
+	for np := 0; np < c.Partitions.ParallelDegree; np++ {
+		Kmax := c.Partitions.GetBucketDimension(np)
+		for k := 0; k < Kmax; k++ {
+			for i := range w {
+				ind := k + i*Kmax
+				wJ := w[i] * Jdet[np].DataP[k]
+				var qe [4]float64
+				qe[0], qe[1], qe[2], qe[3] = exact.GetStateC(Time, SX[np].DataP[ind], SY[np].DataP[ind])
+				for n := 0; n < 4; n++ {
+					e := math.Abs(c.Q[np][n].DataP[ind] - qe[n])
+					se.L1[n] += wJ * e
+					se.L2[n] += wJ * e * e
+					se.Linf[n] = math.Max(se.Linf[n], e)
+				}
+				se.Area += wJ
+			}
+		}
+	}
+	for n := 0; n < 4; n++ {
+		se.L1[n] /= se.Area
+		se.L2[n] = math.Sqrt(se.L2[n] / se.Area)
+	}
+	return
+}
+
+func (se SolutionError) Print() (txt string) {
+	txt = fmt.Sprintf("%6s %12s %12s %12s\n", "", "L1", "L2", "Linf")
+	for n, name := range []string{"rho", "rhoU", "rhoV", "E"} {
+		txt += fmt.Sprintf("%6s %12.5e %12.5e %12.5e\n", name, se.L1[n], se.L2[n], se.Linf[n])
+	}
+	return
+}
+
+/*
+A convergence study solves an analytic case on a sequence of meshes for each polynomial order, and measures the error
+at the final time. The mesh spacing is the square root of the average element area.
+*/
+type ConvergenceStudyResult struct {
+	MeshFile string
+	N, K, Np int
+	H        float64 // Mesh spacing
+	CFL      float64
+	Time     float64 // Final time, where the error is measured
+	Error    SolutionError
+}
+
+func RunConvergenceStudy(base *InputParameters, meshFiles []string, orders []int, ProcLimit int,
+	pm *PlotMeta) (results []ConvergenceStudyResult) {
+	if len(orders) == 0 {
+		orders = []int{base.PolynomialOrder}
+	}
+	for _, N := range orders {
+		for _, meshFile := range meshFiles {
+			ip := &InputParameters{}
+			*ip = *base
+			ip.PolynomialOrder = N
+			fmt.Printf("\nConvergence study: N = %d, mesh [%s]\n", N, meshFile)
+			readFile, cleanup := uncompressedMeshFile(meshFile)
+			c := NewEuler(ip, readFile, ProcLimit, false, false, false)
+			cleanup()
+			if c.AnalyticSolution == nil {
+				panic(fmt.Errorf("initial condition [%s] has no exact solution", c.Case.Print()))
+			}
+			if ip.ImplicitSolver {
+				c.SolveImplicit(pm)
+			} else {
+				c.Solve(pm)
+			}
+			r := ConvergenceStudyResult{
+				MeshFile: meshFile,
+				N:        N,
+				K:        c.dfr.K,
+				Np:       c.dfr.SolutionElement.Np,
+				CFL:      ip.CFL,
+				Time:     c.Result.Time,
+				Error:    c.GetSolutionError(c.AnalyticSolution, c.Result.Time),
+			}
+			r.H = math.Sqrt(r.Error.Area / float64(r.K))
+			results = append(results, r)
+		}
+	}
+	return
+}
+
+func uncompressedMeshFile(meshFile string) (readFile string, cleanup func()) {
+	// Meshes compressed with bzip2 are uncompressed into a temporary file that is removed by cleanup
+	cleanup = func() {}
+	if !strings.HasSuffix(meshFile, ".bz2") {
+		return meshFile, cleanup
+	}
+	var (
+		dir string
+		in  *os.File
+		out *os.File
+		err error
+	)
+	if in, err = os.Open(meshFile); err != nil {
+		panic(err)
+	}
+	defer in.Close()
+	if dir, err = ioutil.TempDir("", "gocfd-mesh"); err != nil {
+		panic(err)
+	}
+	readFile = filepath.Join(dir, strings.TrimSuffix(filepath.Base(meshFile), ".bz2"))
+	if out, err = os.Create(readFile); err != nil {
+		panic(err)
+	}
+	if _, err = io.Copy(out, bzip2.NewReader(in)); err != nil {
+		panic(err)
+	}
+	if err = out.Close(); err != nil {
+		panic(err)
+	}
+	cleanup = func() { _ = os.RemoveAll(dir) }
+	return
+}
+
+func WriteConvergenceStudyCSV(fileName, title string, results []ConvergenceStudyResult) (err error) {
+	/*
+		Written in the format read by tools/convOrder: the mesh size is given as the number of points in each direction,
+		the square root of the number of solution points, followed by log10 of the RMS and max errors of rho, rhoU and E
+	*/
+	var file *os.File
+	if file, err = os.Create(fileName); err != nil {
+		return
+	}
+	defer func() {
+		if errC := file.Close(); err == nil {
+			err = errC
+		}
+	}()
+	if _, err = fmt.Fprintln(file,
+		"case,K,N,CFL,Log10_Rho_rms,Log10_Rhou_rms,Log10_e_rms,Log10_rho_max,Log10_rhou_max,Log10_e_max"); err != nil {
+		return
+	}
+	for _, r := range results {
+		var (
+			nPts = int(math.Round(math.Sqrt(float64(r.K * r.Np))))
+			e    = r.Error
+		)
+		if _, err = fmt.Fprintf(file, "\"%s\",%d,%d,%g,%.6f,%.6f,%.6f,%.6f,%.6f,%.6f\n",
+			title, nPts, r.N, r.CFL, math.Log10(e.L2[0]), math.Log10(e.L2[1]), math.Log10(e.L2[3]),
+			math.Log10(e.Linf[0]), math.Log10(e.Linf[1]), math.Log10(e.Linf[3])); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func ObservedOrders(results []ConvergenceStudyResult) (orders map[int]SolutionError) {
+	/*
+		The order of each norm and variable for each polynomial order, from a least squares fit of log(error) against
+		log(H) over the meshes
+	*/
+	var (
+		byN = make(map[int][]ConvergenceStudyResult)
+	)
+	orders = make(map[int]SolutionError)
+	for _, r := range results {
+		byN[r.N] = append(byN[r.N], r)
+	}
+	for N, rs := range byN {
+		if len(rs) < 2 {
+			continue
+		}
+		var (
+			logH = make([]float64, len(rs))
+			p    SolutionError
+		)
+		for i, r := range rs {
+			logH[i] = math.Log(r.H)
+		}
+		for n := 0; n < 4; n++ {
+			p.L1[n] = logSlope(logH, rs, func(se SolutionError) float64 { return se.L1[n] })
+			p.L2[n] = logSlope(logH, rs, func(se SolutionError) float64 { return se.L2[n] })
+			p.Linf[n] = logSlope(logH, rs, func(se SolutionError) float64 { return se.Linf[n] })
+		}
+		orders[N] = p
+	}
+	return
+}
+
+func logSlope(logH []float64, rs []ConvergenceStudyResult, norm func(se SolutionError) float64) (slope float64) {
+	var (
+		n                = float64(len(logH))
+		sx, sy, sxx, sxy float64
+	)
+	for i, x := range logH {
+		y := math.Log(norm(rs[i].Error))
+		sx += x
+		sy += y
+		sxx += x * x
+		sxy += x * y
+	}
+	slope = (n*sxy - sx*sy) / (n*sxx - sx*sx)
+	return
+}
+
+func PrintConvergenceStudy(results []ConvergenceStudyResult) {
+	var (
+		names  = []string{"rho", "rhoU", "rhoV", "E"}
+		orders = ObservedOrders(results)
+	)
+	for i, r := range results {
+		if i == 0 || results[i-1].N != r.N {
+			fmt.Printf("\nPolynomial Order N = %d\n", r.N)
+			fmt.Printf("%8s %12s", "K", "H")
+			for _, name := range names {
+				fmt.Printf(" %12s", "L2("+name+")")
+			}
+			fmt.Printf(" %12s\n", "Linf(rho)")
+		}
+		fmt.Printf("%8d %12.5e", r.K, r.H)
+		for n := 0; n < 4; n++ {
+			fmt.Printf(" %12.5e", r.Error.L2[n])
+		}
+		fmt.Printf(" %12.5e\n", r.Error.Linf[0])
+		if i == len(results)-1 || results[i+1].N != r.N {
+			p, ok := orders[r.N]
+			if !ok {
+				continue
+			}
+			for _, norm := range []struct {
+				label string
+				vals  [4]float64
+			}{{"L1", p.L1}, {"L2", p.L2}, {"Linf", p.Linf}} {
+				fmt.Printf("Observed %-4s order:", norm.label)
+				for n, name := range names {
+					fmt.Printf(" %s = %5.3f", name, norm.vals[n])
+				}
+				fmt.Printf("\n")
+			}
+		}
+	}
+}
@@ -266,22 +266,29 @@ func (sd *ScalarDissipation) AddDissipation(c *Euler, cont ContinuityLevel, myTh
 		dU/dT = -(Div(Flux) - Div(epsilon*Grad(U)))
 		dU/dT = -Div(Flux - epsilon*Grad(U))
 	*/
+	c.AddFluxDivergence(myThread, GradientFluxForLaplacian, Jinv, Jdet, sd.DissX[myThread], sd.DissY[myThread],
+		sd.DissDOF[myThread], sd.DissDiv[myThread], RHSQ)
+}
+
+func (c *Euler) AddFluxDivergence(myThread int, valType ValueType, Jinv, Jdet utils.Matrix, FluxX, FluxY [4]utils.Matrix,
+	DOF, DIV utils.Matrix, RHSQ [4]utils.Matrix) {
+	/*
+		Adds Div(Flux) to the RHS, where the flux is defined at the flux points (FluxX, FluxY) in the interior and the
+		normal flux on the edges is taken from the edge storage of valType
+	*/
 	var (
-		dfr          = sd.dfr
-		Kmax         = sd.PMap.GetBucketDimension(myThread)
-		NpInt        = dfr.FluxElement.NpInt
-		KmaxGlobal   = sd.PMap.MaxIndex
-		DOF          = sd.DissDOF[myThread]
-		DIV          = sd.DissDiv[myThread]
-		DissX, DissY = sd.DissX[myThread], sd.DissY[myThread]
+		dfr        = c.dfr
+		Kmax       = c.Partitions.GetBucketDimension(myThread)
+		NpInt      = dfr.FluxElement.NpInt
+		KmaxGlobal = c.Partitions.MaxIndex
 	)
 	for n := 0; n < 4; n++ {
 		/*
-			Add the DissX and DissY to the RT_DOF using the contravariant transform for the interior
+			Add the FluxX and FluxY to the RT_DOF using the contravariant transform for the interior
 			and IInII for the edges
 		*/
 		var (
-			DiXd, DiYd = DissX[n].DataP, DissY[n].DataP
+			DiXd, DiYd = FluxX[n].DataP, FluxY[n].DataP
 			NpEdge     = dfr.FluxElement.NpEdge
 			DOFd       = DOF.DataP
 		)
@@ -290,7 +297,7 @@ func (sd *ScalarDissipation) AddDissipation(c *Euler, cont ContinuityLevel, myTh
 				JdetD   = Jdet.DataP[k]
 				JinvD   = Jinv.DataP[4*k : 4*(k+1)]
 				IInIId  = dfr.IInII.DataP
-				kGlobal = sd.PMap.GetGlobalK(k, myThread)
+				kGlobal = c.Partitions.GetGlobalK(k, myThread)
 			)
 			for i := 0; i < NpInt; i++ {
 				ind := k + Kmax*i
@@ -304,7 +311,7 @@ func (sd *ScalarDissipation) AddDissipation(c *Euler, cont ContinuityLevel, myTh
 					IInII = IInIId[fInd]
 					shift = NpEdge * edgeNum
 				)
-				edgeFlux, sign := c.EdgeStore.GetEdgeValues(GradientFluxForLaplacian, myThread, k, n, edgeNum, c.dfr)
+				edgeFlux, sign := c.EdgeStore.GetEdgeValues(valType, myThread, k, n, edgeNum, c.dfr)
 				var ii int
 				for i := 0; i < NpEdge; i++ {
 					ind := k + (2*NpInt+i+shift)*Kmax
@@ -317,7 +324,7 @@ func (sd *ScalarDissipation) AddDissipation(c *Euler, cont ContinuityLevel, myTh
 				}
 			}
 		}
-		sd.dfr.FluxElement.DivInt.Mul(DOF, DIV)
+		dfr.FluxElement.DivInt.Mul(DOF, DIV)
 		for k := 0; k < Kmax; k++ {
 			var (
 				oojd = 1. / Jdet.DataP[k]
@@ -1,6 +1,7 @@
 package Euler2D
 
 import (
+	"fmt"
 	"math"
 	"sort"
 
@@ -53,6 +54,7 @@ const (
 	NumericalFluxForEuler ValueType = iota
 	QFluxForGradient
 	GradientFluxForLaplacian
+	ViscousFluxForNavierStokes
 )
 
 func (c *Euler) NewEdgeStorage() (nf *EdgeValueStorage) {
@@ -63,7 +65,7 @@ func (c *Euler) NewEdgeStorage() (nf *EdgeValueStorage) {
 		StorageIndex: make(map[types.EdgeKey]int),
 		PMap:         c.Partitions,
 		Nedge:        c.dfr.FluxElement.NpEdge,
-		Fluxes:       make([][4]utils.Matrix, int(GradientFluxForLaplacian)+1),
+		Fluxes:       make([][4]utils.Matrix, int(ViscousFluxForNavierStokes)+1),
 	}
 	// Allocate memory for fluxes
 	for i := range nf.Fluxes {
@@ -127,11 +129,21 @@ func (c *Euler) GetFaceNormal(kGlobal, edgeNumber int) (normal [2]float64) {
 }
 
 func (c *Euler) StoreGradientEdgeFlux(edgeKeys EdgeKeySlice, EdgeQ1 [][4]float64) {
+	c.StoreNormalEdgeFlux(edgeKeys, EdgeQ1, GradientFluxForLaplacian, c.Dissipation.DissX, c.Dissipation.DissY, nil)
+}
+
+func (c *Euler) StoreNormalEdgeFlux(edgeKeys EdgeKeySlice, EdgeQ1 [][4]float64, valType ValueType,
+	FluxX, FluxY [][4]utils.Matrix, bcFlux func(en types.EdgeKey, normal [2]float64, normalFlux [][4]float64)) {
+	/*
+		Stores the normal component of a flux defined at the flux points of each element (FluxX, FluxY) into the edge
+		storage. Shared edges use the right element's flux, boundary edges use their only element's flux, which can be
+		modified by bcFlux prior to storage.
+	*/
 	var (
-		Nedge                    = c.dfr.FluxElement.NpEdge
-		gradientFluxForLaplacian = EdgeQ1
-		pm                       = c.Partitions
-		Nint                     = c.dfr.FluxElement.NpInt
+		Nedge          = c.dfr.FluxElement.NpEdge
+		normalEdgeFlux = EdgeQ1
+		pm             = c.Partitions
+		Nint           = c.dfr.FluxElement.NpInt
 	)
 	for _, en := range edgeKeys {
 		e := c.dfr.Tris.Edges[en]
@@ -139,14 +151,14 @@ func (c *Euler) StoreGradientEdgeFlux(edgeKeys EdgeKeySlice, EdgeQ1 [][4]float64
 			kLGlobal             = int(e.ConnectedTris[0])
 			kL, KmaxL, myThreadL = pm.GetLocalK(int(e.ConnectedTris[0]))
 			edgeNumberL          = int(e.ConnectedTriEdgeNumber[0])
-			DissXL, DissYL       = c.Dissipation.DissX[myThreadL], c.Dissipation.DissY[myThreadL]
+			FluxXL, FluxYL       = FluxX[myThreadL], FluxY[myThreadL]
 			shiftL               = Nedge * edgeNumberL
 			normalL              = c.GetFaceNormal(kLGlobal, edgeNumberL)
 			nxL, nyL             = normalL[0], normalL[1]
 
 			kR, KmaxR, myThreadR = pm.GetLocalK(int(e.ConnectedTris[1]))
 			edgeNumberR          = int(e.ConnectedTriEdgeNumber[1])
-			DissXR, DissYR       = c.Dissipation.DissX[myThreadR], c.Dissipation.DissY[myThreadR]
+			FluxXR, FluxYR       = FluxX[myThreadR], FluxY[myThreadR]
 			shiftR               = Nedge * edgeNumberR
 			normalR              = c.GetFaceNormal(kLGlobal, edgeNumberL)
 			nxR, nyR             = normalR[0], normalR[1]
@@ -158,20 +170,23 @@ func (c *Euler) StoreGradientEdgeFlux(edgeKeys EdgeKeySlice, EdgeQ1 [][4]float64
 			for n := 0; n < 4; n++ {
 				for i := 0; i < Nedge; i++ {
 					indL := kL + (2*Nint+shiftL+i)*KmaxL // Reversed edge - storage is for primary element
-					gradientFluxForLaplacian[i][n] = nxL*DissXL[n].DataP[indL] + nyL*DissYL[n].DataP[indL]
+					normalEdgeFlux[i][n] = nxL*FluxXL[n].DataP[indL] + nyL*FluxYL[n].DataP[indL]
 				}
 			}
+			if bcFlux != nil {
+				bcFlux(en, normalL, normalEdgeFlux)
+			}
 		case 2: // Handle edges with two connected tris - shared faces
 			for n := 0; n < 4; n++ {
 				for i := 0; i < Nedge; i++ {
 					indR := kR + (2*Nint+shiftR+Nedge-1-i)*KmaxR // Reversed edge - storage is for primary element
 					// Use right side only per Cockburn and Shu's algorithm for Laplacian, where we alternate flux sides
-					gradientFluxForLaplacian[i][n] = nxR*DissXR[n].DataP[indR] + nyR*DissYR[n].DataP[indR]
+					normalEdgeFlux[i][n] = nxR*FluxXR[n].DataP[indR] + nyR*FluxYR[n].DataP[indR]
 				}
 			}
 		}
 		// Load the normal flux into the global normal flux storage
-		c.EdgeStore.PutEdgeValues(en, GradientFluxForLaplacian, gradientFluxForLaplacian)
+		c.EdgeStore.PutEdgeValues(en, valType, normalEdgeFlux)
 	}
 	return
 }
@@ -200,6 +215,9 @@ func (c *Euler) CalculateEdgeFlux(Time float64, CalculateDT bool, Jdet, DT []uti
 				qFluxForGradient[i][n] = Q_Face[myThreadL][n].DataP[indL]
 			}
 		}
+		if c.Viscous != nil && e.NumConnectedTris == 1 {
+			c.Viscous.SetWallGradientState(en, kLGlobal, edgeNumberL, qFluxForGradient) // No slip walls
+		}
 		c.EdgeStore.PutEdgeValues(en, QFluxForGradient, qFluxForGradient)
 
 		for i := range numericalFluxForEuler {
@@ -211,7 +229,7 @@ func (c *Euler) CalculateEdgeFlux(Time float64, CalculateDT bool, Jdet, DT []uti
 		case 0:
 			panic("unable to handle unconnected edges")
 		case 1: // Handle edges with only one triangle - default is edge flux, which will be replaced by a BC flux
-			c.calculateNonSharedEdgeFlux(e, Nedge, Time,
+			c.calculateNonSharedEdgeFlux(en, e, Nedge, Time,
 				kL, KmaxL, edgeNumberL, myThreadL,
 				normalL, numericalFluxForEuler, qFluxForGradient, Q_Face)
 		case 2: // Handle edges with two connected tris - shared faces
@@ -226,7 +244,7 @@ func (c *Euler) CalculateEdgeFlux(Time float64, CalculateDT bool, Jdet, DT []uti
 		c.EdgeStore.PutEdgeValues(en, NumericalFluxForEuler, numericalFluxForEuler)
 
 		if CalculateDT {
-			waveSpeedMax = c.calculateLocalDT(e, Nedge, Q_Face, Jdet, DT)
+			waveSpeedMax = math.Max(waveSpeedMax, c.calculateLocalDT(e, Nedge, Q_Face, Jdet, DT))
 		}
 	}
 	return
@@ -250,6 +268,9 @@ func (c *Euler) calculateLocalDT(e *DG2D.Edge, Nedge int,
 		C := c.FSFar.GetFlowFunction(Q_Face[myThread], ind, SoundSpeed)
 		U := c.FSFar.GetFlowFunction(Q_Face[myThread], ind, Velocity)
 		waveSpeed := fs * (U + C)
+		if c.Viscous != nil { // Viscous time step limit scales with the square of the inverse element size
+			waveSpeed += fs * fs * c.Viscous.GetDiffusivity(Q_Face[myThread], ind)
+		}
 		waveSpeedMax = math.Max(waveSpeed, waveSpeedMax)
 		if waveSpeed > edgeMax {
 			edgeMax = waveSpeed
@@ -272,30 +293,52 @@ func (c *Euler) calculateSharedEdgeFlux(Nedge, kL, KmaxL, edgeNumberL, myThreadL
 	var (
 		shiftL, shiftR      = edgeNumberL * Nedge, edgeNumberR * Nedge
 		interpolateFluxNotQ = true
+		FluxL, FluxR        *[2][4]utils.Matrix
 	)
+	if interpolateFluxNotQ {
+		FluxL, FluxR = &Flux_Face[myThreadL], &Flux_Face[myThreadR]
+	}
+	c.calculateNumericalFlux(kL, kR, KmaxL, KmaxR, shiftL, shiftR, Q_Face[myThreadL], Q_Face[myThreadR], FluxL, FluxR,
+		normalL, numericalFluxForEuler)
+}
+
+func (c *Euler) calculateNumericalFlux(kL, kR, KmaxL, KmaxR, shiftL, shiftR int, QL, QR [4]utils.Matrix,
+	FluxL, FluxR *[2][4]utils.Matrix, normal [2]float64, numericalFlux [][4]float64) {
+	/*
+		Numerical normal flux of the selected flux type between the left and right states of an edge. The Lax and Roe
+		fluxes use the fluxes interpolated to the edge when FluxL and FluxR are given, otherwise they are calculated
+		from the interpolated states
+	*/
+	interpolateFluxNotQ := FluxL != nil && FluxR != nil
 	switch c.FluxCalcAlgo {
 	case FLUX_Average:
-		c.AvgFlux(kL, kR, KmaxL, KmaxR, shiftL, shiftR, Q_Face[myThreadL], Q_Face[myThreadR], normalL, numericalFluxForEuler)
+		c.AvgFlux(kL, kR, KmaxL, KmaxR, shiftL, shiftR, QL, QR, normal, numericalFlux)
 	case FLUX_LaxFriedrichs:
 		if interpolateFluxNotQ {
-			c.LaxFlux2(kL, kR, KmaxL, KmaxR, shiftL, shiftR, Q_Face[myThreadL], Q_Face[myThreadR], Flux_Face[myThreadL],
-				Flux_Face[myThreadR], normalL, numericalFluxForEuler)
+			c.LaxFlux2(kL, kR, KmaxL, KmaxR, shiftL, shiftR, QL, QR, *FluxL, *FluxR, normal, numericalFlux)
 		} else {
-			c.LaxFlux(kL, kR, KmaxL, KmaxR, shiftL, shiftR, Q_Face[myThreadL], Q_Face[myThreadR], normalL, numericalFluxForEuler)
+			c.LaxFlux(kL, kR, KmaxL, KmaxR, shiftL, shiftR, QL, QR, normal, numericalFlux)
 		}
 	case FLUX_Roe:
 		if interpolateFluxNotQ {
-			c.RoeFlux2(kL, kR, KmaxL, KmaxR, shiftL, shiftR, Q_Face[myThreadL], Q_Face[myThreadR], Flux_Face[myThreadL],
-				Flux_Face[myThreadR], normalL, numericalFluxForEuler)
+			c.RoeFlux2(kL, kR, KmaxL, KmaxR, shiftL, shiftR, QL, QR, *FluxL, *FluxR, normal, numericalFlux)
 		} else {
-			c.RoeFlux(kL, kR, KmaxL, KmaxR, shiftL, shiftR, Q_Face[myThreadL], Q_Face[myThreadR], normalL, numericalFluxForEuler)
+			c.RoeFlux(kL, kR, KmaxL, KmaxR, shiftL, shiftR, QL, QR, normal, numericalFlux)
 		}
 	case FLUX_RoeER:
-		c.RoeERFlux(kL, kR, KmaxL, KmaxR, shiftL, shiftR, Q_Face[myThreadL], Q_Face[myThreadR], normalL, numericalFluxForEuler)
+		c.RoeERFlux(kL, kR, KmaxL, KmaxR, shiftL, shiftR, QL, QR, normal, numericalFlux)
+	case FLUX_HLLC:
+		c.HLLCFlux(kL, kR, KmaxL, KmaxR, shiftL, shiftR, QL, QR, normal, numericalFlux)
+	case FLUX_AUSMPlusUp:
+		c.AUSMPlusUpFlux(kL, kR, KmaxL, KmaxR, shiftL, shiftR, QL, QR, normal, numericalFlux)
+	case FLUX_IsmailRoe, FLUX_Chandrashekar:
+		c.EntropyStableFlux(kL, kR, KmaxL, KmaxR, shiftL, shiftR, QL, QR, normal, numericalFlux)
+	default:
+		panic(fmt.Errorf("unable to calculate the numerical flux for flux type %d", c.FluxCalcAlgo))
 	}
 }
 
-func (c *Euler) calculateNonSharedEdgeFlux(e *DG2D.Edge, Nedge int, Time float64,
+func (c *Euler) calculateNonSharedEdgeFlux(en types.EdgeKey, e *DG2D.Edge, Nedge int, Time float64,
 	k, Kmax, edgeNumber, myThread int,
 	normal0 [2]float64, numericalFluxForEuler, qFluxForGradient [][4]float64,
 	Q_Face [][4]utils.Matrix) {
@@ -309,12 +352,25 @@ func (c *Euler) calculateNonSharedEdgeFlux(e *DG2D.Edge, Nedge int, Time float64
 		// Do nothing, calculate normal flux
 	case types.BC_Far:
 		c.FarBC(c.FSFar, k, Kmax, shift, Q_Face[myThread], normal0)
-	case types.BC_In:
-		c.FarBC(c.FSIn, k, Kmax, shift, Q_Face[myThread], normal0)
-	case types.BC_Out:
-		c.FarBC(c.FSOut, k, Kmax, shift, Q_Face[myThread], normal0)
+	case types.BC_In, types.BC_Out:
+		if pbc, ok := c.PressureBCs[en]; ok {
+			c.PressureBoundaryBC(pbc, k, Kmax, shift, Q_Face[myThread], normal0)
+		} else if e.BCType == types.BC_In {
+			c.FarBC(c.FSIn, k, Kmax, shift, Q_Face[myThread], normal0)
+		} else {
+			c.FarBC(c.FSOut, k, Kmax, shift, Q_Face[myThread], normal0)
+		}
 	case types.BC_IVortex:
-		c.IVortexBC(Time, k, Kmax, shift, Q_Face[myThread], normal0)
+		c.ExactStateBC(Time, k, Kmax, myThread, shift, Q_Face[myThread], normal0)
+	case types.BC_Exact:
+		calculateNormalFlux = false
+		c.ExactStateFlux(Time, k, Kmax, myThread, shift, Q_Face[myThread], normal0, numericalFluxForEuler)
+	case types.BC_Slip:
+		c.SlipBC(k, Kmax, shift, Q_Face[myThread], normal0)
+	case types.BC_Dirichlet:
+		c.DirichletBoundaryBC(c.DirichletBCs[en], k, Kmax, shift, Q_Face[myThread])
+	case types.BC_Neuman:
+		// Zero gradient, the flux is calculated from the interior solution extrapolated to the edge
 	case types.BC_Wall, types.BC_Cyl:
 		calculateNormalFlux = false
 		c.WallBC(k, Kmax, Q_Face[myThread], shift, normal0, numericalFluxForEuler) // Calculates normal flux directly
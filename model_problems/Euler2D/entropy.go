@@ -0,0 +1,319 @@
+package Euler2D
+
+import (
+	"math"
+
+	"github.com/notargets/gocfd/utils"
+)
+
+/*
+Entropy stable split form of the DFR scheme, after Fisher and Carpenter (2013) and Gassner, Winters and Kopriva (2016).
+The divergence of the interior flux is replaced with the flux differencing form, sum over j of 2*D_ij*F#(Q_i,Q_j),
+where F# is a symmetric two point entropy conservative flux, Ismail-Roe or Chandrashekar, and j runs over the interior
+and edge points of the RT element. The edges use the same two point flux with added local Lax-Friedrichs dissipation,
+which is entropy stable as the jump in the conserved variables dotted with the jump in the entropy variables is positive
+for a convex entropy. The RT divergence is not a summation by parts operator, so the entropy bound of the split form is
+not exact, it is checked on a non uniform flow in the tests.
+
+The entropy used throughout is the mathematical entropy U = -rho*s/(gamma-1), where s = log(p) - gamma*log(rho) is the
+physical entropy from the Entropy flow function, the entropy flux is U*u and the entropy flux potential is rho*u.
+*/
+
+func (ft FluxType) IsEntropyStable() bool {
+	return ft == FLUX_IsmailRoe || ft == FLUX_Chandrashekar
+}
+
+func logMean(a, b float64) float64 {
+	/*
+		Logarithmic mean (a-b)/(log(a)-log(b)), evaluated with the series of Ismail and Roe (2009) when a and b are close
+	*/
+	var (
+		zeta = a / b
+		f    = (zeta - 1) / (zeta + 1)
+		u    = f * f
+		F    float64
+	)
+	if u < 1.e-2 {
+		F = 1 + u/3 + u*u/5 + u*u*u/7
+	} else {
+		F = math.Log(zeta) / (2 * f)
+	}
+	return 0.5 * (a + b) / F
+}
+
+func (c *Euler) EntropyConservativeFlux(QL, QR [4]float64) (Fx, Fy [4]float64) {
+	switch c.FluxCalcAlgo {
+	case FLUX_Chandrashekar:
+		Fx, Fy = c.chandrashekarFlux(QL, QR)
+	default:
+		Fx, Fy = c.ismailRoeFlux(QL, QR)
+	}
+	return
+}
+
+func (c *Euler) ismailRoeFlux(QL, QR [4]float64) (Fx, Fy [4]float64) {
+	/*
+		Ismail and Roe (2009), using the parameter vector z = sqrt(rho/p)*[1, u, v, p]
+	*/
+	var (
+		Gamma  = c.FSFar.Gamma
+		pL, pR = c.FSFar.GetFlowFunctionQQ(QL, StaticPressure), c.FSFar.GetFlowFunctionQQ(QR, StaticPressure)
+		z1L    = math.Sqrt(QL[0] / pL)
+		z1R    = math.Sqrt(QR[0] / pR)
+		z4L    = math.Sqrt(QL[0] * pL)
+		z4R    = math.Sqrt(QR[0] * pR)
+		z1     = 0.5 * (z1L + z1R)
+		z2     = 0.5 * (z1L*QL[1]/QL[0] + z1R*QR[1]/QR[0])
+		z3     = 0.5 * (z1L*QL[2]/QL[0] + z1R*QR[2]/QR[0])
+		z4     = 0.5 * (z4L + z4R)
+		z1ln   = logMean(z1L, z1R)
+		z4ln   = logMean(z4L, z4R)
+	)
+	rho := z1 * z4ln
+	u, v := z2/z1, z3/z1
+	p1 := z4 / z1
+	p2 := 0.5*(Gamma+1)/Gamma*z4ln/z1ln + 0.5*(Gamma-1)/Gamma*z4/z1
+	h := Gamma*p2/(rho*(Gamma-1)) + 0.5*(u*u+v*v)
+	Fx = [4]float64{rho * u, rho*u*u + p1, rho * u * v, rho * u * h}
+	Fy = [4]float64{rho * v, rho * u * v, rho*v*v + p1, rho * v * h}
+	return
+}
+
+func (c *Euler) chandrashekarFlux(QL, QR [4]float64) (Fx, Fy [4]float64) {
+	/*
+		Chandrashekar (2013), kinetic energy preserving and entropy conservative, using beta = rho/(2p)
+	*/
+	var (
+		GM1            = c.FSFar.Gamma - 1
+		pL, pR         = c.FSFar.GetFlowFunctionQQ(QL, StaticPressure), c.FSFar.GetFlowFunctionQQ(QR, StaticPressure)
+		uL, vL, uR, vR = QL[1] / QL[0], QL[2] / QL[0], QR[1] / QR[0], QR[2] / QR[0]
+		betaL, betaR   = 0.5 * QL[0] / pL, 0.5 * QR[0] / pR
+		rhoLn          = logMean(QL[0], QR[0])
+		betaLn         = logMean(betaL, betaR)
+		u, v           = 0.5 * (uL + uR), 0.5 * (vL + vR)
+		p              = 0.5 * (QL[0] + QR[0]) / (betaL + betaR)
+		U2             = 0.5 * (uL*uL + vL*vL + uR*uR + vR*vR)
+		e              = 0.5/(GM1*betaLn) - 0.5*U2
+	)
+	Fx[0], Fy[0] = rhoLn*u, rhoLn*v
+	Fx[1], Fy[1] = Fx[0]*u+p, Fy[0]*u
+	Fx[2], Fy[2] = Fx[0]*v, Fy[0]*v+p
+	Fx[3] = e*Fx[0] + u*Fx[1] + v*Fx[2]
+	Fy[3] = e*Fy[0] + u*Fy[1] + v*Fy[2]
+	return
+}
+
+func (c *Euler) EntropyStableFlux(kL, kR, KmaxL, KmaxR, shiftL, shiftR int,
+	Q_FaceL, Q_FaceR [4]utils.Matrix, normal [2]float64, normalFlux [][4]float64) {
+	var (
+		Nedge  = c.dfr.FluxElement.NpEdge
+		QL, QR [4]float64
+	)
+	for i := 0; i < Nedge; i++ {
+		iL := i + shiftL
+		iR := Nedge - 1 - i + shiftR // Shared edges run in reverse order relative to each other
+		indL, indR := kL+iL*KmaxL, kR+iR*KmaxR
+		for n := 0; n < 4; n++ {
+			QL[n], QR[n] = Q_FaceL[n].DataP[indL], Q_FaceR[n].DataP[indR]
+		}
+		Fx, Fy := c.EntropyConservativeFlux(QL, QR)
+		maxV := math.Max(
+			c.FSFar.GetFlowFunctionQQ(QL, Velocity)+c.FSFar.GetFlowFunctionQQ(QL, SoundSpeed),
+			c.FSFar.GetFlowFunctionQQ(QR, Velocity)+c.FSFar.GetFlowFunctionQQ(QR, SoundSpeed))
+		for n := 0; n < 4; n++ {
+			normalFlux[i][n] = normal[0]*Fx[n] + normal[1]*Fy[n] + 0.5*maxV*(QL[n]-QR[n])
+		}
+	}
+}
+
+type EntropyFluxScratch struct {
+	Qp, Fx, Fy   [][4]float64 // Solution and flux at the interior points, dimension NpInt
+	QE, FxE, FyE [][4]float64 // Solution interpolated to the edge points and its flux, dimension 3*NpEdge
+}
+
+func NewEntropyFluxScratch(Nint, Nedge int) (es EntropyFluxScratch) {
+	es = EntropyFluxScratch{
+		Qp: make([][4]float64, Nint), Fx: make([][4]float64, Nint), Fy: make([][4]float64, Nint),
+		QE: make([][4]float64, 3*Nedge), FxE: make([][4]float64, 3*Nedge), FyE: make([][4]float64, 3*Nedge),
+	}
+	return
+}
+
+func (c *Euler) AddEntropyConservativeVolumeFlux(myThread, Kmax int, Jdet, Jinv utils.Matrix, Q, RHSQ [4]utils.Matrix,
+	es EntropyFluxScratch) {
+	/*
+		Adds the difference between the flux differencing form and the divergence of the fluxes computed by
+		RHSInternalPoints. The RT divergence at an interior point i uses the fluxes at the interior points and the normal
+		fluxes at the edge points, so the difference is taken over both, with the solution interpolated to the edges:
+			sum over j of D_ij * (2*F#(Q_i,Q_j) - F(Q_i) - F(Q_j))
+		This is the RT divergence of G(x) = 2*F#(Q_i,Q(x)) - F(Q_i) - F(Q(x)), where G and its gradient vanish at point
+		i for a symmetric and consistent F#, so the difference is within the truncation error of the RT divergence. The
+		interior rows of the RT divergence alone do not sum to zero, which is why the edge points are needed. The
+		interior fluxes carry a factor of Jdet that cancels with the inverse Jacobian in the RHS, the edge fluxes are
+		scaled by the edge length ratio IInII as in SetRTFluxOnEdges.
+	*/
+	var (
+		dfr          = c.dfr
+		Nint         = dfr.FluxElement.NpInt
+		Nedge        = dfr.FluxElement.NpEdge
+		NpFlux       = dfr.FluxElement.Np
+		Div          = dfr.FluxElement.DivInt.DataP
+		interpD      = dfr.FluxEdgeInterp.DataP
+		KmaxGlobal   = dfr.K
+		Fx, Fy, Qp   = es.Fx, es.Fy, es.Qp
+		FxE, FyE, QE = es.FxE, es.FyE, es.QE
+	)
+	for k := 0; k < Kmax; k++ {
+		var (
+			JinvD   = Jinv.DataP[4*k : 4*(k+1)]
+			oojd    = 1. / Jdet.DataP[k]
+			kGlobal = c.Partitions.GetGlobalK(k, myThread)
+		)
+		for i := 0; i < Nint; i++ {
+			ind := k + i*Kmax
+			Qp[i] = [4]float64{Q[0].DataP[ind], Q[1].DataP[ind], Q[2].DataP[ind], Q[3].DataP[ind]}
+			Fx[i], Fy[i] = c.CalculateFlux(Q, ind)
+		}
+		for e := 0; e < 3*Nedge; e++ {
+			QE[e] = [4]float64{}
+			for j := 0; j < Nint; j++ {
+				for n := 0; n < 4; n++ {
+					QE[e][n] += interpD[j+e*Nint] * Qp[j][n]
+				}
+			}
+			FxE[e], FyE[e] = c.FluxCalcMock(QE[e][0], QE[e][1], QE[e][2], QE[e][3])
+		}
+		for i := 0; i < Nint; i++ {
+			indI := k + i*Kmax
+			for j := i + 1; j < Nint; j++ {
+				var (
+					FxS, FyS = c.EntropyConservativeFlux(Qp[i], Qp[j])
+					Dri, Dsi = Div[i*NpFlux+j], Div[i*NpFlux+j+Nint]
+					Drj, Dsj = Div[j*NpFlux+i], Div[j*NpFlux+i+Nint]
+					indJ     = k + j*Kmax
+				)
+				for n := 0; n < 4; n++ {
+					dFx := 2*FxS[n] - Fx[i][n] - Fx[j][n]
+					dFy := 2*FyS[n] - Fy[i][n] - Fy[j][n]
+					dFr := JinvD[0]*dFx + JinvD[1]*dFy
+					dFs := JinvD[2]*dFx + JinvD[3]*dFy
+					RHSQ[n].DataP[indI] -= Dri*dFr + Dsi*dFs
+					RHSQ[n].DataP[indJ] -= Drj*dFr + Dsj*dFs
+				}
+			}
+			for edgeNum := 0; edgeNum < 3; edgeNum++ {
+				var (
+					normal = c.GetFaceNormal(kGlobal, edgeNum)
+					IInII  = dfr.IInII.DataP[kGlobal+KmaxGlobal*edgeNum]
+				)
+				for ie := 0; ie < Nedge; ie++ {
+					var (
+						e        = ie + edgeNum*Nedge
+						FxS, FyS = c.EntropyConservativeFlux(Qp[i], QE[e])
+						De       = Div[i*NpFlux+2*Nint+e] * IInII * oojd
+					)
+					for n := 0; n < 4; n++ {
+						dFx := 2*FxS[n] - Fx[i][n] - FxE[e][n]
+						dFy := 2*FyS[n] - Fy[i][n] - FyE[e][n]
+						RHSQ[n].DataP[indI] -= De * (normal[0]*dFx + normal[1]*dFy)
+					}
+				}
+			}
+		}
+	}
+}
+
+func (c *Euler) GetEntropyVariables(Q [4]utils.Matrix, ind int) (v [4]float64) {
+	var (
+		Gamma = c.FSFar.Gamma
+		rho   = Q[0].DataP[ind]
+		p     = c.FSFar.GetFlowFunction(Q, ind, StaticPressure)
+		s     = c.FSFar.GetFlowFunction(Q, ind, Entropy)
+		q     = c.FSFar.GetFlowFunction(Q, ind, DynamicPressure)
+	)
+	v[0] = (Gamma-s)/(Gamma-1) - q/p
+	v[1] = Q[1].DataP[ind] / p
+	v[2] = Q[2].DataP[ind] / p
+	v[3] = -rho / p
+	return
+}
+
+func (c *Euler) solutionPointWeights() (w []float64) {
+	// Integration weights of the solution points on the unit triangle, from the row sums of the mass matrix
+	var (
+		Np = c.dfr.SolutionElement.Np
+		MD = c.dfr.SolutionElement.MassMatrix.DataP
+	)
+	w = make([]float64, Np)
+	for i := 0; i < Np; i++ {
+		for j := 0; j < Np; j++ {
+			w[i] += MD[j+i*Np]
+		}
+	}
+	return
+}
+
+func (c *Euler) TotalEntropy(Q [][4]utils.Matrix) (S float64) {
+	/*
+		Integral of the mathematical entropy -rho*s/(gamma-1) over the domain
+	*/
+	var (
+		w    = c.solutionPointWeights()
+		GM1  = c.FSFar.Gamma - 1
+		Jdet = c.ShardByKTranspose(c.dfr.Jdet)
+	)
+	for np := 0; np < c.Partitions.ParallelDegree; np++ {
+		Kmax := c.Partitions.GetBucketDimension(np)
+		for k := 0; k < Kmax; k++ {
+			for i := range w {
+				ind := k + i*Kmax
+				s := c.FSFar.GetFlowFunction(Q[np], ind, Entropy)
+				S -= w[i] * Jdet[np].DataP[k] * Q[np][0].DataP[ind] * s / GM1
+			}
+		}
+	}
+	return
+}
+
+func (c *Euler) EntropyProductionRate(Q, RHSQ [][4]utils.Matrix) (dSdt float64) {
+	/*
+		Semi discrete rate of change of the total entropy, the integral of the entropy variables dotted with dQ/dt
+	*/
+	var (
+		w    = c.solutionPointWeights()
+		Jdet = c.ShardByKTranspose(c.dfr.Jdet)
+	)
+	for np := 0; np < c.Partitions.ParallelDegree; np++ {
+		Kmax := c.Partitions.GetBucketDimension(np)
+		for k := 0; k < Kmax; k++ {
+			for i := range w {
+				ind := k + i*Kmax
+				v := c.GetEntropyVariables(Q[np], ind)
+				for n := 0; n < 4; n++ {
+					dSdt += w[i] * Jdet[np].DataP[k] * v[n] * RHSQ[np][n].DataP[ind]
+				}
+			}
+		}
+	}
+	return
+}
+
+/*
+The entropy diagnostic tracks the total entropy after each step, the discrete entropy production of a step is the change
+in the total entropy, which is not positive for an entropy stable scheme away from boundary inflow of entropy
+*/
+type EntropyDiagnostic struct {
+	Initial, Total float64
+	Production     float64 // Change in total entropy over the last step
+}
+
+func (c *Euler) NewEntropyDiagnostic() (ed *EntropyDiagnostic) {
+	S := c.TotalEntropy(c.Q)
+	ed = &EntropyDiagnostic{Initial: S, Total: S}
+	return
+}
+
+func (ed *EntropyDiagnostic) Update(c *Euler) {
+	S := c.TotalEntropy(c.Q)
+	ed.Production, ed.Total = S-ed.Total, S
+}
@@ -3,11 +3,12 @@ package Euler2D
 import (
 	"fmt"
 	"math"
-	"sync"
 	"syscall"
 	"time"
 
+	"github.com/notargets/gocfd/model_problems/Euler2D/ringleb"
 	"github.com/notargets/gocfd/model_problems/Euler2D/sod_shock_tube"
+	"github.com/notargets/gocfd/model_problems/Euler2D/supersonic_vortex"
 
 	"github.com/notargets/gocfd/types"
 
@@ -33,74 +34,184 @@ type Euler struct {
 	chart              ChartState
 	profile            bool // Generate a CPU profile of the solver
 	FluxCalcAlgo       FluxType
+	TimeIntegrator     TimeIntegratorType
 	Case               InitType
 	AnalyticSolution   ExactState
+	exactFace          [][4]utils.Matrix                                    // Sharded exact state on one boundary edge, Nedge x 1
 	FluxCalcMock       func(rho, rhoU, rhoV, E float64) (Fx, Fy [4]float64) // For testing
 	SortedEdgeKeys     []EdgeKeySlice                                       // Buckets, one for each parallel partition
 	Partitions         *PartitionMap                                        // mapping of elements into bins for parallelism
+	Partitioner        PartitionerType                                      // Grouping of the elements into the bins
 	LocalTimeStepping  bool
+	ImplicitSolver     bool // Steady state solution using implicit pseudo time stepping
 	MaxIterations      int
 	// Below are partitioned by K (elements) in the first slice
 	Q                    [][4]utils.Matrix // Sharded solution variables, stored at solution point locations, Np_solution x K
+	SourceTerm           [][4]utils.Matrix // Steady source at the solution points, nil if there is none
 	SolutionX, SolutionY []utils.Matrix
 	ShockFinder          *ModeAliasShockFinder
 	Limiter              *SolutionLimiter
 	Dissipation          *ScalarDissipation
+	Viscous              *NavierStokes                 // Viscous terms of the Navier-Stokes equations, nil for inviscid flow
+	PressureBCs          map[types.EdgeKey]*PressureBC // Inflow and outflow edges with parameters from the input
+	DirichletBCs         map[types.EdgeKey]*DirichletBC
 	// Edge number mapped quantities, i.e. Face Normal Flux
 	EdgeStore *EdgeValueStorage
 	ShockTube *sod_shock_tube.SODShockTube
+	// Checkpoint and restart
+	InputParams     *InputParameters // Retained for storage in checkpoints
+	RestartFile     string           // If set, the solution is initialized from this checkpoint file
+	CheckpointFile  string           // Name of the checkpoint file written every CheckpointEvery steps
+	CheckpointEvery int              // Number of steps between checkpoints, 0 disables checkpointing
+	// VTK output
+	VTKPrefix string         // If set, VTU files and a PVD collection are written using this prefix
+	VTKEvery  int            // Number of steps between VTU files, 0 writes only the final solution
+	VTKFields []FlowFunction // Flow functions written in addition to the conserved variables
+	// Aerodynamic forces on wall boundaries
+	Forces           *AeroForces
+	ForceHistoryFile string // Force coefficient history is written here if there are walls
+	SurfaceFile      string // If set, surface Cp and Mach along each wall are written at the end using this prefix
+	// Convergence monitoring
+	Convergence         *ConvergenceMonitor // If not nil, the solution stops when the residual target is reached
+	ResidualHistoryFile string
+	Result              ResidualRecord     // Iteration, time step, residuals and solver time at the end of Solve
+	Converged           bool               // The convergence criteria stopped the last Solve
+	Entropy             *EntropyDiagnostic // Total entropy and its production, used with the entropy stable fluxes
 }
 
 func NewEuler(ip *InputParameters, meshFile string, ProcLimit int, plotMesh, verbose, profile bool) (c *Euler) {
+	c = newEuler(ip, meshFile, profile)
+	if len(meshFile) == 0 {
+		return
+	}
+
+	// Read mesh file, initialize geometry and finite elements
+	var err error
+	if c.dfr, err = DG2D.NewDFR2DWithOrdering(ip.PolynomialOrder, DG2D.NewMeshOrdering(ip.MeshOrdering), plotMesh,
+		verbose, meshFile); err != nil {
+		panic(fmt.Errorf("unable to use mesh file %s: %s", meshFile, err.Error()))
+	}
+
+	c.SetParallelDegree(ProcLimit, c.dfr.K) // Must occur after determining the number of elements
+	c.PartitionElements(c.Partitioner)      // Renumbers the elements, must occur before any element data is allocated
+	c.initialize(ip, verbose)
+	return
+}
+
+func NewEulerSharingGeometry(ip *InputParameters, prev *Euler, verbose, profile bool) (c *Euler) {
+	/*
+		Uses the mesh, finite elements and partitions of a previous solver, for a new solution with different flow
+		conditions. The mesh ordering and partitioner of prev are kept, the polynomial order must be the same.
+	*/
+	if ip.PolynomialOrder != prev.dfr.N {
+		panic(fmt.Errorf("unable to share geometry of polynomial order %d for polynomial order %d",
+			prev.dfr.N, ip.PolynomialOrder))
+	}
+	c = newEuler(ip, prev.MeshFile, profile)
+	c.dfr, c.Partitions, c.Partitioner = prev.dfr, prev.Partitions, prev.Partitioner
+	c.initialize(ip, verbose)
+	return
+}
+
+func newEuler(ip *InputParameters, meshFile string, profile bool) (c *Euler) {
 	c = &Euler{
 		MeshFile:          meshFile,
 		CFL:               ip.CFL,
 		FinalTime:         ip.FinalTime,
 		FluxCalcAlgo:      NewFluxType(ip.FluxType),
+		TimeIntegrator:    NewTimeIntegratorType(ip.TimeIntegrator),
+		Partitioner:       NewPartitionerType(ip.Partitioner),
 		Case:              NewInitType(ip.InitType),
 		LocalTimeStepping: ip.LocalTimeStepping,
 		MaxIterations:     ip.MaxIterations,
 		FSFar:             NewFreeStream(ip.Minf, ip.Gamma, ip.Alpha),
 		profile:           profile,
+		InputParams:       ip,
+		Convergence:       NewConvergenceMonitor(ip),
 	}
 	c.FluxCalcMock = c.FluxCalcBase
+	c.ResidualHistoryFile = ip.ResidualHistoryFile
+	return
+}
 
-	if len(meshFile) == 0 {
-		return
-	}
-
-	// Read mesh file, initialize geometry and finite elements
-	c.dfr = DG2D.NewDFR2D(ip.PolynomialOrder, plotMesh, verbose, meshFile)
-
-	c.SetParallelDegree(ProcLimit, c.dfr.K) // Must occur after determining the number of elements
-	c.PartitionEdgesByK()                   // Setup the key for edge calculations, useful for parallelizing the process
+func (c *Euler) initialize(ip *InputParameters, verbose bool) {
+	// Allocates the solution, boundary conditions and numerics on the elements and partitions of c.dfr
+	c.PartitionEdgesByK() // Setup the key for edge calculations, useful for parallelizing the process
 
 	// Allocate Normal flux storage and indices
 	c.EdgeStore = c.NewEdgeStorage()
 
+	c.PressureBCs = c.NewPressureBCs(ip)
+	c.DirichletBCs = c.NewDirichletBCs(ip)
+
 	c.InitializeSolution(verbose)
 
+	// Working storage for the exact state on the boundary edges of each partition
+	if c.AnalyticSolution != nil {
+		c.exactFace = make([][4]utils.Matrix, c.Partitions.ParallelDegree)
+		for np := range c.exactFace {
+			for n := 0; n < 4; n++ {
+				c.exactFace[np][n] = utils.NewMatrix(c.dfr.FluxElement.NpEdge, 1)
+			}
+		}
+	}
+
 	// Allocate a solution limiter
-	lt := NewLimiterType(ip.Limiter)
+	lt, positivity, dissipation := NewLimiterTypes(ip.Limiter)
 	c.Limiter = NewSolutionLimiter(lt, ip.Kappa, c.dfr, c.Partitions, c.FSFar)
+	if positivity {
+		c.Limiter.Positivity = NewPositivityLimiter(c.dfr, c.Partitions, c.FSFar)
+	}
 
 	// Initiate Artificial Dissipation
-	if lt == None {
+	if dissipation {
 		c.Dissipation = NewScalarDissipation(ip.Kappa, c.dfr, c.Partitions)
 	}
 
+	// Add the viscous terms of the Navier-Stokes equations
+	if ip.Reynolds != 0 {
+		c.Viscous = NewNavierStokes(ip, c.FSFar, c.dfr, c.Partitions)
+	}
+
 	if verbose {
-		fmt.Printf("Euler Equations in 2 Dimensions\n")
+		if c.Viscous != nil {
+			fmt.Printf("Navier-Stokes Equations in 2 Dimensions\n")
+		} else {
+			fmt.Printf("Euler Equations in 2 Dimensions\n")
+		}
 		fmt.Printf("Using %d go routines in parallel\n", c.Partitions.ParallelDegree)
+		edgeCut, interiorEdges, imbalance := c.PartitionStatistics()
+		fmt.Printf("Partitioner: [%s], Edge Cut = %d of %d interior edges, Load Imbalance = %5.3f\n",
+			c.Partitioner.Print(), edgeCut, interiorEdges, imbalance)
 		fmt.Printf("Solving %s\n", c.Case.Print())
 		switch c.Case {
 		case FREESTREAM:
 			fmt.Printf("Mach Infinity = %8.5f, Angle of Attack = %8.5f\n", ip.Minf, ip.Alpha)
 		}
-		fmt.Printf("Flux Algorithm: [%s] using Limiter: [%s]\n", c.FluxCalcAlgo.Print(), c.Limiter.limiterType.Print())
+		fmt.Printf("Flux Algorithm: [%s] using Limiter: [%s]\n", c.FluxCalcAlgo.Print(), c.Limiter.Print())
+		if !ip.ImplicitSolver {
+			fmt.Printf("Time Integrator: [%s]\n", c.TimeIntegrator.Print())
+		}
 		if c.Dissipation != nil {
 			fmt.Printf("Artificial Dissipation: Kappa = [%5.3f]\n", c.Dissipation.Kappa)
 		}
+		if c.Viscous != nil {
+			fmt.Printf("%s\n", c.Viscous.Print())
+		}
+		printed := make(map[*PressureBC]bool)
+		for _, pbc := range c.PressureBCs {
+			if !printed[pbc] {
+				fmt.Printf("%s\n", pbc.Print())
+				printed[pbc] = true
+			}
+		}
+		printedD := make(map[*DirichletBC]bool)
+		for _, dbc := range c.DirichletBCs {
+			if !printedD[dbc] {
+				fmt.Printf("%s\n", dbc.Print())
+				printedD[dbc] = true
+			}
+		}
 		fmt.Printf("CFL = %8.4f, Polynomial Degree N = %d (1 is linear), Num Elements K = %d\n\n\n",
 			ip.CFL, ip.PolynomialOrder, c.dfr.K)
 	}
@@ -111,44 +222,174 @@ func (c *Euler) Solve(pm *PlotMeta) {
 	var (
 		FinalTime = c.FinalTime
 		steps     int
+		stepsInit int // Nonzero when restarting from a checkpoint
 		finished  bool
+		converged bool
 		plotQ     = pm.Plot
 	)
 	if c.profile {
 		defer profile.Start().Stop()
 	}
 
-	c.PrintInitialization(FinalTime)
+	if c.InputParams != nil {
+		c.Forces = c.NewAeroForces(c.InputParams.MomentCenter, c.InputParams.ReferenceLength, c.ForceHistoryFile)
+	}
+	if c.Forces != nil {
+		defer c.Forces.Close()
+	}
 
 	rk := c.NewRungeKuttaSSP()
+	defer rk.Close()
+	var is *ImplicitSolver
+	if c.ImplicitSolver {
+		is = c.NewImplicitSolver(rk, c.InputParams)
+		fmt.Printf("%s\n", is.Print())
+	}
+	if c.Convergence != nil {
+		fmt.Printf("Converging until %s\n", c.Convergence.Print())
+	}
+	if c.FluxCalcAlgo.IsEntropyStable() {
+		c.Entropy = c.NewEntropyDiagnostic()
+	}
+	c.PrintInitialization(FinalTime)
+
+	if len(c.RestartFile) != 0 {
+		cp, err := ReadCheckpoint(c.RestartFile)
+		if err == nil {
+			err = c.RestoreCheckpoint(cp, rk)
+		}
+		if err != nil {
+			panic(err)
+		}
+		steps, stepsInit = rk.StepCount, rk.StepCount
+		if c.LocalTimeStepping {
+			fmt.Printf("Restarting from checkpoint [%s] at iteration %d\n", c.RestartFile, rk.StepCount)
+		} else {
+			fmt.Printf("Restarting from checkpoint [%s] at iteration %d, time = %8.5f\n",
+				c.RestartFile, rk.StepCount, rk.Time)
+		}
+	}
+
+	var rh *ResidualHistory
+	if len(c.ResidualHistoryFile) != 0 {
+		var err error
+		if rh, err = NewResidualHistory(c.ResidualHistoryFile); err != nil {
+			panic(err)
+		}
+		defer func() {
+			if err := rh.Close(); err != nil {
+				fmt.Printf("unable to write residual history: %s\n", err.Error())
+			}
+		}()
+	}
+
+	var vo *VTKOutput
+	if len(c.VTKPrefix) != 0 {
+		vo = c.NewVTKOutput(c.VTKPrefix, c.VTKFields)
+	}
+
+	if pm.Render != nil {
+		defer func() {
+			if err := pm.Render.Close(); err != nil {
+				fmt.Printf("unable to write animation: %s\n", err.Error())
+			}
+		}()
+	}
 
 	elapsed := time.Duration(0)
 	var start time.Time
 	for !finished {
 		start = time.Now()
-		rk.Step(c)
+		if is != nil {
+			is.Step(c)
+		} else {
+			rk.Step(c)
+		}
 		elapsed += time.Now().Sub(start)
 		steps++
-		rk.Time += rk.GlobalDT
+		/*
+			With local time stepping, explicit or implicit, every element takes its own (pseudo) time step and there is
+			no global time. Time is held at its initial value and the progress is the iteration count in StepCount
+		*/
+		if !c.LocalTimeStepping {
+			rk.Time += rk.GlobalDT
+		}
 		rk.StepCount++
+		if c.Entropy != nil {
+			c.Entropy.Update(c)
+		}
+		if c.Limiter != nil && c.Limiter.Positivity != nil {
+			c.Limiter.Positivity.UpdateCount()
+		}
 		finished = c.CheckIfFinished(rk.Time, FinalTime, steps)
+		if c.Convergence != nil || rh != nil {
+			rec := c.NewResidualRecord(rk.Time, rk.GlobalDT, steps, rk.ResidualNorms, rk.LimitedPoints, elapsed)
+			if rh != nil {
+				if err := rh.Add(rec); err != nil {
+					fmt.Printf("unable to write residual history: %s\n", err.Error())
+				}
+			}
+			if c.Convergence != nil && c.Convergence.Converged(rec.Residual) && !finished {
+				finished = true
+				converged = true
+			}
+		}
 		if finished || steps%pm.StepsBeforePlot == 0 || steps == 1 {
 			var printMem bool
 			if steps%100 == 0 {
 				printMem = true
 			}
-			c.PrintUpdate(rk.Time, rk.GlobalDT, steps, c.Q, rk.Residual, plotQ, pm, printMem,
+			c.PrintUpdate(rk.Time, rk.GlobalDT, steps, c.Q, rk.ResidualNorms, plotQ, pm, printMem,
 				rk.LimitedPoints)
+			if pm.Render != nil {
+				if err := c.WriteFrame(pm, pm.Render, steps); err != nil {
+					fmt.Printf("unable to write image: %s\n", err.Error())
+				}
+			}
+			if pm.Monitor != nil {
+				c.UpdateMonitor(pm.Monitor, pm, c.NewResidualRecord(rk.Time, rk.GlobalDT, steps, rk.ResidualNorms,
+					rk.LimitedPoints, elapsed), finished)
+			}
+		}
+		if c.CheckpointEvery != 0 && (finished || steps%c.CheckpointEvery == 0) {
+			if err := c.WriteCheckpoint(c.CheckpointFile, rk); err != nil {
+				fmt.Printf("unable to write checkpoint: %s\n", err.Error())
+			}
+		}
+		if vo != nil && (finished || (c.VTKEvery != 0 && steps%c.VTKEvery == 0)) {
+			if err := c.WriteVTKStep(vo, rk.Time, steps); err != nil {
+				fmt.Printf("unable to write VTK output: %s\n", err.Error())
+			}
+		}
+	}
+	c.Result = c.NewResidualRecord(rk.Time, rk.GlobalDT, steps, rk.ResidualNorms, rk.LimitedPoints, elapsed)
+	c.Converged = converged
+	if converged {
+		fmt.Printf("\nConverged at iteration %d: %s\n", steps, c.Convergence.Print())
+	}
+	if c.AnalyticSolution != nil {
+		// With local time stepping the solution is a steady state, compared with the exact solution at the initial time
+		se := c.GetSolutionError(c.AnalyticSolution, rk.Time)
+		if c.LocalTimeStepping {
+			fmt.Printf("\nError of the %s at iteration %d\n%s", c.Case.Print(), steps, se.Print())
+		} else {
+			fmt.Printf("\nError of the %s at time %8.5f\n%s", c.Case.Print(), rk.Time, se.Print())
 		}
 	}
-	c.PrintFinal(elapsed, steps)
+	if len(c.SurfaceFile) != 0 {
+		if err := c.WriteSurfaceDistribution(c.SurfaceFile); err != nil {
+			fmt.Printf("unable to write surface distribution: %s\n", err.Error())
+		}
+	}
+	c.PrintFinal(elapsed, steps-stepsInit)
 }
 
 type RungeKutta4SSP struct {
 	Jdet, Jinv           []utils.Matrix       // Sharded mesh Jacobian and inverse transform
 	RHSQ, Q_Face         [][4]utils.Matrix    // State used for matrix multiplies within the time step algorithm
 	Flux_Face            [][2][4]utils.Matrix // Flux interpolated to edges from interior
-	Q1, Q2, Q3, Q4       [][4]utils.Matrix    // Intermediate solution state
+	Integrator           TimeIntegrator
+	Registers            [][][4]utils.Matrix  // Intermediate solution states, the solution itself is register 0
 	Flux                 [][2][4]utils.Matrix // Flux at solution points, used for interpolation to edges
 	Residual             [][4]utils.Matrix    // Used for reporting, aliased to Q1
 	F_RT_DOF             [][4]utils.Matrix    // Normal flux used for divergence
@@ -156,10 +397,15 @@ type RungeKutta4SSP struct {
 	MaxWaveSpeed         []float64            // Shard max wavespeed
 	GlobalDT, Time       float64
 	StepCount            int
-	Kmax                 []int          // Local element count (dimension: Kmax[ParallelDegree])
-	NpInt, Nedge, NpFlux int            // Number of points in solution, edge and flux total
-	EdgeQ1, EdgeQ2       [][][4]float64 // Sharded local working memory, dimensions Nedge
-	LimitedPoints        []int          // Sharded number of limited points
+	Kmax                 []int                // Local element count (dimension: Kmax[ParallelDegree])
+	NpInt, Nedge, NpFlux int                  // Number of points in solution, edge and flux total
+	EdgeQ1, EdgeQ2       [][][4]float64       // Sharded local working memory, dimensions Nedge
+	LimitedPoints        []int                // Sharded number of limited points
+	ResidualMax          [][4]float64         // Sharded max residual of each variable
+	ResidualNorms        [6]float64           // Residual norms of the last step, reduced from ResidualMax
+	Pool                 *WorkerPool          // Persistent workers, one for each shard
+	StepDT               []float64            // Sharded global time step, the same on all shards after the reduction
+	EntropyScratch       []EntropyFluxScratch // Sharded working memory for the entropy conservative volume flux
 }
 
 func (c *Euler) NewRungeKuttaSSP() (rk *RungeKutta4SSP) {
@@ -168,36 +414,40 @@ func (c *Euler) NewRungeKuttaSSP() (rk *RungeKutta4SSP) {
 		NPar = pm.ParallelDegree
 	)
 	rk = &RungeKutta4SSP{
-		Jdet:          c.ShardByKTranspose(c.dfr.Jdet),
-		Jinv:          c.ShardByKTranspose(c.dfr.Jinv),
-		RHSQ:          make([][4]utils.Matrix, NPar),
-		Q_Face:        make([][4]utils.Matrix, NPar),
-		Flux_Face:     make([][2][4]utils.Matrix, NPar),
-		Q1:            make([][4]utils.Matrix, NPar),
-		Q2:            make([][4]utils.Matrix, NPar),
-		Q3:            make([][4]utils.Matrix, NPar),
-		Q4:            make([][4]utils.Matrix, NPar),
-		Flux:          make([][2][4]utils.Matrix, NPar),
-		Residual:      make([][4]utils.Matrix, NPar),
-		F_RT_DOF:      make([][4]utils.Matrix, NPar),
-		DT:            make([]utils.Matrix, NPar),
-		MaxWaveSpeed:  make([]float64, NPar),
-		Kmax:          make([]int, NPar),
-		NpInt:         c.dfr.SolutionElement.Np,
-		Nedge:         c.dfr.FluxElement.NpEdge,
-		NpFlux:        c.dfr.FluxElement.Np,
-		EdgeQ1:        make([][][4]float64, NPar),
-		EdgeQ2:        make([][][4]float64, NPar),
-		LimitedPoints: make([]int, NPar),
+		Jdet:           c.ShardByKTranspose(c.dfr.Jdet),
+		Jinv:           c.ShardByKTranspose(c.dfr.Jinv),
+		RHSQ:           make([][4]utils.Matrix, NPar),
+		Q_Face:         make([][4]utils.Matrix, NPar),
+		Flux_Face:      make([][2][4]utils.Matrix, NPar),
+		Integrator:     NewTimeIntegrator(c.TimeIntegrator),
+		Flux:           make([][2][4]utils.Matrix, NPar),
+		Residual:       make([][4]utils.Matrix, NPar),
+		F_RT_DOF:       make([][4]utils.Matrix, NPar),
+		DT:             make([]utils.Matrix, NPar),
+		MaxWaveSpeed:   make([]float64, NPar),
+		Kmax:           make([]int, NPar),
+		NpInt:          c.dfr.SolutionElement.Np,
+		Nedge:          c.dfr.FluxElement.NpEdge,
+		NpFlux:         c.dfr.FluxElement.Np,
+		EdgeQ1:         make([][][4]float64, NPar),
+		EdgeQ2:         make([][][4]float64, NPar),
+		LimitedPoints:  make([]int, NPar),
+		ResidualMax:    make([][4]float64, NPar),
+		Pool:           NewWorkerPool(NPar),
+		StepDT:         make([]float64, NPar),
+		EntropyScratch: make([]EntropyFluxScratch, NPar),
+	}
+	rk.Registers = make([][][4]utils.Matrix, rk.Integrator.NumRegisters())
+	for j := range rk.Registers {
+		rk.Registers[j] = make([][4]utils.Matrix, NPar)
 	}
 	// Initialize memory for RHS
 	for np := 0; np < NPar; np++ {
 		rk.Kmax[np] = pm.GetBucketDimension(np)
 		for n := 0; n < 4; n++ {
-			rk.Q1[np][n] = utils.NewMatrix(rk.NpInt, rk.Kmax[np])
-			rk.Q2[np][n] = utils.NewMatrix(rk.NpInt, rk.Kmax[np])
-			rk.Q3[np][n] = utils.NewMatrix(rk.NpInt, rk.Kmax[np])
-			rk.Q4[np][n] = utils.NewMatrix(rk.NpInt, rk.Kmax[np])
+			for j := range rk.Registers {
+				rk.Registers[j][np][n] = utils.NewMatrix(rk.NpInt, rk.Kmax[np])
+			}
 			rk.Flux[np][0][n] = utils.NewMatrix(rk.NpInt, rk.Kmax[np])
 			rk.Flux[np][1][n] = utils.NewMatrix(rk.NpInt, rk.Kmax[np])
 			rk.RHSQ[np][n] = utils.NewMatrix(rk.NpInt, rk.Kmax[np])
@@ -210,142 +460,163 @@ func (c *Euler) NewRungeKuttaSSP() (rk *RungeKutta4SSP) {
 		rk.DT[np] = utils.NewMatrix(rk.NpInt, rk.Kmax[np])
 		rk.EdgeQ1[np] = make([][4]float64, rk.Nedge)
 		rk.EdgeQ2[np] = make([][4]float64, rk.Nedge)
+		rk.EntropyScratch[np] = NewEntropyFluxScratch(rk.NpInt, rk.Nedge)
 	}
 	return
 }
 
 func (rk *RungeKutta4SSP) Step(c *Euler) {
 	/*
-		This is the controller thread - it manages and synchronizes the workers
-		NOTE: Any CPU work done in this thread makes the workers wait - be careful!
+		This is the controller thread - it runs the first shard and waits for the workers to finish the step. A spawning
+		pool starts the shards once for every substep, as the solver did before the persistent workers
 	*/
-	var (
-		pm = c.Partitions
-		NP = pm.ParallelDegree
-		wg = sync.WaitGroup{}
-	)
-	for currentStep := 0; currentStep < 26; currentStep++ {
-		//rkStep := getRKStepNumber(currentStep)
-		//initDT                     := (rkStep == 0) // Calculate time step on first RK stage only
-		initDT := true // Calculate time step at each stage
-		// Workers are blocked below here until the StepWorker section - make sure significant work done here is abs necessary!
-		if initDT && !c.LocalTimeStepping {
-			rk.calculateGlobalDT(c) // Compute the global DT for non local timestepping - must be done serially
-		}
-		// Advance the workers one step
-		for np := 0; np < NP; np++ {
-			wg.Add(1)
-			go rk.StepWorker(c, np, &wg, currentStep, initDT)
+	nSteps := 1 + 5*rk.Integrator.NumStages()
+	if rk.Pool.Spawn {
+		for currentStep := 0; currentStep < nSteps; currentStep++ {
+			rk.Pool.Run(func(myThread int) { rk.StepWorker(c, myThread, currentStep, currentStep+1) })
+			rk.GlobalDT = rk.StepDT[0]
 		}
-		wg.Wait()
+	} else {
+		rk.Pool.Run(func(myThread int) { rk.StepWorker(c, myThread, 0, nSteps) })
+		rk.GlobalDT = rk.StepDT[0]
 	}
+	rk.ResidualNorms = GetResidualNormsFromMax(rk.ResidualMax)
+}
+
+func (rk *RungeKutta4SSP) Close() {
+	rk.Pool.Close()
 }
 
-func (rk *RungeKutta4SSP) StepWorker(c *Euler, myThread int, wg *sync.WaitGroup, currentStep int, initDT bool) {
+func (rk *RungeKutta4SSP) GetRegister(c *Euler, j int) (Qall [][4]utils.Matrix) {
+	// Register 0 is the solution, sharded by thread
+	if j == 0 {
+		return c.Q
+	}
+	return rk.Registers[j-1]
+}
+
+func (rk *RungeKutta4SSP) StepWorker(c *Euler, myThread, beginStep, endStep int) {
+	/*
+		Runs substeps [beginStep, endStep) of a time step for one shard. The substeps are separated by the pool barrier,
+		as each one uses results from the other shards computed in the previous substep
+	*/
 	var (
 		Np                         = rk.NpInt
-		dT                         float64
+		dT                         = rk.GlobalDT
 		contLevel                  = C0
 		Kmax, Jdet, Jinv, F_RT_DOF = rk.Kmax[myThread], rk.Jdet[myThread], rk.Jinv[myThread], rk.F_RT_DOF[myThread]
 		DT, Q_Face, Flux_Face      = rk.DT[myThread], rk.Q_Face[myThread], rk.Flux_Face[myThread]
-		Q0                         = c.Q[myThread]
-		Q1, Q2, Q3, Q4             = rk.Q1[myThread], rk.Q2[myThread], rk.Q3[myThread], rk.Q4[myThread]
 		Flux                       = rk.Flux[myThread]
 		RHSQ, Residual             = rk.RHSQ[myThread], rk.Residual[myThread]
 		EdgeQ1, EdgeQ2             = rk.EdgeQ1[myThread], rk.EdgeQ2[myThread]
 		SortedEdgeKeys             = c.SortedEdgeKeys[myThread]
 		DTStartup                  = 1. - math.Pow(math.Exp(-float64(rk.StepCount+1)), 1./64)
-		rkStep                     = getRKStepNumber(currentStep)
-		QQQ                        = [][4]utils.Matrix{Q0, Q1, Q2, Q3, Q4}[rkStep]
-		QQQAll                     = [][][4]utils.Matrix{c.Q, rk.Q1, rk.Q2, rk.Q3, rk.Q4}[rkStep]
+		integrator                 = rk.Integrator
+		barrier                    = rk.Pool.Barrier
+		U                          = make([][]float64, 1+integrator.NumRegisters())
 	)
-	defer wg.Done()
 	/*
 		Inline functions
 	*/
 	rkAdvance := func(rkstep int, QQQ [4]utils.Matrix) {
-		/*
-			SSP54 RK Coefficients from:
-			"A numerical study of diagonally split Runge-Kutta methods for PDEs with discontinuities"
-			Colin B. Macdonald, Sigal Gottlieb and Steven J. Ruuth, 2007
-		*/
 		c.SetRTFluxInternal(Kmax, Jdet, Jinv, F_RT_DOF, QQQ) // Updates F_RT_DOF with values from Q
 		c.SetRTFluxOnEdges(myThread, Kmax, F_RT_DOF)
-		c.RHSInternalPoints(Kmax, Jdet, F_RT_DOF, RHSQ)
+		c.RHSInternalPoints(myThread, Kmax, Jdet, F_RT_DOF, RHSQ)
+		if c.FluxCalcAlgo.IsEntropyStable() {
+			c.AddEntropyConservativeVolumeFlux(myThread, Kmax, Jdet, Jinv, QQQ, RHSQ, rk.EntropyScratch[myThread])
+		}
 		if c.Dissipation != nil {
 			c.Dissipation.AddDissipation(c, contLevel, myThread, Jinv, Jdet, QQQ, RHSQ)
 		}
-		dT = rk.GlobalDT
+		if c.Viscous != nil {
+			c.Viscous.AddViscousFlux(c, myThread, Jinv, Jdet, RHSQ)
+		}
 		for n := 0; n < 4; n++ {
-			var (
-				U0, U1, U2, U3, U4 = Q0[n].DataP, Q1[n].DataP, Q2[n].DataP, Q3[n].DataP, Q4[n].DataP
-				R, RHS             = Residual[n].DataP, RHSQ[n].DataP
-			)
+			// Scale the RHS by the time step in place, then advance this variable's registers
+			RHS := RHSQ[n].DataP
 			for i := 0; i < Kmax*Np; i++ {
 				if c.LocalTimeStepping {
-					dT = DTStartup * DT.DataP[i]
-				}
-				dtRHS := dT * RHSQ[n].DataP[i]
-				switch rkstep {
-				case 0:
-					U1[i] = U0[i] + 0.391752226571890*dtRHS
-				case 1:
-					U2[i] = 0.444370493651235*U0[i] + 0.555629506348765*U1[i] + 0.368410593050371*dtRHS
-				case 2:
-					U3[i] = 0.620101851488403*U0[i] + 0.379898148511597*U2[i] + 0.251891774271694*dtRHS
-				case 3:
-					R[i] = RHS[i] // Store the current RHS for use in the last RK step
-					U4[i] = 0.178079954393132*U0[i] + 0.821920045606868*U3[i] + 0.544974750228521*dtRHS
-				case 4:
-					dtR3 := dT * R[i]
-					R[i] = -U0[i] + 0.517231671970585*U2[i] + 0.096059710526146*U3[i] + 0.386708617503269*U4[i] +
-						0.063692468666290*dtR3 + 0.226007483236906*dtRHS
-					U0[i] += R[i]
+					RHS[i] *= DTStartup * DT.DataP[i]
+				} else {
+					RHS[i] *= dT
 				}
 			}
+			for j := range U {
+				U[j] = rk.GetRegister(c, j)[myThread][n].DataP
+			}
+			integrator.Advance(rkstep, U, Residual[n].DataP, RHS)
 		}
 	}
 	/*
 		Execution
 	*/
-	switch currentStep {
-	case 0:
-		if c.LocalTimeStepping {
-			// Setup local time stepping
-			for k := 0; k < Kmax; k++ {
-				DT.DataP[k] = -100 // Global
-			}
-		}
-		c.InterpolateSolutionToEdges(QQQ, Q_Face, Flux, Flux_Face) // Interpolates Q_Face values from Q
-	case 1, 6, 11, 16, 21:
-		rk.MaxWaveSpeed[myThread] =
-			c.CalculateEdgeFlux(rk.Time, initDT, rk.Jdet, rk.DT, rk.Q_Face, rk.Flux_Face, SortedEdgeKeys, EdgeQ1, EdgeQ2) // Global
-		if c.Dissipation != nil {
-			c.Dissipation.CalculateElementViscosity(myThread, QQQAll)
-		}
-	case 2, 7, 12, 17, 22:
-		if c.Dissipation != nil {
-			c.Dissipation.propagateEpsilonMaxToVertices(myThread)
-		}
-	case 3, 8, 13, 18, 23:
-		if initDT && c.LocalTimeStepping {
-			c.CalculateLocalDT(DT)
-		}
-		if c.Dissipation != nil {
-			c.Dissipation.CalculateEpsilonGradient(c, C0, myThread, QQQ)
-		}
-	case 4, 9, 14, 19, 24:
-		if c.Dissipation != nil {
-			c.StoreGradientEdgeFlux(SortedEdgeKeys, EdgeQ1)
+	for currentStep := beginStep; currentStep < endStep; currentStep++ {
+		if currentStep > beginStep {
+			barrier.Wait()
 		}
-	case 5, 10, 15, 20, 25:
-		rkAdvance(rkStep, QQQ)
-		if rkStep == 4 {
-			rk.LimitedPoints[myThread] = c.Limiter.LimitSolution(myThread, c.Q, rk.Residual)
-		} else {
+		var (
+			rkStep    = getRKStepNumber(currentStep)
+			initDT    = rkStep == 0 // Calculate time step within the first RK stage only, then hold it fixed
+			lastStage = rkStep == integrator.NumStages()-1
+			QQQAll    = rk.GetRegister(c, integrator.StageInput(rkStep))
+			QQQ       = QQQAll[myThread]
+		)
+		switch getRKSubStep(currentStep) {
+		case 0:
+			if c.LocalTimeStepping {
+				// Setup local time stepping
+				for k := 0; k < Kmax; k++ {
+					DT.DataP[k] = -100 // Global
+				}
+			}
 			c.InterpolateSolutionToEdges(QQQ, Q_Face, Flux, Flux_Face) // Interpolates Q_Face values from Q
+		case 1:
+			rk.MaxWaveSpeed[myThread] =
+				c.CalculateEdgeFlux(rk.Time, initDT, rk.Jdet, rk.DT, rk.Q_Face, rk.Flux_Face, SortedEdgeKeys, EdgeQ1, EdgeQ2) // Global
+			if initDT && !c.LocalTimeStepping {
+				// Parallel reduction of the wave speed, every shard computes the same global time step
+				dT = rk.calculateGlobalDT(c, barrier.ReduceMax(myThread, rk.MaxWaveSpeed[myThread]))
+			}
+			if c.Dissipation != nil {
+				c.Dissipation.CalculateElementViscosity(myThread, QQQAll)
+			}
+		case 2:
+			if c.Dissipation != nil {
+				c.Dissipation.propagateEpsilonMaxToVertices(myThread)
+			}
+		case 3:
+			if initDT && c.LocalTimeStepping {
+				c.CalculateLocalDT(DT)
+			}
+			if c.Dissipation != nil {
+				c.Dissipation.CalculateEpsilonGradient(c, C0, myThread, QQQ)
+			}
+			if c.Viscous != nil {
+				c.Viscous.CalculateViscousFlux(c, myThread, QQQ)
+			}
+		case 4:
+			if c.Dissipation != nil {
+				c.StoreGradientEdgeFlux(SortedEdgeKeys, EdgeQ1)
+			}
+			if c.Viscous != nil {
+				c.Viscous.StoreViscousEdgeFlux(c, SortedEdgeKeys, EdgeQ1)
+			}
+		case 5:
+			rkAdvance(rkStep, QQQ)
+			if lastStage {
+				rk.LimitedPoints[myThread] = c.Limiter.LimitSolution(myThread, c.Q, rk.Residual)
+				rk.ResidualMax[myThread] = GetShardResidualMax(Residual)
+			} else {
+				// The input of the next stage is interpolated to the edges for its edge flux
+				QNext := rk.GetRegister(c, integrator.StageInput(rkStep+1))[myThread]
+				if c.Limiter.Positivity != nil { // Intermediate stages are kept positive as well
+					c.Limiter.Positivity.Limit(myThread, QNext)
+				}
+				c.InterpolateSolutionToEdges(QNext, Q_Face, Flux, Flux_Face) // Interpolates Q_Face values from Q
+			}
 		}
 	}
+	rk.StepDT[myThread] = dT
 	return
 }
 
@@ -354,7 +625,16 @@ func getRKStepNumber(currentStep int) (rkStepNum int) {
 	return
 }
 
-func (c *Euler) RHSInternalPoints(Kmax int, Jdet utils.Matrix, F_RT_DOF, RHSQ [4]utils.Matrix) {
+func getRKSubStep(currentStep int) (subStep int) {
+	// Substep 0 is the initial interpolation to the edges, substeps 1 through 5 repeat for each RK stage
+	if currentStep == 0 {
+		return 0
+	}
+	subStep = (currentStep-1)%5 + 1
+	return
+}
+
+func (c *Euler) RHSInternalPoints(myThread, Kmax int, Jdet utils.Matrix, F_RT_DOF, RHSQ [4]utils.Matrix) {
 	var (
 		JdetD = Jdet.DataP
 		Nint  = c.dfr.FluxElement.NpInt
@@ -387,6 +667,13 @@ func (c *Euler) RHSInternalPoints(Kmax int, Jdet utils.Matrix, F_RT_DOF, RHSQ [4
 				data[ind] *= -oojd
 			}
 		}
+		if c.SourceTerm != nil {
+			// dQ/dt = -div(F,G) + S
+			src := c.SourceTerm[myThread][n].DataP
+			for i := 0; i < Nint*Kmax; i++ {
+				data[i] += src[i]
+			}
+		}
 	}
 }
 
@@ -428,39 +715,13 @@ func (c *Euler) InitializeSolution(verbose bool) {
 			c.Q[np] = c.InitializeFS(Kmax)
 		}
 	case SHOCKTUBE:
-		c.SolutionX = c.ShardByK(c.dfr.SolutionX)
-		c.SolutionY = c.ShardByK(c.dfr.SolutionY)
-		gamma := 1.4
-		c.FSIn = NewFreestreamFromQinf(gamma, [4]float64{1, 0, 0, 1 / (gamma - 1)})
-		c.FSOut = NewFreestreamFromQinf(gamma, [4]float64{0.125, 0, 0, 0.1 / (gamma - 1)})
-		NP := c.Partitions.ParallelDegree
-		for np := 0; np < NP; np++ {
-			var (
-				Kmax = c.Partitions.GetBucketDimension(np)
-				Nint = c.dfr.SolutionElement.Np
-			)
-			for n := 0; n < 4; n++ {
-				c.Q[np][n] = utils.NewMatrix(Nint, Kmax)
-			}
-			for k := 0; k < Kmax; k++ {
-				for i := 0; i < Nint; i++ {
-					ind := k + i*Kmax
-					if c.SolutionX[np].DataP[ind] < 0.5 { // The length of the domain should be 0->1
-						c.Q[np][0].DataP[ind] = c.FSIn.Qinf[0]
-						c.Q[np][1].DataP[ind] = c.FSIn.Qinf[1]
-						c.Q[np][2].DataP[ind] = c.FSIn.Qinf[2]
-						c.Q[np][3].DataP[ind] = c.FSIn.Qinf[3]
-					} else {
-						c.Q[np][0].DataP[ind] = c.FSOut.Qinf[0]
-						c.Q[np][1].DataP[ind] = c.FSOut.Qinf[1]
-						c.Q[np][2].DataP[ind] = c.FSOut.Qinf[2]
-						c.Q[np][3].DataP[ind] = c.FSOut.Qinf[3]
-					}
-				}
-			}
-		}
+		rp := c.InputParams.GetShockTube()
+		c.InitializeShockTube(rp)
 		// There are 5 points vertically, then we double sample (4 pts per cell) to capture all the dynamics
-		c.ShockTube = sod_shock_tube.NewSODShockTube(4*c.dfr.K/5, c.dfr)
+		c.ShockTube = sod_shock_tube.NewSODShockTube(4*c.dfr.K/5, c.dfr, rp)
+		if verbose {
+			fmt.Printf("\t%s\n", rp.Print())
+		}
 	case IVORTEX:
 		c.FSFar = NewFreestreamFromQinf(1.4, [4]float64{1, 1, 0, 3})
 		c.SolutionX = c.ShardByK(c.dfr.SolutionX)
@@ -480,13 +741,40 @@ func (c *Euler) InitializeSolution(verbose bool) {
 		if verbose {
 			fmt.Printf("\tReplaced %d Wall boundary conditions with analytic BC_IVortex\n", count)
 		}
+	case MMS:
+		gamma := c.InputParams.Gamma
+		if gamma == 0 {
+			gamma = 1.4
+		}
+		mt := NewManufacturedSolutionType(c.InputParams.ManufacturedSolution)
+		c.InitializeManufactured(mt, gamma)
+		if verbose {
+			fmt.Printf("\tManufactured solution [%s], all boundaries set to the exact solution\n", mt.Print())
+		}
+	case RINGLEB:
+		rb := ringleb.NewRinglebDefault()
+		x, y := rb.GetXY(rb.Q0, 0.5*(rb.KMin+rb.KMax), false)
+		count := c.InitializeExactState(rb, rb.Gamma, x, y)
+		if verbose {
+			fmt.Printf("\tStreamlines k = [%5.3f, %5.3f], inflow and outflow speed q = %5.3f\n",
+				rb.KMin, rb.KMax, rb.Q0)
+			fmt.Printf("\tReplaced %d inflow and outflow boundary conditions with the exact solution\n", count)
+		}
+	case SVORTEX:
+		sv := supersonic_vortex.NewSVortexDefault()
+		count := c.InitializeExactState(sv, sv.Gamma, 0.5*(sv.Ri+sv.Ro), 0)
+		if verbose {
+			fmt.Printf("\tRadii = [%5.3f, %5.3f], inner wall Mach number = %5.3f\n", sv.Ri, sv.Ro, sv.Mi)
+			fmt.Printf("\tReplaced %d inflow and outflow boundary conditions with the exact solution\n", count)
+		}
 	default:
 		panic("unknown case type")
 	}
 }
 
 func (c *Euler) CheckIfFinished(Time, FinalTime float64, steps int) (finished bool) {
-	if Time >= FinalTime || steps >= c.MaxIterations {
+	// Time is not advanced with local time stepping, only the iteration count can finish the solution
+	if (!c.LocalTimeStepping && Time >= FinalTime) || steps >= c.MaxIterations {
 		finished = true
 	}
 	return
@@ -501,9 +789,16 @@ func (c *Euler) PrintInitialization(FinalTime float64) {
 		fmt.Printf("    iter    time      dt")
 	}
 	fmt.Printf("       Res0       Res1       Res2")
-	fmt.Printf("       Res3         L1         L2\n")
+	fmt.Printf("       Res3         L1         L2")
+	if c.Forces != nil {
+		fmt.Printf("         CL         CD         CM")
+	}
+	if c.Entropy != nil {
+		fmt.Printf("    Entropy         dS")
+	}
+	fmt.Printf("\n")
 }
-func (c *Euler) PrintUpdate(Time, dt float64, steps int, Q, Residual [][4]utils.Matrix, plotQ bool, pm *PlotMeta,
+func (c *Euler) PrintUpdate(Time, dt float64, steps int, Q [][4]utils.Matrix, residual [6]float64, plotQ bool, pm *PlotMeta,
 	printMem bool, limitedPoints []int) {
 	format := "%11.4e"
 	if plotQ {
@@ -520,23 +815,22 @@ func (c *Euler) PrintUpdate(Time, dt float64, steps int, Q, Residual [][4]utils.
 	} else {
 		fmt.Printf("%8d%8.5f%8.5f", steps, Time, dt)
 	}
-	var l1, l2 float64
-	for n := 0; n < 4; n++ {
-		var maxR float64
-		for np := 0; np < c.Partitions.ParallelDegree; np++ {
-			m := Residual[np][n].Max()
-			if m > maxR {
-				maxR = m
-			}
-		}
-		fmt.Printf(format, maxR)
-		if maxR > l1 {
-			l1 = maxR
+	for _, r := range residual {
+		fmt.Printf(format, r)
+	}
+	if c.Forces != nil {
+		c.CalculateForces(c.Forces)
+		fmt.Printf(format, c.Forces.Total.CL)
+		fmt.Printf(format, c.Forces.Total.CD)
+		fmt.Printf(format, c.Forces.Total.CM)
+		if err := c.Forces.WriteHistory(Time, steps); err != nil {
+			fmt.Printf(" unable to write force history: %s", err.Error())
 		}
-		l2 += maxR * maxR
 	}
-	fmt.Printf(format, l1)
-	fmt.Printf(format, math.Sqrt(l2)/4.)
+	if c.Entropy != nil {
+		fmt.Printf(format, c.Entropy.Total)
+		fmt.Printf(format, c.Entropy.Production)
+	}
 	if printMem {
 		fmt.Printf(" :: %s", utils.GetMemUsage())
 	}
@@ -547,6 +841,9 @@ func (c *Euler) PrintUpdate(Time, dt float64, steps int, Q, Residual [][4]utils.
 	if lpsum != 0 {
 		fmt.Printf(" #limited:%5d/%-8d ", lpsum, c.dfr.K*c.dfr.SolutionElement.Np)
 	}
+	if c.Limiter != nil && c.Limiter.Positivity != nil {
+		fmt.Printf(" #positivity:%5d/%-8d ", c.Limiter.Positivity.LimitedCount, c.dfr.K)
+	}
 	fmt.Printf("\n")
 }
 func (c *Euler) PrintFinal(elapsed time.Duration, steps int) {
@@ -562,20 +859,13 @@ func (c *Euler) PrintFinal(elapsed time.Duration, steps int) {
 		iRate/1000000000., instructionRate)
 }
 
-func (rk *RungeKutta4SSP) calculateGlobalDT(c *Euler) {
-	var (
-		pm = c.Partitions
-		NP = pm.ParallelDegree
-	)
-	// Must be done in controller/sync process
-	var wsMaxAll float64
-	for np := 0; np < NP; np++ {
-		wsMaxAll = math.Max(wsMaxAll, rk.MaxWaveSpeed[np])
-	}
-	rk.GlobalDT = c.CFL / wsMaxAll
-	if rk.Time+rk.GlobalDT > c.FinalTime {
-		rk.GlobalDT = c.FinalTime - rk.Time
+func (rk *RungeKutta4SSP) calculateGlobalDT(c *Euler, wsMaxAll float64) (dT float64) {
+	// The wave speed is the max over all shards
+	dT = c.CFL / wsMaxAll
+	if rk.Time+dT > c.FinalTime {
+		dT = c.FinalTime - rk.Time
 	}
+	return
 }
 
 func (c *Euler) GetSolutionGradientUsingRTElement(myThread, varNum int, Q [4]utils.Matrix, GradX, GradY, DOFX, DOFY utils.Matrix) {
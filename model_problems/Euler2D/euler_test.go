@@ -1,16 +1,35 @@
 package Euler2D
 
 import (
+	"bytes"
+	"compress/bzip2"
+	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"image"
+	"image/gif"
+	"image/png"
+	"io/ioutil"
 	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/notargets/gocfd/DG1D"
 	"github.com/notargets/gocfd/DG2D"
 
 	"github.com/notargets/gocfd/types"
 
+	"github.com/notargets/gocfd/model_problems/Euler1D/exact_riemann"
+	"github.com/notargets/gocfd/model_problems/Euler2D/isentropic_vortex"
+
 	"github.com/stretchr/testify/assert"
 
 	"github.com/notargets/gocfd/utils"
@@ -333,6 +352,7 @@ func TestFluxInterpolation(t *testing.T) {
 	ip.FluxType = "Roe"
 	c := NewEuler(&ip, "../../DG2D/test_tris_6.neu", 1, plotMesh, false, false)
 	rk := c.NewRungeKuttaSSP()
+	defer rk.Close()
 	c.InterpolateSolutionToEdges(c.Q[0], rk.Q_Face[0], rk.Flux[0], rk.Flux_Face[0])
 	el := c.dfr.SolutionElement
 	/*
@@ -416,6 +436,557 @@ func TestFluxJacobian(t *testing.T) {
 	}
 }
 
+func TestNumericalFluxes(t *testing.T) {
+	assert.Equal(t, FLUX_HLLC, NewFluxType("HLLC"))
+	assert.Equal(t, FLUX_AUSMPlusUp, NewFluxType("ausm+-up"))
+	assert.Equal(t, FLUX_AUSMPlusUp, NewFluxType("ausm+"))
+	var (
+		ip       = *ipDefault
+		c        = NewEuler(&ip, "../../DG2D/test_tris_6.neu", 1, false, false, false)
+		Nedge    = c.dfr.FluxElement.NpEdge
+		normal   = [2]float64{0.6, 0.8}
+		Gamma    = c.FSFar.Gamma
+		fluxFunc = map[string]func(QL, QR [4]utils.Matrix, normalFlux [][4]float64){
+			"hllc": func(QL, QR [4]utils.Matrix, normalFlux [][4]float64) {
+				c.HLLCFlux(0, 0, 1, 1, 0, 0, QL, QR, normal, normalFlux)
+			},
+			"ausm+-up": func(QL, QR [4]utils.Matrix, normalFlux [][4]float64) {
+				c.AUSMPlusUpFlux(0, 0, 1, 1, 0, 0, QL, QR, normal, normalFlux)
+			},
+		}
+	)
+	newFaceQ := func(rho, u, v, p float64) (Q [4]utils.Matrix) {
+		q := [4]float64{rho, rho * u, rho * v, p/(Gamma-1) + 0.5*rho*(u*u+v*v)}
+		for n := 0; n < 4; n++ {
+			Q[n] = utils.NewMatrix(Nedge, 1)
+			Q[n].AddScalar(q[n])
+		}
+		return
+	}
+	physicalFlux := func(Q [4]utils.Matrix) (F []float64) {
+		Fx, Fy := c.FluxCalcBase(Q[0].DataP[0], Q[1].DataP[0], Q[2].DataP[0], Q[3].DataP[0])
+		for n := 0; n < 4; n++ {
+			F = append(F, normal[0]*Fx[n]+normal[1]*Fy[n])
+		}
+		return
+	}
+	for name, flux := range fluxFunc {
+		normalFlux := make([][4]float64, Nedge)
+		// Consistency, identical states give the physical flux
+		Q := newFaceQ(1.2, 0.3, -0.2, 0.9)
+		flux(Q, Q, normalFlux)
+		assert.InDeltaSlicef(t, physicalFlux(Q), normalFlux[0][:], 1.e-12, name)
+		// A stationary contact is preserved, only the pressure is transmitted
+		flux(newFaceQ(1, 0, 0, 1), newFaceQ(0.125, 0, 0, 1), normalFlux)
+		assert.InDeltaSlicef(t, []float64{0, normal[0], normal[1], 0}, normalFlux[Nedge-1][:], 1.e-12, name)
+		// Supersonic flow through the face is fully upwinded
+		QL := newFaceQ(1, 2.4, 3.2, 1)
+		flux(QL, newFaceQ(0.5, 1.8, 2.4, 0.4), normalFlux)
+		assert.InDeltaSlicef(t, physicalFlux(QL), normalFlux[0][:], 1.e-12, name)
+	}
+	// Freestream is preserved through the edge flux calculation
+	for _, fluxType := range []string{"hllc", "ausm+-up"} {
+		ip.FluxType = fluxType
+		ip.Minf = 0.8
+		ip.PolynomialOrder = 1
+		c := NewEuler(&ip, "../../DG2D/test_tris_6_nowall.neu", 1, false, false, false)
+		Q0 := c.RecombineShardsKBy4(c.Q)
+		rk := c.NewRungeKuttaSSP()
+		defer rk.Close()
+		for i := 0; i < 5; i++ {
+			rk.Step(c)
+			rk.StepCount++
+		}
+		Q := c.RecombineShardsKBy4(c.Q)
+		for n := 0; n < 4; n++ {
+			assert.InDeltaSlicef(t, Q0[n].DataP, Q[n].DataP, 1.e-10, fluxType)
+		}
+	}
+}
+
+func TestEntropyStable(t *testing.T) {
+	assert.Equal(t, FLUX_IsmailRoe, NewFluxType("Ismail-Roe"))
+	assert.Equal(t, FLUX_Chandrashekar, NewFluxType("chandrashekar"))
+	assert.True(t, FLUX_IsmailRoe.IsEntropyStable())
+	assert.False(t, FLUX_Roe.IsEntropyStable())
+	assert.InDelta(t, 2., logMean(1, 3)*math.Log(3), 1.e-14)
+	assert.InDelta(t, 0.002/math.Log(1.002), logMean(1, 1.002), 1.e-12) // Series branch
+	for _, fluxType := range []string{"ismail-roe", "chandrashekar"} {
+		var (
+			ip     = *ipDefault
+			normal = [2]float64{0.6, 0.8}
+		)
+		ip.FluxType = fluxType
+		ip.Minf = 0.8
+		ip.PolynomialOrder = 2
+		c := NewEuler(&ip, "../../DG2D/test_tris_6_nowall.neu", 1, false, false, false)
+		var (
+			Nedge = c.dfr.FluxElement.NpEdge
+			Gamma = c.FSFar.Gamma
+		)
+		newFaceQ := func(rho, u, v, p float64) (Q [4]utils.Matrix) {
+			q := [4]float64{rho, rho * u, rho * v, p/(Gamma-1) + 0.5*rho*(u*u+v*v)}
+			for n := 0; n < 4; n++ {
+				Q[n] = utils.NewMatrix(Nedge, 1)
+				Q[n].AddScalar(q[n])
+			}
+			return
+		}
+		getQ := func(Q [4]utils.Matrix) [4]float64 {
+			return [4]float64{Q[0].DataP[0], Q[1].DataP[0], Q[2].DataP[0], Q[3].DataP[0]}
+		}
+		QFL, QFR := newFaceQ(1.2, 0.3, -0.2, 0.9), newFaceQ(0.7, -0.1, 0.4, 1.3)
+		QL, QR := getQ(QFL), getQ(QFR)
+		vL, vR := c.GetEntropyVariables(QFL, 0), c.GetEntropyVariables(QFR, 0)
+		// Consistent with the physical flux and symmetric
+		Fx, Fy := c.EntropyConservativeFlux(QL, QL)
+		FxP, FyP := c.FluxCalcBase(QL[0], QL[1], QL[2], QL[3])
+		assert.InDeltaSlicef(t, FxP[:], Fx[:], 1.e-12, fluxType)
+		assert.InDeltaSlicef(t, FyP[:], Fy[:], 1.e-12, fluxType)
+		Fx, Fy = c.EntropyConservativeFlux(QL, QR)
+		FxR, FyR := c.EntropyConservativeFlux(QR, QL)
+		assert.InDeltaSlicef(t, Fx[:], FxR[:], 1.e-12, fluxType)
+		assert.InDeltaSlicef(t, Fy[:], FyR[:], 1.e-12, fluxType)
+		// Entropy conservation, the jump in entropy variables dotted with the flux is the jump in the flux potential
+		var dVFx, dVFy, dVFn float64
+		for n := 0; n < 4; n++ {
+			dVFx += (vR[n] - vL[n]) * Fx[n]
+			dVFy += (vR[n] - vL[n]) * Fy[n]
+		}
+		assert.InDeltaf(t, QR[1]-QL[1], dVFx, 1.e-12, fluxType)
+		assert.InDeltaf(t, QR[2]-QL[2], dVFy, 1.e-12, fluxType)
+		// The edge flux produces entropy
+		normalFlux := make([][4]float64, Nedge)
+		c.EntropyStableFlux(0, 0, 1, 1, 0, 0, QFL, QFR, normal, normalFlux)
+		for n := 0; n < 4; n++ {
+			dVFn += (vR[n] - vL[n]) * normalFlux[0][n]
+		}
+		dPsi := normal[0]*(QR[1]-QL[1]) + normal[1]*(QR[2]-QL[2])
+		assert.Less(t, dVFn-dPsi, -1.e-3, fluxType)
+		// Freestream is preserved by the split form and the entropy stable edge flux
+		var (
+			rk     = c.NewRungeKuttaSSP()
+			Q0     = c.RecombineShardsKBy4(c.Q)
+			S0     = c.TotalEntropy(c.Q)
+			sInf   = c.FSFar.GetFlowFunctionQQ(c.FSFar.Qinf, Entropy)
+			area   float64
+			Jdet   = c.dfr.Jdet.DataP
+			rhoInf = c.FSFar.Qinf[0]
+		)
+		defer rk.Close()
+		for k := 0; k < c.dfr.K; k++ {
+			area += 2 * Jdet[k] // The reference triangle has an area of 2
+		}
+		assert.InDeltaf(t, -rhoInf*sInf*area/(Gamma-1), S0, 1.e-10, fluxType)
+		c.Entropy = c.NewEntropyDiagnostic()
+		for i := 0; i < 5; i++ {
+			rk.Step(c)
+			rk.StepCount++
+			c.Entropy.Update(c)
+		}
+		Q := c.RecombineShardsKBy4(c.Q)
+		for n := 0; n < 4; n++ {
+			assert.InDeltaSlicef(t, Q0[n].DataP, Q[n].DataP, 1.e-10, fluxType)
+		}
+		assert.InDeltaf(t, 0, c.Entropy.Production, 1.e-10, fluxType)
+		assert.InDeltaf(t, 0, c.EntropyProductionRate(c.Q, rk.RHSQ), 1.e-10, fluxType)
+		// The volume flux works in the integrator's scratch storage, without allocating in the time step
+		allocs := testing.AllocsPerRun(10, func() {
+			c.AddEntropyConservativeVolumeFlux(0, rk.Kmax[0], rk.Jdet[0], rk.Jinv[0], c.Q[0], rk.RHSQ[0],
+				rk.EntropyScratch[0])
+		})
+		assert.Zerof(t, allocs, fluxType)
+	}
+	/*
+		A swirling flow with varying density and pressure in an annulus closed by walls, the swirl vanishes at the walls
+		so that there is no entropy flux through the straight edges approximating the circles. The entropy of the
+		semi discrete solution decreases, the design order of accuracy is checked in TestManufacturedSolution.
+	*/
+	meshFile := writeCouetteMesh(t, "../../test_cases/Grid/Euler2D/Couette_K082.neu.bz2")
+	for _, fluxType := range []string{"ismail-roe", "chandrashekar"} {
+		for _, N := range []int{2, 3} {
+			ip := *ipDefault
+			ip.FluxType = fluxType
+			ip.Minf = 0.5
+			ip.PolynomialOrder = N
+			ip.TimeIntegrator = "euler"
+			c := NewEuler(&ip, meshFile, 1, false, false, false)
+			c.Dissipation = nil // Only the inviscid fluxes contribute
+			var (
+				X, Y  = c.ShardByK(c.dfr.SolutionX)[0], c.ShardByK(c.dfr.SolutionY)[0]
+				Q     = c.Q[0]
+				Gamma = c.FSFar.Gamma
+				Q0    [4][]float64
+			)
+			for i := range X.DataP {
+				var (
+					r, th = math.Hypot(X.DataP[i], Y.DataP[i]), math.Atan2(Y.DataP[i], X.DataP[i])
+					rho   = 1 + 0.2*math.Sin(r)*math.Cos(2*th)
+					w     = 0.02 * (r - 1) * (r - 1) * (4 - r) * (4 - r) * (1 + 0.2*math.Cos(3*th))
+					u, v  = -w * Y.DataP[i], w * X.DataP[i]
+					p     = 1/Gamma + 0.2*math.Cos(r+th)
+				)
+				Q[0].DataP[i], Q[1].DataP[i], Q[2].DataP[i] = rho, rho*u, rho*v
+				Q[3].DataP[i] = p/(Gamma-1) + 0.5*rho*(u*u+v*v)
+			}
+			for n := 0; n < 4; n++ {
+				Q0[n] = append([]float64{}, Q[n].DataP...)
+			}
+			// A forward Euler step leaves the RHS of the initial state times the time step in RHSQ
+			rk := c.NewRungeKuttaSSP()
+			rk.Step(c)
+			rk.Close()
+			for n := 0; n < 4; n++ {
+				copy(Q[n].DataP, Q0[n])
+			}
+			dSdt := c.EntropyProductionRate(c.Q, rk.RHSQ) / rk.GlobalDT
+			assert.Lessf(t, dSdt, 0., "%s N = %d", fluxType, N)
+		}
+	}
+}
+
+func TestPositivityLimiter(t *testing.T) {
+	for label, expected := range map[string]LimiterType{"": None, "positivity": None, "None + Positivity": None,
+		"BarthJesperson + Positivity": BarthJesperson, "PerssonC0": PerssonC0} {
+		lt, positivity, dissipation := NewLimiterTypes(label)
+		assert.Equal(t, expected, lt, label)
+		assert.Equal(t, strings.Contains(label, "ositivity"), positivity, label)
+		// Only the default, an explicit None and PerssonC0 add artificial dissipation
+		assert.Equal(t, label == "" || label == "None + Positivity" || label == "PerssonC0", dissipation, label)
+	}
+	var (
+		ip = *ipDefault
+	)
+	ip.Minf = 0.8
+	ip.PolynomialOrder = 2
+	ip.Limiter = "Positivity"
+	c := NewEuler(&ip, "../../DG2D/test_tris_6_nowall.neu", 1, false, false, false)
+	var (
+		pl       = c.Limiter.Positivity
+		Q        = c.Q[0]
+		Np, Kmax = Q[0].Dims()
+		Gamma    = c.FSFar.Gamma
+		average  = func(k int) (Qave [4]float64) {
+			for n := 0; n < 4; n++ {
+				for i := 0; i < Np; i++ {
+					Qave[n] += pl.Weights[i] * Q[n].DataP[k+i*Kmax]
+				}
+				Qave[n] /= 2 // The weights sum to the area of the reference triangle
+			}
+			return
+		}
+	)
+	assert.NotNil(t, pl)
+	assert.Nil(t, c.Dissipation) // The positivity limiter alone adds no artificial dissipation
+	// Element 0 has a negative density at one point, element 1 a negative pressure at one point
+	Q[0].DataP[0] = -0.2
+	Q[0].DataP[2*Kmax] = 1.8
+	Q[3].DataP[1] = 0.1
+	Q0 := c.RecombineShardsKBy4(c.Q)
+	ave0, ave1 := average(0), average(1)
+	pl.Limit(0, Q)
+	pl.UpdateCount()
+	assert.Equal(t, 2, pl.LimitedCount)
+	aveL0, aveL1 := average(0), average(1)
+	assert.InDeltaSlice(t, ave0[:], aveL0[:], 1.e-12)
+	assert.InDeltaSlice(t, ave1[:], aveL1[:], 1.e-12)
+	var QFace [4]utils.Matrix
+	for n := 0; n < 4; n++ {
+		QFace[n] = c.dfr.FluxEdgeInterp.Mul(Q[n])
+	}
+	for _, QQ := range [][4]utils.Matrix{Q, QFace} {
+		for i := range QQ[0].DataP {
+			assert.Greater(t, QQ[0].DataP[i], 0.)
+			assert.Greater(t, c.FSFar.GetFlowFunction(QQ, i, StaticPressure), 0.)
+		}
+	}
+	// Elements that are positive are not modified
+	for k := 2; k < Kmax; k++ {
+		for n := 0; n < 4; n++ {
+			for i := 0; i < Np; i++ {
+				assert.Equal(t, Q0[n].DataP[k+i*Kmax], Q[n].DataP[k+i*Kmax])
+			}
+		}
+	}
+	// A strong expansion stays positive through the RK stages
+	for k := 0; k < Kmax; k++ {
+		for i := 0; i < Np; i++ {
+			ind := k + i*Kmax
+			u := 3. * float64(2*(k%2)-1)
+			Q[0].DataP[ind], Q[1].DataP[ind], Q[2].DataP[ind] = 1, u, 0
+			Q[3].DataP[ind] = 0.01/(Gamma-1) + 0.5*u*u
+		}
+	}
+	rk := c.NewRungeKuttaSSP()
+	defer rk.Close()
+	for i := 0; i < 10; i++ {
+		rk.Step(c)
+		rk.Time += rk.GlobalDT
+		rk.StepCount++
+		pl.UpdateCount()
+	}
+	for i := range Q[0].DataP {
+		assert.Greater(t, Q[0].DataP[i], 0.)
+		assert.Greater(t, c.FSFar.GetFlowFunction(Q, i, StaticPressure), 0.)
+	}
+}
+
+func TestNewEulerMeshError(t *testing.T) {
+	// A mesh that can not be used panics with the error, leaving the caller to recover or exit
+	meshFile := filepath.Join(t.TempDir(), "bogus.msh")
+	mesh := `$MeshFormat
+2.2 0 8
+$EndMeshFormat
+$PhysicalNames
+2
+1 1 "Bogus-side"
+2 2 "fluid"
+$EndPhysicalNames
+$Nodes
+4
+1 0 0 0
+2 1 0 0
+3 1 1 0
+4 0 1 0
+$EndNodes
+$Elements
+3
+1 1 2 1 1 1 2
+2 2 2 2 1 1 2 3
+3 2 2 2 1 1 3 4
+$EndElements
+`
+	if err := ioutil.WriteFile(meshFile, []byte(mesh), 0644); err != nil {
+		panic(err)
+	}
+	ip := *ipDefault
+	defer func() {
+		err, ok := recover().(error)
+		if assert.True(t, ok) {
+			assert.Contains(t, err.Error(), "unable to use mesh file")
+			assert.Contains(t, err.Error(), "bogus-side")
+		}
+	}()
+	NewEuler(&ip, meshFile, 1, false, false, false)
+}
+
+func TestPressureBCs(t *testing.T) {
+	var (
+		ip = *ipDefault
+	)
+	ip.Minf = 0.5
+	ip.PolynomialOrder = 2
+	ip.BCs = map[string]map[int]map[string]float64{
+		"Inflow":  {1: {"Pt": 1.18621, "Tt": 1.05, "Alpha": 0}},
+		"Outflow": {1: {"P": 1}},
+	}
+	c := NewEuler(&ip, "../../DG2D/fstepA001.neu", 1, false, false, false)
+	assert.Equal(t, 16, len(c.PressureBCs))
+	assert.Panics(t, func() { NewPressureBC(types.NewBCTAG("In-1"), map[string]float64{"pt": 1}, c.FSFar) })
+	// Boundaries using the existing parameters, NPR for inflow, keep the FarBC
+	ip.BCs = map[string]map[int]map[string]float64{
+		"Inflow":  {1: {"NPR": 4}},
+		"Outflow": {1: {"P": 1}},
+	}
+	cFar := NewEuler(&ip, "../../DG2D/fstepA001.neu", 1, false, false, false)
+	assert.NotEqual(t, 0, len(cFar.PressureBCs))
+	assert.Less(t, len(cFar.PressureBCs), 16)
+	for _, pbc := range cFar.PressureBCs {
+		assert.False(t, pbc.Inflow)
+	}
+	ip.BCs = map[string]map[int]map[string]float64{"Inflow": {1: {"NPR": 4}}, "Outflow": {1: {"NPR": 4}}}
+	cFar = NewEuler(&ip, "../../DG2D/fstepA001.neu", 1, false, false, false)
+	assert.Equal(t, 0, len(cFar.PressureBCs))
+	var (
+		Gamma  = c.FSFar.Gamma
+		GM1    = Gamma - 1
+		normal = [2]float64{-0.6, -0.8}
+		tol    = 1.e-10
+	)
+	// Total conditions of a state with Mach M moving along dir, relative to the freestream static values
+	newState := func(M, Tt, Pt float64, dir [2]float64) (Q [4]float64) {
+		var (
+			C2  = Tt / (1 + 0.5*GM1*M*M)
+			p   = Pt * c.FSFar.Pinf / math.Pow(1+0.5*GM1*M*M, Gamma/GM1)
+			rho = Gamma * p / C2
+			u   = M * math.Sqrt(C2) * dir[0]
+			v   = M * math.Sqrt(C2) * dir[1]
+		)
+		return [4]float64{rho, rho * u, rho * v, p/GM1 + 0.5*rho*(u*u+v*v)}
+	}
+	{ // Subsonic inflow reproduces an interior state with the same total conditions and direction
+		pbc := NewPressureBC(types.NewBCTAG("In-1"), map[string]float64{"pt": 1.5, "tt": 1.2, "alpha": 60}, c.FSFar)
+		dir := pbc.Direction
+		QInt := newState(0.3, 1.2, 1.5, dir)
+		assert.InDeltaSlice(t, QInt[:], func() []float64 { Q := c.InflowState(pbc, QInt, normal); return Q[:] }(), tol)
+		// Without a flow angle the flow enters normal to the boundary
+		pbc = NewPressureBC(types.NewBCTAG("In-1"), map[string]float64{"pt": 1.5, "tt": 1.2}, c.FSFar)
+		QInt = newState(0.4, 1.2, 1.5, [2]float64{-normal[0], -normal[1]})
+		assert.InDeltaSlice(t, QInt[:], func() []float64 { Q := c.InflowState(pbc, QInt, normal); return Q[:] }(), tol)
+		// From rest the inflow accelerates and keeps the total conditions
+		Q := c.InflowState(pbc, newState(0, 1, 1, dir), normal)
+		M := c.FSFar.GetFlowFunctionQQ(Q, Mach)
+		assert.Greater(t, M, 0.1)
+		p := c.FSFar.GetFlowFunctionQQ(Q, StaticPressure)
+		assert.InDelta(t, 1.5*c.FSFar.Pinf, p*math.Pow(1+0.5*GM1*M*M, Gamma/GM1), tol)
+		assert.InDelta(t, 1.2, Gamma*p/Q[0]*(1+0.5*GM1*M*M), tol)
+	}
+	{ // Supersonic inflow is fixed by the total conditions and Mach number
+		pbc := NewPressureBC(types.NewBCTAG("In-1"), map[string]float64{"pt": 8, "tt": 1.8, "mach": 2, "alpha": 0}, c.FSFar)
+		QExpected := newState(2, 1.8, 8, [2]float64{1, 0})
+		Q := c.InflowState(pbc, newState(0.5, 1, 1, [2]float64{1, 0}), normal)
+		assert.InDeltaSlice(t, QExpected[:], Q[:], tol)
+	}
+	{ // Subsonic outflow imposes the back pressure with the interior entropy, supersonic outflow extrapolates
+		pbc := NewPressureBC(types.NewBCTAG("Out-exit"), map[string]float64{"p": 0.9}, c.FSFar)
+		QInt := newState(0.5, 1.1, 1.3, [2]float64{-normal[0], normal[1]})
+		Q := c.OutflowState(pbc, QInt, [2]float64{-normal[0], -normal[1]})
+		assert.InDelta(t, 0.9*c.FSFar.Pinf, c.FSFar.GetFlowFunctionQQ(Q, StaticPressure), tol)
+		assert.InDelta(t, c.FSFar.GetFlowFunctionQQ(QInt, Entropy), c.FSFar.GetFlowFunctionQQ(Q, Entropy), tol)
+		QInt = newState(1.5, 1.1, 1.3, [2]float64{1, 0})
+		Q = c.OutflowState(pbc, QInt, [2]float64{1, 0})
+		assert.Equal(t, QInt, Q)
+	}
+	// The freestream is preserved near the inflow and outflow when the BCs match the freestream
+	rk := c.NewRungeKuttaSSP()
+	defer rk.Close()
+	for i := 0; i < 2; i++ {
+		rk.Step(c)
+		rk.StepCount++
+	}
+	var (
+		Q       = c.Q[0]
+		Kmax    = c.dfr.K
+		X       = c.dfr.SolutionX.DataP
+		checked int
+	)
+	for ind := range Q[0].DataP {
+		assert.False(t, math.IsNaN(Q[0].DataP[ind]))
+		k := ind % Kmax
+		if x := X[k*c.dfr.SolutionElement.Np]; x < 0.05 || x > 2.95 {
+			checked++
+			for n := 0; n < 4; n++ {
+				assert.InDelta(t, c.FSFar.Qinf[n], Q[n].DataP[ind], 1.e-4)
+			}
+		}
+	}
+	assert.Greater(t, checked, 0)
+}
+
+func TestSlipDirichletNeumanBCs(t *testing.T) {
+	// A channel with slip walls on both sides, a fixed state inlet and a zero gradient exit
+	mesh := `$MeshFormat
+2.2 0 8
+$EndMeshFormat
+$PhysicalNames
+4
+1 1 "Slip"
+1 2 "Neuman-exit"
+1 3 "Dirichlet-inlet"
+2 4 "fluid"
+$EndPhysicalNames
+$Nodes
+8
+1 0 0 0
+2 1 0 0
+3 2 0 0
+4 3 0 0
+5 0 1 0
+6 1 1 0
+7 2 1 0
+8 3 1 0
+$EndNodes
+$Elements
+14
+1 1 2 1 1 1 2
+2 1 2 1 1 2 3
+3 1 2 1 1 3 4
+4 1 2 1 1 8 7
+5 1 2 1 1 7 6
+6 1 2 1 1 6 5
+7 1 2 2 2 4 8
+8 1 2 3 3 5 1
+9 2 2 4 1 1 2 6
+10 2 2 4 1 1 6 5
+11 2 2 4 1 2 3 7
+12 2 2 4 1 2 7 6
+13 2 2 4 1 3 4 8
+14 2 2 4 1 3 8 7
+$EndElements
+`
+	meshFile := filepath.Join(t.TempDir(), "channel.msh")
+	if err := ioutil.WriteFile(meshFile, []byte(mesh), 0644); err != nil {
+		panic(err)
+	}
+	ip := *ipDefault
+	ip.Minf = 0.5
+	ip.PolynomialOrder = 2
+	c := NewEuler(&ip, meshFile, 1, false, false, false)
+	assert.Equal(t, 1, len(c.DirichletBCs))
+	for _, dbc := range c.DirichletBCs { // Without parameters the inlet is held at the freestream
+		assert.Equal(t, c.FSFar.Qinf, dbc.Q)
+	}
+	var (
+		Gamma = c.FSFar.Gamma
+		GM1   = Gamma - 1
+		tol   = 1.e-10
+	)
+	{ // The Dirichlet state is given relative to the freestream
+		dbc := NewDirichletBC(types.NewBCTAG("Dirichlet-in"), map[string]float64{"rho": 2, "u": 0.3, "p": 1.5}, c.FSFar)
+		rho, p := 2*c.FSFar.Qinf[0], 1.5*c.FSFar.Pinf
+		assert.InDeltaSlice(t, []float64{rho, 0.3 * rho, 0, p/GM1 + 0.5*rho*0.09}, dbc.Q[:], tol)
+		assert.Panics(t, func() { NewDirichletBC(types.NewBCTAG("Dirichlet-in"), map[string]float64{"p": -1}, c.FSFar) })
+	}
+	{ // The slip plane removes the normal velocity isentropically and keeps the tangential velocity
+		var (
+			Nedge  = c.dfr.FluxElement.NpEdge
+			Q_Face [4]utils.Matrix
+			normal = [2]float64{0.6, 0.8}
+		)
+		for n := 0; n < 4; n++ {
+			Q_Face[n] = utils.NewMatrix(3*Nedge, 1)
+		}
+		for _, vn := range []float64{0.2, -0.2} {
+			var (
+				u, v = vn*normal[0] - 0.3*normal[1], vn*normal[1] + 0.3*normal[0]
+				QInt = [4]float64{1.1, 1.1 * u, 1.1 * v, 1.2/GM1 + 0.55*(u*u+v*v)}
+			)
+			for i := 0; i < Nedge; i++ {
+				for n := 0; n < 4; n++ {
+					Q_Face[n].DataP[i+Nedge] = QInt[n]
+				}
+			}
+			c.SlipBC(0, 1, Nedge, Q_Face, normal)
+			for i := 0; i < Nedge; i++ {
+				Q := [4]float64{Q_Face[0].DataP[i+Nedge], Q_Face[1].DataP[i+Nedge], Q_Face[2].DataP[i+Nedge],
+					Q_Face[3].DataP[i+Nedge]}
+				assert.InDelta(t, 0, Q[1]*normal[0]+Q[2]*normal[1], tol)
+				assert.InDelta(t, 0.3, (-Q[1]*normal[1]+Q[2]*normal[0])/Q[0], tol)
+				assert.InDelta(t, c.FSFar.GetFlowFunctionQQ(QInt, Entropy), c.FSFar.GetFlowFunctionQQ(Q, Entropy), tol)
+				// Flow into the plane compresses, flow away expands
+				if vn > 0 {
+					assert.Greater(t, c.FSFar.GetFlowFunctionQQ(Q, StaticPressure), 1.2)
+				} else {
+					assert.Less(t, c.FSFar.GetFlowFunctionQQ(Q, StaticPressure), 1.2)
+				}
+			}
+		}
+	}
+	// The freestream along the channel is preserved by all three BCs
+	rk := c.NewRungeKuttaSSP()
+	defer rk.Close()
+	for i := 0; i < 5; i++ {
+		rk.Step(c)
+		rk.StepCount++
+	}
+	Q := c.Q[0]
+	for ind := range Q[0].DataP {
+		for n := 0; n < 4; n++ {
+			assert.InDelta(t, c.FSFar.Qinf[n], Q[n].DataP[ind], 1.e-8)
+		}
+	}
+}
+
 func TestEdges(t *testing.T) {
 	dfr := DG2D.NewDFR2D(1, false, false, "../../DG2D/test_tris_9.neu")
 	assert.Equal(t, len(dfr.Tris.Edges), 19)
@@ -712,6 +1283,7 @@ func TestEuler_GetSolutionGradientUsingRTElement(t *testing.T) {
 		c := NewEuler(&ip, "../../DG2D/test_tris_9.neu", 1,
 			false, false, false)
 		rk := c.NewRungeKuttaSSP()
+		defer rk.Close()
 		myThread := 0
 		var (
 			dfr                      = c.dfr
@@ -792,6 +1364,1234 @@ BCs:
 	input.Print()
 	assert.Equal(t, input.FinalTime, 4.)
 }
+func TestCheckpoint(t *testing.T) {
+	var (
+		err error
+		tol = 1.e-12
+	)
+	ip := *ipDefault
+	ip.InitType = "ivortex"
+	ip.PolynomialOrder = 2
+	ip.FinalTime = 10
+	dir, err := ioutil.TempDir("", "gocfd-checkpoint")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	cpFile := filepath.Join(dir, "test.gob")
+	// Advance the solution a few steps on one partition and checkpoint it
+	c1 := NewEuler(&ip, "../../DG2D/vortex-new.su2", 1, false, false, false)
+	rk1 := c1.NewRungeKuttaSSP()
+	defer rk1.Close()
+	for i := 0; i < 3; i++ {
+		rk1.Step(c1)
+		rk1.Time += rk1.GlobalDT
+		rk1.StepCount++
+	}
+	assert.Nil(t, c1.WriteCheckpoint(cpFile, rk1))
+	// Restart on a different number of partitions, the state should be resharded correctly
+	c2 := NewEuler(&ip, "../../DG2D/vortex-new.su2", 3, false, false, false)
+	assert.Equal(t, 3, c2.Partitions.ParallelDegree)
+	rk2 := c2.NewRungeKuttaSSP()
+	defer rk2.Close()
+	cp, err := ReadCheckpoint(cpFile)
+	assert.Nil(t, err)
+	assert.Nil(t, c2.RestoreCheckpoint(cp, rk2))
+	assert.Equal(t, rk1.Time, rk2.Time)
+	assert.Equal(t, rk1.StepCount, rk2.StepCount)
+	assert.Equal(t, ip, cp.Parameters)
+	Q1, Q2 := c1.RecombineShardsKBy4(c1.Q), c2.RecombineShardsKBy4(c2.Q)
+	for n := 0; n < 4; n++ {
+		assert.Equal(t, Q1[n].DataP, Q2[n].DataP)
+	}
+	// The restarted solution should continue exactly as the original when using the same partitions
+	c4 := NewEuler(&ip, "../../DG2D/vortex-new.su2", 1, false, false, false)
+	rk4 := c4.NewRungeKuttaSSP()
+	defer rk4.Close()
+	assert.Nil(t, c4.RestoreCheckpoint(cp, rk4))
+	rk1.Step(c1)
+	rk4.Step(c4)
+	assert.Equal(t, rk1.GlobalDT, rk4.GlobalDT)
+	Q1, Q2 = c1.RecombineShardsKBy4(c1.Q), c4.RecombineShardsKBy4(c4.Q)
+	for n := 0; n < 4; n++ {
+		assert.InDeltaSlicef(t, Q1[n].DataP, Q2[n].DataP, tol, "Q[%d] differs after the restart", n)
+	}
+	// A checkpoint can not be restored on a solver with a different polynomial order
+	ip.PolynomialOrder = 1
+	c3 := NewEuler(&ip, "../../DG2D/vortex-new.su2", 1, false, false, false)
+	rk3 := c3.NewRungeKuttaSSP()
+	defer rk3.Close()
+	assert.NotNil(t, c3.RestoreCheckpoint(cp, rk3))
+}
+
+func TestWorkerPool(t *testing.T) {
+	var (
+		NWorkers    = 2*runtime.GOMAXPROCS(0) + 3 // More workers than processors, so waiting workers yield
+		generations = 1000
+		workers     = func() int {
+			// Goroutines started by a pool, whether or not they have been scheduled yet
+			buf := make([]byte, 1<<20)
+			return strings.Count(string(buf[:runtime.Stack(buf, true)]), "created by "+
+				"github.com/notargets/gocfd/model_problems/Euler2D.NewWorkerPool")
+		}
+		waitForExit = func() {
+			// Closed workers exit after Close returns
+			for i := 0; i < 1000 && workers() > 0; i++ {
+				time.Sleep(time.Millisecond)
+			}
+		}
+	)
+	// The pools closed by the earlier tests may still be exiting
+	waitForExit()
+	assert.Equal(t, 0, workers())
+	wp := NewWorkerPool(NWorkers)
+	assert.Equal(t, NWorkers-1, workers())
+	{ // The barrier is reused across generations, no worker leaves it before all of them have arrived
+		var (
+			arrived = make([]int32, generations)
+			early   int32
+		)
+		wp.Run(func(myThread int) {
+			for g := 0; g < generations; g++ {
+				atomic.AddInt32(&arrived[g], 1)
+				wp.Barrier.Wait()
+				if atomic.LoadInt32(&arrived[g]) != int32(NWorkers) {
+					atomic.AddInt32(&early, 1)
+				}
+			}
+		})
+		all := make([]int32, generations)
+		for g := range all {
+			all[g] = int32(NWorkers)
+		}
+		assert.Equal(t, int32(0), early)
+		assert.Equal(t, all, arrived)
+	}
+	{ // ReduceMax returns the serial maximum over the shards to every shard, in every generation
+		var (
+			value = func(g, np int) float64 { return math.Sin(1.3 * float64(g*NWorkers+np)) }
+			maxes = make([][]float64, NWorkers)
+		)
+		wp.Run(func(myThread int) {
+			maxes[myThread] = make([]float64, generations)
+			for g := 0; g < generations; g++ {
+				maxes[myThread][g] = wp.Barrier.ReduceMax(myThread, value(g, myThread))
+			}
+		})
+		for g := 0; g < generations; g++ {
+			max := value(g, 0)
+			for np := 1; np < NWorkers; np++ {
+				max = math.Max(max, value(g, np))
+			}
+			for np := 0; np < NWorkers; np++ {
+				if maxes[np][g] != max {
+					assert.Equalf(t, max, maxes[np][g], "generation %d shard %d", g, np)
+				}
+			}
+		}
+	}
+	// Close stops the workers
+	wp.Close()
+	waitForExit()
+	assert.Equal(t, 0, workers())
+	{ // Spawning goroutines for every substep, as before the persistent workers, computes the same steps
+		ip := *ipDefault
+		ip.Minf = 0.5
+		ip.PolynomialOrder = 2
+		var Q [2][4]utils.Matrix
+		for i, spawn := range []bool{false, true} {
+			c := NewEuler(&ip, "../../DG2D/test_tris_9.neu", 3, false, false, false)
+			rk := c.NewRungeKuttaSSP()
+			rk.Pool.Spawn = spawn
+			for step := 0; step < 5; step++ {
+				rk.Step(c)
+				rk.Time += rk.GlobalDT
+				rk.StepCount++
+			}
+			rk.Close()
+			assert.True(t, rk.GlobalDT > 0)
+			Q[i] = c.RecombineShardsKBy4(c.Q)
+		}
+		for n := 0; n < 4; n++ {
+			assert.Equal(t, Q[0][n].DataP, Q[1][n].DataP)
+		}
+	}
+}
+
+func TestTimeIntegrators(t *testing.T) {
+	assert.Equal(t, RK_SSP54, NewTimeIntegratorType(""))
+	assert.Equal(t, RK_Classic4, NewTimeIntegratorType(" RK4 "))
+	assert.Equal(t, RK_LowStorage4, NewTimeIntegratorType("LSRK4"))
+	assert.Panics(t, func() { NewTimeIntegratorType("rk7") })
+	var (
+		tiTypes = []TimeIntegratorType{RK_SSP54, RK_ForwardEuler, RK_SSP2, RK_SSP3, RK_Classic4, RK_LowStorage4}
+		labels  = []string{"SSP54", "Euler", "SSP-RK2", "SSP-RK3", "RK4", "LSRK4"}
+		orders  = []float64{4, 1, 2, 3, 4, 4}
+	)
+	// Integrate du/dt = lambda*u to T = 1 and measure the order of accuracy from the error at two time steps
+	solveODE := func(integrator TimeIntegrator, nSteps int) (err float64) {
+		var (
+			lambda = -1.5
+			dt     = 1. / float64(nSteps)
+			U      = make([][]float64, 1+integrator.NumRegisters())
+			R      = make([]float64, 1)
+			dtRHS  = make([]float64, 1)
+		)
+		for j := range U {
+			U[j] = make([]float64, 1)
+		}
+		U[0][0] = 1
+		for step := 0; step < nSteps; step++ {
+			u0 := U[0][0]
+			for stage := 0; stage < integrator.NumStages(); stage++ {
+				dtRHS[0] = dt * lambda * U[integrator.StageInput(stage)][0]
+				integrator.Advance(stage, U, R, dtRHS)
+			}
+			assert.InDelta(t, U[0][0]-u0, R[0], 1.e-15) // R holds the change over the step
+		}
+		return math.Abs(U[0][0] - math.Exp(lambda))
+	}
+	for i, ti := range tiTypes {
+		assert.Equal(t, ti, NewTimeIntegratorType(labels[i]))
+		integrator := NewTimeIntegrator(ti)
+		assert.Equal(t, ti.Print(), integrator.Print())
+		e1, e2 := solveODE(integrator, 20), solveODE(integrator, 40)
+		assert.InDelta(t, orders[i], math.Log2(e1/e2), 0.2, ti.Print())
+	}
+	// Each integrator advances the isentropic vortex, the solutions agree to within the time discretization error
+	ip := *ipDefault
+	ip.InitType = "ivortex"
+	ip.PolynomialOrder = 2
+	ip.CFL = 0.5
+	var QRef [4]utils.Matrix
+	for i, ti := range tiTypes {
+		ip.TimeIntegrator = labels[i]
+		if ti == RK_ForwardEuler {
+			continue // Not stable for this discretization
+		}
+		c := NewEuler(&ip, "../../DG2D/vortex-new.su2", 2, false, false, false)
+		rk := c.NewRungeKuttaSSP()
+		defer rk.Close()
+		assert.Equal(t, NewTimeIntegrator(ti).NumRegisters(), len(rk.Registers))
+		for step := 0; step < 5; step++ {
+			rk.Step(c)
+			rk.Time += rk.GlobalDT
+			rk.StepCount++
+		}
+		Q := c.RecombineShardsKBy4(c.Q)
+		if i == 0 {
+			QRef = Q
+			continue
+		}
+		for n := 0; n < 4; n++ {
+			for ind := range Q[n].DataP {
+				assert.False(t, math.IsNaN(Q[n].DataP[ind]))
+			}
+			assert.InDeltaSlice(t, QRef[n].DataP, Q[n].DataP, 1.e-3, ti.Print())
+		}
+	}
+}
+
+func TestPartitioner(t *testing.T) {
+	assert.Equal(t, PARTITION_Contiguous, NewPartitionerType(""))
+	assert.Equal(t, PARTITION_Inertial, NewPartitionerType(" Inertial "))
+	assert.Panics(t, func() { NewPartitionerType("metis") })
+	var (
+		tol    = 1.e-12
+		meshFn = "../../DG2D/vortex-new.su2"
+	)
+	ip := *ipDefault
+	ip.InitType = "ivortex"
+	ip.PolynomialOrder = 2
+	ip.FinalTime = 10
+	c1 := NewEuler(&ip, meshFn, 4, false, false, false)
+	ip.Partitioner = "inertial"
+	c2 := NewEuler(&ip, meshFn, 4, false, false, false)
+	assert.Nil(t, c1.dfr.ElementOrder)
+	// The renumbering is a permutation of the mesh file elements with the same geometry
+	X1, Y1 := c1.dfr.ElementCentroids()
+	X2, Y2 := c2.dfr.ElementCentroids()
+	seen := make(map[int]bool)
+	for k, kFile := range c2.dfr.ElementOrder {
+		seen[kFile] = true
+		assert.InDelta(t, X1[kFile], X2[k], tol)
+		assert.InDelta(t, Y1[kFile], Y2[k], tol)
+		assert.InDelta(t, c1.dfr.Jdet.DataP[kFile], c2.dfr.Jdet.DataP[k], tol)
+	}
+	assert.Equal(t, c1.dfr.K, len(seen))
+	// The inertial partitions have the same sizes and cut fewer edges than the mesh file numbering
+	cut1, nEdges1, imbalance1 := c1.PartitionStatistics()
+	cut2, nEdges2, imbalance2 := c2.PartitionStatistics()
+	assert.Equal(t, nEdges1, nEdges2)
+	assert.Equal(t, imbalance1, imbalance2)
+	assert.Less(t, cut2, cut1)
+	/*
+		The time derivative of the Euler equations does not depend on the element numbering. The time step does, as the
+		wave speed of an edge is evaluated using the first element connected to the edge, so a forward Euler step is
+		compared divided by dT. The artificial dissipation is removed, its one sided (alternating) edge fluxes also
+		depend on which element is first on each edge.
+	*/
+	ip.TimeIntegrator = "euler"
+	ip.Partitioner = ""
+	c1 = NewEuler(&ip, meshFn, 4, false, false, false)
+	ip.Partitioner = "inertial"
+	c2 = NewEuler(&ip, meshFn, 4, false, false, false)
+	c1.Dissipation, c2.Dissipation = nil, nil
+	Q01, Q02 := c1.RecombineShardsKBy4(c1.Q), c2.RecombineShardsKBy4(c2.Q)
+	rk1, rk2 := c1.NewRungeKuttaSSP(), c2.NewRungeKuttaSSP()
+	defer rk1.Close()
+	defer rk2.Close()
+	rk1.Step(c1)
+	rk2.Step(c2)
+	Q1, Q2 := c1.RecombineShardsKBy4(c1.Q), c2.RecombineShardsKBy4(c2.Q)
+	for n := 0; n < 4; n++ {
+		dQ1 := Q1[n].Subtract(Q01[n]).Scale(1. / rk1.GlobalDT)
+		dQ2 := c2.toMeshFileOrder(Q2[n].Subtract(Q02[n]).Scale(1. / rk2.GlobalDT))
+		assert.InDeltaSlicef(t, dQ1.DataP, dQ2.DataP, 1.e-10, "dQ/dt[%d] differs between the serial and inertial partitions", n)
+	}
+	// A checkpoint written by the renumbered solver restores in the mesh file order
+	dir, err := ioutil.TempDir("", "gocfd-partition")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	cpFile := filepath.Join(dir, "test.gob")
+	assert.Nil(t, c2.WriteCheckpoint(cpFile, rk2))
+	cp, err := ReadCheckpoint(cpFile)
+	assert.Nil(t, err)
+	ip.Partitioner = ""
+	c3 := NewEuler(&ip, meshFn, 1, false, false, false)
+	rk3 := c3.NewRungeKuttaSSP()
+	defer rk3.Close()
+	assert.Nil(t, c3.RestoreCheckpoint(cp, rk3))
+	Q2, Q3 := c2.RecombineShardsKBy4(c2.Q), c3.RecombineShardsKBy4(c3.Q)
+	for n := 0; n < 4; n++ {
+		assert.Equal(t, c2.toMeshFileOrder(Q2[n]).DataP, Q3[n].DataP)
+	}
+}
+
+func TestMeshOrdering(t *testing.T) {
+	var (
+		meshFn = "../../DG2D/vortex-new.su2"
+	)
+	ip := *ipDefault
+	ip.InitType = "ivortex"
+	ip.PolynomialOrder = 2
+	ip.FinalTime = 10
+	ip.TimeIntegrator = "euler"
+	// Forward Euler time derivative in the mesh file element order, without the numbering dependent dissipation
+	getDQDT := func(ordering, partitioner string, ProcLimit int) (dQ [4]utils.Matrix) {
+		ip.MeshOrdering, ip.Partitioner = ordering, partitioner
+		c := NewEuler(&ip, meshFn, ProcLimit, false, false, false)
+		c.Dissipation = nil
+		Q0 := c.RecombineShardsKBy4(c.Q)
+		rk := c.NewRungeKuttaSSP()
+		defer rk.Close()
+		rk.Step(c)
+		Q := c.RecombineShardsKBy4(c.Q)
+		for n := 0; n < 4; n++ {
+			dQ[n] = c.toMeshFileOrder(Q[n].Subtract(Q0[n]).Scale(1. / rk.GlobalDT))
+		}
+		return
+	}
+	dQFile := getDQDT("", "", 1)
+	for _, test := range []struct {
+		ordering, partitioner string
+		ProcLimit             int
+	}{{"rcm", "", 1}, {"hilbert", "", 2}, {"rcm", "inertial", 3}} {
+		dQ := getDQDT(test.ordering, test.partitioner, test.ProcLimit)
+		for n := 0; n < 4; n++ {
+			assert.InDeltaSlicef(t, dQFile[n].DataP, dQ[n].DataP, 1.e-10, "dQ/dt[%d] differs for the %s ordering", n, test.ordering)
+		}
+	}
+	// VTK output is written in the mesh file element order
+	dir, err := ioutil.TempDir("", "gocfd-ordering")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	var vtu [2][]byte
+	for i, ordering := range []string{"", "rcm"} {
+		ip.MeshOrdering, ip.Partitioner = ordering, ""
+		c := NewEuler(&ip, meshFn, 1, false, false, false)
+		fileName := filepath.Join(dir, fmt.Sprintf("ordering%d.vtu", i))
+		assert.Nil(t, c.WriteVTU(fileName, c.NewVTKOutput("", nil)))
+		vtu[i], err = ioutil.ReadFile(fileName)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, vtu[0], vtu[1])
+}
+
+func TestVTKOutput(t *testing.T) {
+	// VTK Lagrange triangle node ordering: corners, edges counter clockwise, then interior recursively
+	assert.Equal(t, [][2]int{{0, 0}, {1, 0}, {0, 1}}, VTKLagrangeTriangleNodes(1))
+	assert.Equal(t, [][2]int{{0, 0}, {3, 0}, {0, 3}, {1, 0}, {2, 0}, {2, 1}, {1, 2}, {0, 2}, {0, 1}, {1, 1}},
+		VTKLagrangeTriangleNodes(3))
+	nodes := VTKLagrangeTriangleNodes(4)
+	assert.Equal(t, 15, len(nodes))
+	assert.Equal(t, [][2]int{{1, 1}, {2, 1}, {1, 2}}, nodes[12:])
+
+	ip := *ipDefault
+	ip.InitType = "ivortex"
+	ip.PolynomialOrder = 2
+	dir, err := ioutil.TempDir("", "gocfd-vtk")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	c := NewEuler(&ip, "../../DG2D/vortex-new.su2", 2, false, false, false)
+	vo := c.NewVTKOutput(filepath.Join(dir, "vortex"), []FlowFunction{Mach, StaticPressure, Entropy})
+	assert.Nil(t, c.WriteVTKStep(vo, 0, 0))
+	assert.Nil(t, c.WriteVTKStep(vo, 0.5, 10))
+	// Parse the output and check the dimensions and content
+	type dataArray struct {
+		Name string `xml:"Name,attr"`
+		Data string `xml:",chardata"`
+	}
+	var vtu struct {
+		Piece struct {
+			NumberOfPoints int         `xml:"NumberOfPoints,attr"`
+			NumberOfCells  int         `xml:"NumberOfCells,attr"`
+			Points         dataArray   `xml:"Points>DataArray"`
+			Cells          []dataArray `xml:"Cells>DataArray"`
+			PointData      []dataArray `xml:"PointData>DataArray"`
+		} `xml:"UnstructuredGrid>Piece"`
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "vortex_000010.vtu"))
+	assert.Nil(t, err)
+	assert.Nil(t, xml.Unmarshal(data, &vtu))
+	Kmax, Npts := c.dfr.K, 6
+	assert.Equal(t, Kmax, vtu.Piece.NumberOfCells)
+	assert.Equal(t, Kmax*Npts, vtu.Piece.NumberOfPoints)
+	assert.Equal(t, 3, len(vtu.Piece.Cells))
+	assert.Equal(t, []string{"Density", "XMomentum", "YMomentum", "Energy", "Mach", "StaticPressure", "Entropy"},
+		func() (names []string) {
+			for _, da := range vtu.Piece.PointData {
+				names = append(names, da.Name)
+			}
+			return
+		}())
+	// The first node of each cell is the first vertex of the element
+	coords := strings.Fields(vtu.Piece.Points.Data)
+	assert.Equal(t, 3*Kmax*Npts, len(coords))
+	for k := 0; k < Kmax; k++ {
+		verts := c.dfr.Tris.GetTriVerts(uint32(k))
+		x, _ := strconv.ParseFloat(coords[3*k*Npts], 64)
+		y, _ := strconv.ParseFloat(coords[3*k*Npts+1], 64)
+		assert.InDeltaf(t, c.dfr.VX.DataP[verts[0]], x, 1.e-12, "k = %d", k)
+		assert.InDeltaf(t, c.dfr.VY.DataP[verts[0]], y, 1.e-12, "k = %d", k)
+	}
+	// Density is a quadratic interpolant of the vortex solution, it should be close to the exact density
+	rho := strings.Fields(vtu.Piece.PointData[0].Data)
+	assert.Equal(t, Kmax*Npts, len(rho))
+	for i, r := range rho {
+		val, _ := strconv.ParseFloat(r, 64)
+		x, _ := strconv.ParseFloat(coords[3*i], 64)
+		y, _ := strconv.ParseFloat(coords[3*i+1], 64)
+		rhoE, _, _, _ := c.AnalyticSolution.GetStateC(0, x, y)
+		assert.InDelta(t, rhoE, val, 0.05)
+	}
+	// The collection lists both files in order
+	data, err = ioutil.ReadFile(filepath.Join(dir, "vortex.pvd"))
+	assert.Nil(t, err)
+	var pvd struct {
+		DataSets []struct {
+			Time float64 `xml:"timestep,attr"`
+			File string  `xml:"file,attr"`
+		} `xml:"Collection>DataSet"`
+	}
+	assert.Nil(t, xml.Unmarshal(data, &pvd))
+	assert.Equal(t, 2, len(pvd.DataSets))
+	assert.Equal(t, "vortex_000000.vtu", pvd.DataSets[0].File)
+	assert.Equal(t, 0.5, pvd.DataSets[1].Time)
+}
+
+func TestRender(t *testing.T) {
+	assert.Equal(t, IMAGE_PNG, NewImageFormat(""))
+	assert.Equal(t, IMAGE_GIF, NewImageFormat("GIF"))
+	assert.Panics(t, func() { NewImageFormat("jpeg") })
+	// The color map spans the palette and clamps outside of the field range
+	assert.Equal(t, uint8(paletteFirstColor), colorIndex(-1, 0, 1))
+	assert.Equal(t, uint8(paletteFirstColor+paletteColors-1), colorIndex(2, 0, 1))
+	assert.Equal(t, uint8(paletteFirstColor), colorIndex(1, 1, 1))
+
+	ip := *ipDefault
+	ip.InitType = "ivortex"
+	ip.PolynomialOrder = 2
+	dir, err := ioutil.TempDir("", "gocfd-render")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	c := NewEuler(&ip, "../../DG2D/vortex-new.su2", 2, false, false, false)
+	pm := &PlotMeta{Field: Density, Scale: 1, FrameTime: 50 * time.Millisecond}
+	{ // PNG frames, one file per step
+		fr := NewFrameRenderer(filepath.Join(dir, "vortex"), IMAGE_PNG, 320, 200, true)
+		assert.Nil(t, c.WriteFrame(pm, fr, 10))
+		f, err := os.Open(filepath.Join(dir, "vortex_000010.png"))
+		assert.Nil(t, err)
+		img, err := png.Decode(f)
+		_ = f.Close()
+		assert.Nil(t, err)
+		assert.Equal(t, image.Rect(0, 0, 320, 200), img.Bounds())
+		// The wide image leaves background on the sides of the square domain, the field and mesh fill the middle
+		var nMesh, nField int
+		pal := img.(*image.Paletted)
+		for j := 0; j < 200; j++ {
+			for i := 0; i < 320; i++ {
+				switch ind := pal.ColorIndexAt(i, j); {
+				case ind == paletteMesh:
+					nMesh++
+				case ind >= paletteFirstColor:
+					nField++
+				}
+			}
+		}
+		assert.Equal(t, uint8(paletteBackground), pal.ColorIndexAt(0, 100))
+		assert.Equal(t, uint8(paletteBackground), pal.ColorIndexAt(319, 100))
+		assert.True(t, nMesh > 0)
+		assert.True(t, nField > 0)
+		assert.True(t, nMesh+nField <= 200*200+2*200)
+	}
+	{ // Animated GIF, written on close
+		fr := NewFrameRenderer(filepath.Join(dir, "vortex"), IMAGE_GIF, 160, 160, false)
+		for i := 0; i < 3; i++ {
+			assert.Nil(t, c.WriteFrame(pm, fr, i))
+		}
+		assert.Nil(t, fr.Close())
+		f, err := os.Open(filepath.Join(dir, "vortex.gif"))
+		assert.Nil(t, err)
+		anim, err := gif.DecodeAll(f)
+		_ = f.Close()
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(anim.Image))
+		assert.Equal(t, []int{5, 5, 5}, anim.Delay)
+		assert.Equal(t, image.Rect(0, 0, 160, 160), anim.Image[0].Bounds())
+	}
+	{ // The color at the center of each output mesh triangle is the color map value of the interpolated field
+		fr := NewFrameRenderer(filepath.Join(dir, "colors"), IMAGE_PNG, 800, 800, false)
+		img := c.RenderFrame(pm, fr)
+		var (
+			oField     = c.GetPlotField(c.RecombineShardsKBy4(c.Q), pm.Field)
+			fI         = c.dfr.ConvertScalarToOutputMesh(oField)
+			fmin, fmax = oField.Min(), oField.Max()
+			colors     = make(map[uint8]bool)
+			nChecked   int
+		)
+		for _, tri := range fr.tris {
+			var px, py, f [3]float64
+			for i, node := range tri {
+				px[i], py[i] = fr.toPixel(fr.nodeX[node], fr.nodeY[node])
+				f[i] = float64(fI[node])
+			}
+			i, j := int(math.Floor((px[0]+px[1]+px[2])/3)), int(math.Floor((py[0]+py[1]+py[2])/3))
+			x, y := float64(i)+0.5, float64(j)+0.5
+			area := (px[1]-px[0])*(py[2]-py[0]) - (px[2]-px[0])*(py[1]-py[0])
+			l0 := ((px[1]-x)*(py[2]-y) - (px[2]-x)*(py[1]-y)) / area
+			l1 := ((px[2]-x)*(py[0]-y) - (px[0]-x)*(py[2]-y)) / area
+			l2 := 1 - l0 - l1
+			// Skip pixels on or near a shared edge, which may have been filled by the neighboring triangle
+			if l0 < 0.05 || l1 < 0.05 || l2 < 0.05 {
+				continue
+			}
+			ind := colorIndex(l0*f[0]+l1*f[1]+l2*f[2], fmin, fmax)
+			assert.Equal(t, ind, img.ColorIndexAt(i, j))
+			colors[ind] = true
+			nChecked++
+		}
+		// Most of the triangles are checked, and the vortex shows up as more than one color
+		assert.True(t, nChecked > len(fr.tris)/2)
+		assert.True(t, len(colors) > 10)
+	}
+}
+
+func TestAeroForces(t *testing.T) {
+	ip := *ipDefault
+	ip.PolynomialOrder = 1
+	ip.Minf = 0.5
+	ip.MomentCenter = [2]float64{0.25, 0}
+	c := NewEuler(&ip, "../../test_cases/Euler2D/naca_12/mesh/naca12_2d-medium-lal.su2", 2, false, false, false)
+	af := c.NewAeroForces(ip.MomentCenter, 1, "")
+	if !assert.NotNil(t, af) {
+		return
+	}
+	assert.Equal(t, []types.BCTAG{"wall"}, af.Tags)
+	// Edge points are Gauss-Legendre points
+	X, _ := DG1D.JacobiGQ(0, 0, c.dfr.FluxElement.NpEdge-1)
+	assert.InDeltaSlice(t, DG1D.LegendreZeros(c.dfr.FluxElement.NpEdge-1), X.DataP, 1.e-12)
+	SX, SY := c.ShardByK(c.dfr.SolutionX), c.ShardByK(c.dfr.SolutionY)
+	setPressure := func(pf func(x, y float64) float64) {
+		for np := 0; np < c.Partitions.ParallelDegree; np++ {
+			Np, Kmax := c.Q[np][0].Dims()
+			for k := 0; k < Kmax; k++ {
+				for i := 0; i < Np; i++ {
+					ind := k + i*Kmax
+					x, y := SX[np].DataP[ind], SY[np].DataP[ind]
+					c.Q[np][0].DataP[ind], c.Q[np][1].DataP[ind], c.Q[np][2].DataP[ind] = 1, 0, 0
+					c.Q[np][3].DataP[ind] = pf(x, y) / (c.FSFar.Gamma - 1.)
+				}
+			}
+		}
+	}
+	// A constant pressure produces no force or moment on a closed body
+	setPressure(func(x, y float64) float64 { return 2 * c.FSFar.Pinf })
+	c.CalculateForces(af)
+	assert.InDelta(t, 0, af.Total.CL, 1.e-10)
+	assert.InDelta(t, 0, af.Total.CD, 1.e-10)
+	assert.InDelta(t, 0, af.Total.CM, 1.e-10)
+	// A linear pressure field produces a force equal to the gradient times the body area, which is ~0.0822 for a
+	// NACA 0012 of unit chord
+	area := 0.0822
+	setPressure(func(x, y float64) float64 { return c.FSFar.Pinf + y })
+	c.CalculateForces(af)
+	assert.InDelta(t, -area/c.FSFar.QQinf, af.Total.CL, 0.02*area/c.FSFar.QQinf)
+	assert.InDelta(t, 0, af.Total.CD, 1.e-10)
+	assert.Equal(t, af.Total, af.Coefficients[0])
+	setPressure(func(x, y float64) float64 { return c.FSFar.Pinf + x })
+	c.CalculateForces(af)
+	assert.InDelta(t, -area/c.FSFar.QQinf, af.Total.CD, 0.02*area/c.FSFar.QQinf)
+	assert.InDelta(t, 0, af.Total.CL, 1.e-10)
+	// Surface distribution along the ordered wall curve
+	pts, err := c.GetSurfaceDistribution("wall")
+	assert.Nil(t, err)
+	assert.Equal(t, c.dfr.FluxElement.NpEdge*len(c.dfr.BCEdges["wall"]), len(pts))
+	for i, pt := range pts {
+		assert.InDelta(t, pt.X/c.FSFar.QQinf, pt.Cp, 1.e-10)
+		if i > 0 {
+			// Arc length increases by the distance between consecutive points on an edge, and by at least that distance
+			// between points on consecutive edges, where the path passes through the shared vertex
+			dist := math.Hypot(pt.X-pts[i-1].X, pt.Y-pts[i-1].Y)
+			if i%c.dfr.FluxElement.NpEdge != 0 {
+				assert.InDelta(t, dist, pt.S-pts[i-1].S, 1.e-10)
+			} else {
+				assert.True(t, pt.S-pts[i-1].S > dist-1.e-10)
+			}
+		}
+	}
+	// The perimeter of a NACA 0012 of unit chord is ~2.04
+	assert.InDelta(t, 2.04, pts[len(pts)-1].S, 0.02)
+	_, err = c.GetSurfaceDistribution("far")
+	assert.NotNil(t, err)
+	{ // A tag holding more than one connected curve is walked as one segment for each
+		var (
+			Nedge     = c.dfr.FluxElement.NpEdge
+			wall, far = types.Curve(c.dfr.BCEdges["wall"]), c.dfr.BCEdges["far"]
+			cc, _     = wall.ReOrder(false)
+			n         = len(cc)
+			perimeter float64
+		)
+		for _, e := range far {
+			perimeter += c.dfr.Tris.Edges[e.GetKey()].GetEdgeLength()
+		}
+		// Two closed loops, the airfoil and the far field
+		c.dfr.BCEdges["wall"] = append(append(types.Curve{}, wall...), far...)
+		pts2, err := c.GetSurfaceDistribution("wall")
+		assert.Nil(t, err)
+		assert.Equal(t, Nedge*(len(wall)+len(far)), len(pts2))
+		assert.Equal(t, pts, pts2[:len(pts)])
+		for _, pt := range pts2[len(pts):] {
+			assert.Equal(t, 1, pt.Segment)
+		}
+		assert.True(t, pts2[len(pts)].S < pts2[len(pts)-1].S)
+		assert.InDelta(t, perimeter, pts2[len(pts2)-1].S, 0.01*perimeter)
+		// Two open chains, the airfoil with two edges removed
+		c.dfr.BCEdges["wall"] = append(append(types.Curve{}, cc[1:n/2]...), cc[n/2+1:]...)
+		pts2, err = c.GetSurfaceDistribution("wall")
+		assert.Nil(t, err)
+		assert.Equal(t, Nedge*(n-2), len(pts2))
+		assert.Equal(t, 0, pts2[0].Segment)
+		assert.Equal(t, 1, pts2[len(pts2)-1].Segment)
+		// A branch off the curve can not be ordered
+		v := cc[0].GetVertices()
+		e := c.dfr.Tris.Edges[cc[0].GetKey()]
+		for _, vt := range c.dfr.Tris.GetTriVerts(e.ConnectedTris[0]) {
+			if vt != v[0] && vt != v[1] {
+				c.dfr.BCEdges["wall"] = append(append(types.Curve{}, wall...), types.NewEdgeInt([2]int{v[0], vt}))
+			}
+		}
+		_, err = c.GetSurfaceDistribution("wall")
+		assert.NotNil(t, err)
+		c.dfr.BCEdges["wall"] = wall
+	}
+	// The coefficients are not defined without a freestream dynamic pressure
+	ip.Minf = 0
+	c0 := NewEuler(&ip, "../../test_cases/Euler2D/naca_12/mesh/naca12_2d-medium-lal.su2", 2, false, false, false)
+	assert.Nil(t, c0.NewAeroForces(ip.MomentCenter, 1, ""))
+}
+
+func TestConvergence(t *testing.T) {
+	ip := *ipDefault
+	ip.ResidualReduction = 2
+	ip.ResidualNorm = "Res0"
+	cm := NewConvergenceMonitor(&ip)
+	assert.False(t, cm.Converged([6]float64{1, 1, 1, 1, 1, 1}))
+	assert.False(t, cm.Converged([6]float64{0.011, 0, 0, 0, 0, 0}))
+	assert.True(t, cm.Converged([6]float64{0.01, 1, 1, 1, 1, 1}))
+	ip.ResidualReduction = 0
+	ip.ResidualTarget = 1.e-6
+	ip.ResidualNorm = ""
+	cm = NewConvergenceMonitor(&ip)
+	assert.Equal(t, ResL2, cm.Norm)
+	assert.False(t, cm.Converged([6]float64{0, 0, 0, 0, 0, 2.e-6}))
+	assert.True(t, cm.Converged([6]float64{1, 1, 1, 1, 1, 1.e-6}))
+	ip.ResidualTarget = 0
+	assert.Nil(t, NewConvergenceMonitor(&ip))
+
+	// A freestream solution converges immediately, stopping the solver well before MaxIterations
+	dir, err := ioutil.TempDir("", "gocfd-convergence")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, ext := range []string{".csv", ".json"} {
+		ip = *ipDefault
+		ip.Minf = 0.5
+		ip.PolynomialOrder = 1
+		ip.LocalTimeStepping = true
+		ip.MaxIterations = 100
+		ip.ResidualTarget = 1.e-8
+		ip.ResidualHistoryFile = filepath.Join(dir, "history"+ext)
+		c := NewEuler(&ip, "../../DG2D/vortex-new.su2", 1, false, false, false)
+		c.Solve(&PlotMeta{StepsBeforePlot: 1000})
+		data, err := ioutil.ReadFile(ip.ResidualHistoryFile)
+		assert.Nil(t, err)
+		switch ext {
+		case ".csv":
+			lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+			assert.Equal(t, "iter,time,dt,Res0,Res1,Res2,Res3,L1,L2,limited,wallclock", lines[0])
+			assert.True(t, len(lines) > 1 && len(lines)-1 < ip.MaxIterations)
+		case ".json":
+			var recs []ResidualRecord
+			assert.Nil(t, json.Unmarshal(data, &recs))
+			assert.True(t, len(recs) > 0 && len(recs) < ip.MaxIterations)
+			assert.Equal(t, 1, recs[0].Iteration)
+			assert.True(t, recs[len(recs)-1].Residual[ResL2] <= ip.ResidualTarget)
+		}
+	}
+}
+
+func TestMonitor(t *testing.T) {
+	m, err := NewMonitor("127.0.0.1:0", 200, 100)
+	if err != nil {
+		panic(err)
+	}
+	defer m.Close()
+	url := "http://" + m.Address()
+	get := func(path string) (code int, body []byte) {
+		resp, err := http.Get(url + path)
+		if err != nil {
+			panic(err)
+		}
+		defer resp.Body.Close()
+		body, err = ioutil.ReadAll(resp.Body)
+		assert.Nil(t, err)
+		return resp.StatusCode, body
+	}
+	code, _ := get("/image.png")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	code, _ = get("/nothing")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	ip := *ipDefault
+	ip.InitType = "ivortex"
+	ip.PolynomialOrder = 1
+	ip.MaxIterations = 20
+	c := NewEuler(&ip, "../../DG2D/vortex-new.su2", 2, false, false, false)
+	pm := &PlotMeta{Field: Density, Scale: 1, StepsBeforePlot: 5, Monitor: m}
+	// Poll the monitor while the solver runs
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				code, body := get("/status")
+				assert.Equal(t, http.StatusOK, code)
+				var st MonitorStatus
+				assert.Nil(t, json.Unmarshal(body, &st))
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}()
+	c.Solve(pm)
+	close(done)
+	wg.Wait()
+
+	code, body := get("/status")
+	assert.Equal(t, http.StatusOK, code)
+	var st MonitorStatus
+	assert.Nil(t, json.Unmarshal(body, &st))
+	assert.Equal(t, ip.MaxIterations, st.Iteration)
+	assert.True(t, st.Finished)
+	assert.True(t, st.Time > 0 && st.DT > 0)
+	assert.True(t, st.Residual[ResL1] > 0)
+	assert.Equal(t, "Density", st.Field)
+	assert.Equal(t, c.dfr.K*c.dfr.SolutionElement.Np, st.SolutionPoints)
+	assert.True(t, st.Memory.SysMiB > 0)
+	code, body = get("/image.png")
+	assert.Equal(t, http.StatusOK, code)
+	img, err := png.Decode(bytes.NewReader(body))
+	assert.Nil(t, err)
+	assert.Equal(t, image.Rect(0, 0, 200, 100), img.Bounds())
+	assert.Nil(t, st.NonFinite)
+	{ // A diverging run still gets a status, with the non-finite values named
+		m.mu.Lock()
+		m.status.Residual[ResL1], m.status.DT = math.NaN(), math.Inf(1)
+		m.mu.Unlock()
+		code, body = get("/status")
+		assert.Equal(t, http.StatusOK, code)
+		var st MonitorStatus
+		assert.Nil(t, json.Unmarshal(body, &st))
+		assert.Equal(t, ip.MaxIterations, st.Iteration)
+		assert.Equal(t, map[string]string{fmt.Sprintf("residual[%d]", ResL1): "NaN", "dt": "+Inf"}, st.NonFinite)
+		assert.Equal(t, 0., st.Residual[ResL1])
+		assert.True(t, st.Residual[ResL2] > 0)
+	}
+}
+
+func TestSweep(t *testing.T) {
+	ss, err := ParseSweepSpec([]byte(`
+Alpha: {From: 4, To: -2, Step: -3}
+Minf: 0.5
+PolynomialOrder: [1, 2]
+WarmStart: true
+`))
+	assert.Nil(t, err)
+	assert.Equal(t, SweepRange{4, 1, -2}, ss.Alpha)
+	assert.Equal(t, SweepRange{0.5}, ss.Minf)
+	assert.Equal(t, []int{1, 2}, ss.PolynomialOrder)
+	assert.True(t, ss.WarmStart)
+	for _, bad := range []string{"Alpha: {From: 0, To: 4, Step: -1}", "Alpha: {From: 0, To: 4}", "Minf: fast"} {
+		_, err = ParseSweepSpec([]byte(bad))
+		assert.NotNil(t, err)
+	}
+	// Alpha varies fastest, unswept parameters come from the base input
+	ip := *ipDefault
+	ip.FluxType = "roe"
+	cases := ss.Cases(&ip)
+	assert.Equal(t, 6, len(cases))
+	assert.Equal(t, SweepCase{Minf: 0.5, Alpha: 1, PolynomialOrder: 1, FluxType: "roe"}, cases[1])
+	assert.Equal(t, SweepCase{Minf: 0.5, Alpha: 4, PolynomialOrder: 2, FluxType: "roe"}, cases[3])
+
+	// Cases of the same order share the geometry and start from the previous solution
+	ip.PolynomialOrder = 1
+	ip.Minf = 0.5
+	ip.LocalTimeStepping = true
+	ip.MaxIterations = 4
+	meshFile := "../../test_cases/Euler2D/naca_12/mesh/naca12_2d-medium-lal.su2"
+	c1 := NewEuler(&ip, meshFile, 2, false, false, false)
+	ip2 := SweepCase{Minf: 0.6, Alpha: 2, PolynomialOrder: 1, FluxType: "lax"}.InputParameters(&ip)
+	c2 := NewEulerSharingGeometry(ip2, c1, false, false)
+	assert.True(t, c1.dfr == c2.dfr)
+	assert.Equal(t, 0.6, c2.FSFar.Minf)
+	assert.Equal(t, FLUX_LaxFriedrichs, c2.FluxCalcAlgo)
+	c1.Q[0][3].DataP[0] = 10
+	c2.CopySolution(c1)
+	assert.Equal(t, 10., c2.Q[0][3].DataP[0])
+	ip2.PolynomialOrder = 2
+	assert.Panics(t, func() { NewEulerSharingGeometry(ip2, c1, false, false) })
+
+	dir, err := ioutil.TempDir("", "gocfd-sweep")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	ss = &SweepSpec{Alpha: SweepRange{0, 4}, PolynomialOrder: []int{1, 2}, WarmStart: true}
+	summary := filepath.Join(dir, "sweep.csv")
+	results, err := RunSweep(&ip, ss, meshFile, 2, &PlotMeta{StepsBeforePlot: 1000}, summary)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(results))
+	for i, sr := range results {
+		assert.Equal(t, i%2 == 1, sr.WarmStarted)
+		assert.Equal(t, ip.MaxIterations, sr.Result.Iteration)
+		assert.True(t, sr.Result.Residual[ResL2] > 0)
+	}
+	for _, sr := range results {
+		// The forces on the airfoil are taken from the end of each case
+		assert.NotEqual(t, ForceCoefficients{}, sr.Forces)
+	}
+	data, err := ioutil.ReadFile(summary)
+	assert.Nil(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Equal(t, 5, len(lines))
+	assert.True(t, strings.HasPrefix(lines[0], "case,Minf,Alpha,N,FluxType"))
+	assert.True(t, strings.HasPrefix(lines[4], "4,0.500000,4.000000,2,Roe,true,false,4,"))
+}
+
+func TestConvergenceStudy(t *testing.T) {
+	ip := *ipDefault
+	ip.InitType = "ivortex"
+	ip.PolynomialOrder = 2
+	ip.FinalTime = 0.1
+	// The solution is initialized from the exact solution at the solution points
+	c := NewEuler(&ip, "../../DG2D/vortex-new.su2", 2, false, false, false)
+	se := c.GetSolutionError(c.AnalyticSolution, 0)
+	assert.Equal(t, SolutionError{Area: se.Area}, se)
+	// A constant error has the same value in all norms, and the area is integrated exactly
+	var area float64
+	for k := 0; k < c.dfr.K; k++ {
+		v := c.dfr.Tris.GetTriVerts(uint32(k))
+		x, y := c.dfr.VX.DataP, c.dfr.VY.DataP
+		area += 0.5 * math.Abs((x[v[1]]-x[v[0]])*(y[v[2]]-y[v[0]])-(x[v[2]]-x[v[0]])*(y[v[1]]-y[v[0]]))
+	}
+	assert.InDelta(t, area, se.Area, 1.e-10*area)
+	for np := range c.Q {
+		c.Q[np][3].AddScalar(0.01)
+	}
+	se = c.GetSolutionError(c.AnalyticSolution, 0)
+	assert.InDeltaSlice(t, []float64{0, 0, 0, 0.01}, se.L1[:], 1.e-12)
+	assert.InDeltaSlice(t, []float64{0, 0, 0, 0.01}, se.L2[:], 1.e-12)
+	assert.InDeltaSlice(t, []float64{0, 0, 0, 0.01}, se.Linf[:], 1.e-12)
+
+	// Compressed meshes are read from a temporary file
+	readFile, cleanup := uncompressedMeshFile("../../test_cases/Grid/Euler2D/vortexA04.neu.bz2")
+	assert.Equal(t, "vortexA04.neu", filepath.Base(readFile))
+	_, err := os.Stat(readFile)
+	assert.Nil(t, err)
+	cleanup()
+	_, err = os.Stat(readFile)
+	assert.True(t, os.IsNotExist(err))
+
+	// The error decreases at about the order of accuracy on a refined mesh
+	geom := "../../test_cases/Euler2D/isentropic-vortex/geometry/"
+	results := RunConvergenceStudy(&ip, []string{geom + "vnew-coarse.su2", geom + "vnew.su2"}, nil, 2,
+		&PlotMeta{StepsBeforePlot: 100000})
+	assert.Equal(t, 2, len(results))
+	assert.True(t, results[1].H < results[0].H)
+	for _, r := range results {
+		assert.InDelta(t, ip.FinalTime, r.Time, 1.e-12)
+	}
+	p := ObservedOrders(results)[2]
+	for n := 0; n < 4; n++ {
+		assert.True(t, p.L2[n] > 1.5)
+	}
+	dir, err := ioutil.TempDir("", "gocfd-convergence-study")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	csvFile := filepath.Join(dir, "study.csv")
+	assert.Nil(t, WriteConvergenceStudyCSV(csvFile, "vortex", results))
+	data, err := ioutil.ReadFile(csvFile)
+	assert.Nil(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Equal(t, 3, len(lines))
+	fields := strings.Split(lines[2], ",")
+	assert.Equal(t, 10, len(fields))
+	assert.Equal(t, []string{"\"vortex\"", strconv.Itoa(int(math.Round(math.Sqrt(float64(2402 * 6))))), "2", "1"},
+		fields[:4])
+	log10RMS, err := strconv.ParseFloat(fields[4], 64)
+	assert.Nil(t, err)
+	assert.InDelta(t, math.Log10(results[1].Error.L2[0]), log10RMS, 1.e-6)
+}
+
+func TestManufacturedSolution(t *testing.T) {
+	// The source terms are the divergence of the flux of the manufactured solution
+	for mt := range ManufacturedSolutionPrintNames {
+		var (
+			ms = NewManufacturedSolution(ManufacturedSolutionType(mt), 1.4, -1, -2, 3)
+			h  = 1.e-5
+		)
+		flux := func(x, y float64) (Fx, Fy [4]float64) {
+			Q := ms.conservedAt(x, y)
+			return isentropic_vortex.FluxCalc(ms.Gamma, Q[0], Q[1], Q[2], Q[3])
+		}
+		for _, pt := range [][2]float64{{0, 0}, {1.3, -0.4}, {-0.7, 0.9}} {
+			x, y := pt[0], pt[1]
+			FxP, _ := flux(x+h, y)
+			FxM, _ := flux(x-h, y)
+			_, FyP := flux(x, y+h)
+			_, FyM := flux(x, y-h)
+			div := ms.GetDivergence(0, x, y)
+			for n := 0; n < 4; n++ {
+				assert.InDeltaf(t, (FxP[n]-FxM[n]+FyP[n]-FyM[n])/(2*h), div[n], 1.e-7, "%s: n = %d",
+					ManufacturedSolutionType(mt).Print(), n)
+			}
+		}
+	}
+
+	// The solution starts from the manufactured solution, with the exact state on all boundaries
+	grid := "../../test_cases/Grid/Euler2D/"
+	ip := *ipDefault
+	ip.InitType = "mms"
+	ip.PolynomialOrder = 2
+	ip.MaxIterations = 200
+	meshFile, cleanup := uncompressedMeshFile(grid + "Couette_K082.neu.bz2")
+	c := NewEuler(&ip, meshFile, 2, false, false, false)
+	cleanup()
+	assert.Equal(t, SolutionError{Area: c.GetSolutionError(c.AnalyticSolution, 0).Area},
+		c.GetSolutionError(c.AnalyticSolution, 0))
+	for _, e := range c.dfr.Tris.Edges {
+		if e.NumConnectedTris == 1 {
+			assert.Equal(t, types.BC_Exact, e.BCType)
+		}
+	}
+	assert.Equal(t, c.Partitions.ParallelDegree, len(c.SourceTerm))
+
+	/*
+		With the exact state on the inside of a boundary edge, every flux type gives the normal flux of the exact state,
+		without allocating. An unknown flux type is not silently skipped
+	*/
+	{
+		var (
+			Nedge, Nint = c.dfr.FluxElement.NpEdge, c.dfr.FluxElement.NpInt
+			e           *DG2D.Edge
+		)
+		for _, e = range c.dfr.Tris.Edges {
+			if e.NumConnectedTris == 1 {
+				break
+			}
+		}
+		var (
+			kGlobal           = int(e.ConnectedTris[0])
+			k, Kmax, myThread = c.Partitions.GetLocalK(kGlobal)
+			edgeNumber        = int(e.ConnectedTriEdgeNumber[0])
+			shift             = edgeNumber * Nedge
+			normal            = c.GetFaceNormal(kGlobal, edgeNumber)
+			X, Y              = c.dfr.FluxX.DataP, c.dfr.FluxY.DataP
+			Q_Face            [4]utils.Matrix
+			normalFlux        = make([][4]float64, Nedge)
+		)
+		for n := 0; n < 4; n++ {
+			Q_Face[n] = utils.NewMatrix(c.dfr.FluxElement.Np, Kmax)
+		}
+		for i := 0; i < Nedge; i++ {
+			indFlux := kGlobal + (2*Nint+i+shift)*c.dfr.K
+			ind := k + (i+shift)*Kmax
+			Q_Face[0].DataP[ind], Q_Face[1].DataP[ind], Q_Face[2].DataP[ind], Q_Face[3].DataP[ind] =
+				c.AnalyticSolution.GetStateC(0, X[indFlux], Y[indFlux])
+		}
+		for ft := FluxType(0); int(ft) < len(FluxPrintNames); ft++ {
+			c.FluxCalcAlgo = ft
+			allocs := testing.AllocsPerRun(10, func() {
+				c.ExactStateFlux(0, k, Kmax, myThread, shift, Q_Face, normal, normalFlux)
+			})
+			assert.Equalf(t, 0., allocs, "%s", ft.Print())
+			for i := 0; i < Nedge; i++ {
+				Fx, Fy := c.CalculateFlux(Q_Face, k+(i+shift)*Kmax)
+				for n := 0; n < 4; n++ {
+					assert.InDeltaf(t, normal[0]*Fx[n]+normal[1]*Fy[n], normalFlux[i][n], 1.e-10,
+						"%s: i = %d, n = %d", ft.Print(), i, n)
+				}
+			}
+		}
+		c.FluxCalcAlgo = FluxType(len(FluxPrintNames))
+		assert.Panics(t, func() {
+			c.ExactStateFlux(0, k, Kmax, myThread, shift, Q_Face, normal, normalFlux)
+		})
+	}
+
+	/*
+		The error decreases at about the order of accuracy N+1 for each flux. N = 1 is not checked, it is a known
+		instability of the solver that is not related to the source terms, the isentropic vortex also goes to NaN at
+		N = 1. Here the solution goes to NaN for Roe, HLLC and the entropy stable fluxes, and the Lax solution stalls
+		with errors of about 0.1.
+	*/
+	cases := []struct {
+		N        int
+		fluxes   []string
+		minOrder float64
+	}{
+		{2, []string{"Average", "Lax", "Roe", "Roe-ER", "HLLC", "AUSM+", "Ismail-Roe", "Chandrashekar"}, 2.5},
+		{3, []string{"Lax", "Roe"}, 3.5},
+	}
+	for _, cs := range cases {
+		for _, flux := range cs.fluxes {
+			ip.FluxType = flux
+			results := RunConvergenceStudy(&ip, []string{grid + "Couette_K082.neu.bz2", grid + "Couette_K242.neu.bz2"},
+				[]int{cs.N}, 2, &PlotMeta{StepsBeforePlot: 100000})
+			p := ObservedOrders(results)[cs.N]
+			for n := 0; n < 4; n++ {
+				assert.Truef(t, p.L2[n] > cs.minOrder, "%s N = %d: L2 order of Q[%d] is %5.2f",
+					flux, cs.N, n, p.L2[n])
+			}
+		}
+	}
+}
+
+func TestExactSteadySolutions(t *testing.T) {
+	/*
+		The steady exact solutions start from the exact state, with the exact state on the inflow and outflow and the
+		walls of the generated meshes kept. The walls are straight between the vertices on the curved walls, which
+		limits the order of accuracy to about 2, while the exact state on the walls gives the order N+1
+	*/
+	dir := t.TempDir()
+	for _, label := range []string{"Ringleb", "SupersonicVortex"} {
+		var (
+			it     = NewInitType(label)
+			meshes []string
+		)
+		for _, n := range []int{3, 6} {
+			meshFile := filepath.Join(dir, fmt.Sprintf("%s-%d.su2", label, n))
+			assert.Nil(t, WriteExactSolutionMesh(it, meshFile, n))
+			meshes = append(meshes, meshFile)
+		}
+		ip := *ipDefault
+		ip.InitType = label
+		ip.PolynomialOrder = 2
+		ip.FluxType = "Roe"
+		ip.ImplicitSolver = true
+		ip.MaxIterations = 200
+		c := NewEuler(&ip, meshes[0], 2, false, false, false)
+		assert.Equal(t, SolutionError{Area: c.GetSolutionError(c.AnalyticSolution, 0).Area},
+			c.GetSolutionError(c.AnalyticSolution, 0))
+		count := make(map[types.BCFLAG]int)
+		for _, e := range c.dfr.Tris.Edges {
+			if e.NumConnectedTris == 1 {
+				count[e.BCType]++
+			}
+		}
+		assert.Equal(t, map[types.BCFLAG]int{types.BC_Wall: 2 * 12, types.BC_Exact: 2 * 3}, count)
+
+		results := RunConvergenceStudy(&ip, meshes, nil, 2, &PlotMeta{StepsBeforePlot: 100000})
+		p := ObservedOrders(results)[2]
+		assert.Truef(t, p.L2[0] > 1.5, "%s: L2 order of density is %5.2f", label, p.L2[0])
+		for n := 0; n < 4; n++ {
+			assert.Truef(t, results[1].Error.L2[n] < results[0].Error.L2[n], "%s: L2 error of Q[%d] is %v",
+				label, n, []float64{results[0].Error.L2[n], results[1].Error.L2[n]})
+		}
+	}
+	assert.NotNil(t, WriteExactSolutionMesh(IVORTEX, filepath.Join(dir, "ivortex.su2"), 4))
+}
+
+func TestShockTube(t *testing.T) {
+	/*
+		The shock tube is initialized from the left and right states of the Riemann problem, which are held at the ends
+		of the tube, and the solution is compared with the exact solution of the Riemann problem
+	*/
+	meshFile := "../../test_cases/Euler2D/shock-tube/sod-aligned-100pts.su2"
+	{ // The Riemann problem defaults to Sod's problem, other states are [rho, u, p]
+		ip := *ipDefault
+		assert.Equal(t, exact_riemann.NewSod(), ip.GetShockTube())
+		ip.ShockTubeLeft, ip.ShockTubeRight, ip.ShockTubeX0, ip.Gamma = []float64{1, -2, 0.4}, []float64{1, 2, 0.4}, 0.4, 1.2
+		rp := ip.GetShockTube()
+		assert.Equal(t, exact_riemann.State{Rho: 1, U: -2, P: 0.4}, rp.Left)
+		assert.Equal(t, exact_riemann.State{Rho: 1, U: 2, P: 0.4}, rp.Right)
+		assert.Equal(t, [2]float64{0.4, 1.2}, [2]float64{rp.X0, rp.Gamma})
+		ip.ShockTubeRight = []float64{1, 2}
+		assert.Panics(t, func() { ip.GetShockTube() })
+	}
+	ip := *ipDefault
+	ip.InitType = "ShockTube"
+	ip.FluxType = "Roe"
+	ip.PolynomialOrder = 2
+	ip.Kappa = 4
+	ip.Limiter = "None + Positivity"
+	ip.FinalTime = 0.01
+	ip.ShockTubeLeft, ip.ShockTubeRight = []float64{1, 0.75, 1}, []float64{0.125, 0, 0.1}
+	c := NewEuler(&ip, meshFile, 2, false, false, false)
+	rp := c.ShockTube.Riemann
+	for n, check := range [2][4]float64{{1, 0.75, 0, 1/0.4 + 0.5*0.75*0.75}, {0.125, 0, 0, 0.1 / 0.4}} {
+		fs := []*FreeStream{c.FSIn, c.FSOut}[n]
+		for i := 0; i < 4; i++ {
+			assert.InDelta(t, check[i], fs.Qinf[i], 1.e-12)
+		}
+	}
+	assert.Equal(t, SolutionError{Area: c.GetSolutionError(c.AnalyticSolution, 0).Area},
+		c.GetSolutionError(c.AnalyticSolution, 0))
+	{ // The divergence of the flux is zero in the uniform regions and balances the time derivative in the fan
+		var (
+			h    = 1.e-6
+			div0 = c.AnalyticSolution.GetDivergence(0.1, 0.1, 0)
+			x    = rp.X0 + 0.1*(rp.Left.U-rp.Left.C(rp.Gamma)+0.1) // Behind the head of the left rarefaction
+			div  = c.AnalyticSolution.GetDivergence(0.1, x, 0)
+		)
+		assert.Equal(t, [4]float64{}, div0)
+		assert.True(t, math.Abs(div[0]) > 0.1)
+		q1p, q2p, _, q4p := c.AnalyticSolution.GetStateC(0.1+h, x, 0)
+		q1m, q2m, _, q4m := c.AnalyticSolution.GetStateC(0.1-h, x, 0)
+		for n, dQdt := range [4]float64{q1p - q1m, q2p - q2m, 0, q4p - q4m} {
+			assert.InDelta(t, 0, dQdt/(2*h)+div[n], 1.e-4)
+		}
+	}
+	// The solution follows the waves, with a fraction of the error of the initial state held in place
+	seInitial := c.GetSolutionError(c.AnalyticSolution, ip.FinalTime)
+	c.Solve(&PlotMeta{StepsBeforePlot: 100000})
+	se := c.GetSolutionError(c.AnalyticSolution, ip.FinalTime)
+	for _, n := range []int{0, 1, 3} {
+		assert.Truef(t, se.L1[n] < 0.25*seInitial.L1[n], "L1 error of Q[%d] is %v, initially %v",
+			n, se.L1[n], seInitial.L1[n])
+	}
+	{ // Elements take the state at their centroid when the diaphragm is within them
+		ip.ShockTubeX0 = 0.41
+		c = NewEuler(&ip, meshFile, 2, false, false, false)
+		for np, Q := range c.Q {
+			Np, Kmax := Q[0].Dims()
+			for k := 0; k < Kmax; k++ {
+				for i := 1; i < Np; i++ {
+					assert.Equal(t, Q[0].DataP[k], Q[0].DataP[k+i*Kmax], "shard %d element %d", np, k)
+				}
+			}
+		}
+	}
+}
+
+func TestPerssonC0(t *testing.T) {
+	// PerssonC0 adds the C0 artificial dissipation, which changes the solution in shocked elements
+	ip := *ipDefault
+	ip.InitType = "ShockTube"
+	ip.FluxType = "Roe"
+	ip.PolynomialOrder = 2
+	ip.Kappa = 4
+	ip.Limiter = "PerssonC0"
+	ip.ShockTubeLeft, ip.ShockTubeRight = []float64{1, 0.75, 1}, []float64{0.125, 0, 0.1}
+	c := NewEuler(&ip, "../../test_cases/Euler2D/shock-tube/sod-aligned-100pts.su2", 1, false, false, false)
+	if !assert.NotNil(t, c.Dissipation) {
+		return
+	}
+	rk := c.NewRungeKuttaSSP()
+	defer rk.Close()
+	for i := 0; i < 10; i++ {
+		rk.Step(c)
+	}
+	var (
+		Q           = c.Q[0]
+		Np, Kmax    = Q[0].Dims()
+		Q0, QNoDis  [4][]float64
+		UE          = make([]float64, Np)
+		shocked     []int
+		dissipation = c.Dissipation
+	)
+	for k := 0; k < Kmax; k++ {
+		for i := 0; i < Np; i++ {
+			UE[i] = Q[0].DataP[k+i*Kmax]
+		}
+		if c.Limiter.ShockFinder[0].ElementHasShock(UE) {
+			shocked = append(shocked, k)
+		}
+	}
+	if !assert.NotEmpty(t, shocked) {
+		return
+	}
+	// Take the same step without and then with the dissipation
+	for n := 0; n < 4; n++ {
+		Q0[n], QNoDis[n] = make([]float64, len(Q[n].DataP)), make([]float64, len(Q[n].DataP))
+		copy(Q0[n], Q[n].DataP)
+	}
+	c.Dissipation = nil
+	rk.Step(c)
+	for n := 0; n < 4; n++ {
+		copy(QNoDis[n], Q[n].DataP)
+		copy(Q[n].DataP, Q0[n])
+	}
+	c.Dissipation = dissipation
+	rk.Step(c)
+	for _, k := range shocked {
+		var changed, residual bool
+		for i := 0; i < Np; i++ {
+			ind := k + i*Kmax
+			changed = changed || Q[0].DataP[ind] != QNoDis[0][ind]
+			residual = residual || rk.Residual[0][0].DataP[ind] != 0
+		}
+		assert.Truef(t, changed, "dissipation does not change the density in shocked element %d", k)
+		assert.Truef(t, residual, "residual of shocked element %d is zeroed", k)
+	}
+}
+
 func PrintQ(Q [4]utils.Matrix, l string) {
 	var (
 		label string
@@ -994,3 +2794,200 @@ func CheckFlux0(c *Euler, t *testing.T) {
 		}
 	}
 }
+
+func TestImplicitSolver(t *testing.T) {
+	assert.Equal(t, IMPLICIT_LUSGS, NewImplicitMethod(""))
+	assert.Equal(t, IMPLICIT_BlockJacobi, NewImplicitMethod("Block-Jacobi"))
+	assert.Panics(t, func() { NewImplicitMethod("gmres") })
+
+	ip := *ipDefault
+	ip.PolynomialOrder = 2
+	ip.Minf = 0.5
+	ip.FluxType = "roe"
+	ip.Limiter = "barth jesperson"
+	// Freestream is preserved by the implicit update
+	{
+		c := NewEuler(&ip, "../../DG2D/test_tris_6_nowall.neu", 1, false, false, false)
+		Q0 := c.RecombineShardsKBy4(c.Q)
+		is := c.NewImplicitSolver(c.NewRungeKuttaSSP(), &ip)
+		defer is.Close()
+		for i := 0; i < 5; i++ {
+			is.Step(c)
+		}
+		Q := c.RecombineShardsKBy4(c.Q)
+		for n := 0; n < 4; n++ {
+			assert.InDeltaSlicef(t, Q0[n].DataP, Q[n].DataP, 1.e-12, "Q[%d] drifts from the freestream", n)
+		}
+		assert.Greater(t, is.CFL, ip.CFL)
+	}
+	// A perturbed solution converges to steady state with both linear solvers
+	for _, method := range []string{"lu-sgs", "block-jacobi"} {
+		ip.ImplicitMethod = method
+		c := NewEuler(&ip, "../../DG2D/test_tris_6.neu", 1, false, false, false)
+		X, Y := c.ShardByK(c.dfr.SolutionX), c.ShardByK(c.dfr.SolutionY)
+		for i := range c.Q[0][0].DataP {
+			c.Q[0][0].DataP[i] *= 1 + 0.1*math.Sin(X[0].DataP[i])*math.Cos(Y[0].DataP[i])
+		}
+		is := c.NewImplicitSolver(c.NewRungeKuttaSSP(), &ip)
+		defer is.Close()
+		is.Step(c)
+		res0 := is.RHSNorm
+		for i := 1; i < 40; i++ {
+			is.Step(c)
+		}
+		assert.Less(t, is.RHSNorm, 0.01*res0, method)
+	}
+	// The implicit solution has no time, it runs until the iteration limit and checkpoints the iteration count
+	{
+		ip.ImplicitMethod = ""
+		ip.FinalTime = 0
+		ip.MaxIterations = 3
+		c := NewEuler(&ip, "../../DG2D/test_tris_6.neu", 1, false, false, false)
+		c.CheckpointFile = filepath.Join(t.TempDir(), "implicit.chk")
+		c.CheckpointEvery = 2
+		c.SolveImplicit(&PlotMeta{StepsBeforePlot: 100000})
+		assert.Equal(t, 3, c.Result.Iteration)
+		assert.Equal(t, 0., c.Result.Time)
+		cp, err := ReadCheckpoint(c.CheckpointFile)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, cp.StepCount)
+		assert.Equal(t, 0., cp.Time)
+	}
+}
+
+func TestNavierStokes(t *testing.T) {
+	assert.Equal(t, VISCOSITY_Sutherland, NewViscosityModel(""))
+	assert.Equal(t, VISCOSITY_Constant, NewViscosityModel("Constant"))
+	assert.Panics(t, func() { NewViscosityModel("power law") })
+	{
+		var ip InputParameters
+		err := ip.Parse([]byte(`
+Reynolds: 100
+BCs:
+  Wall-Inner:
+      0:
+         Omega: 0.2
+  Wall-outer:
+      0:
+         T: 1.1
+`))
+		assert.Nil(t, err)
+		w := NewNoSlipWall("wall-inner", ip.GetBCParameters("wall-inner"))
+		assert.False(t, w.Isothermal)
+		u, v := w.GetVelocity(0, 2)
+		assert.InDelta(t, -0.4, u, 1.e-12)
+		assert.InDelta(t, 0., v, 1.e-12)
+		w = NewNoSlipWall("wall-outer", ip.GetBCParameters("wall-outer"))
+		assert.True(t, w.Isothermal)
+		assert.Equal(t, 1.1, w.Temperature)
+	}
+	// Sutherland viscosity is the freestream viscosity at the freestream temperature
+	{
+		ns := &NavierStokes{MuInf: 0.01, SutherlandRatio: 110.4 / 288.15, Model: VISCOSITY_Sutherland}
+		assert.InDelta(t, 0.01, ns.GetViscosity(1), 1.e-14)
+		assert.Greater(t, ns.GetViscosity(1.5), 0.01)
+	}
+	/*
+		Couette flow between concentric cylinders, the inner cylinder (R1 = 1) rotates and the outer cylinder (R2 = 4)
+		is fixed, both walls are isothermal. With constant viscosity the exact velocity and temperature are:
+			Utheta = A*r + B/r
+			Theta = a*ln(r) - mu*B*B/(kappa*r*r) + d
+	*/
+	{
+		var (
+			R1, R2 = 1., 4.
+			U1     = 0.2
+			ip     = *ipDefault
+		)
+		ip.PolynomialOrder = 2
+		ip.FluxType = "roe"
+		ip.Reynolds = 10
+		ip.ViscosityModel = "constant"
+		ip.LocalTimeStepping = true
+		ip.CFL = 0.5
+		ip.BCs = map[string]map[int]map[string]float64{
+			"Wall-inner": {0: {"Omega": U1 / R1, "T": 1}},
+			"Wall-outer": {0: {"T": 1}},
+		}
+		meshFile := writeCouetteMesh(t, "../../test_cases/Grid/Euler2D/Couette_K082.neu.bz2")
+		c := NewEuler(&ip, meshFile, 1, false, false, false)
+		var (
+			ns    = c.Viscous
+			A     = U1 * R1 / (R1*R1 - R2*R2)
+			B     = -A * R2 * R2
+			mu    = ns.MuInf
+			kappa = mu / (ns.Prandtl * (ns.Gamma - 1))
+			g     = func(r float64) float64 { return -mu * B * B / (kappa * r * r) }
+			a     = (g(R1) - g(R2)) / math.Log(R2/R1)
+			d     = 1 - g(R1) - a*math.Log(R1)
+			exact = func(r float64) (uTheta, theta float64) {
+				return A*r + B/r, a*math.Log(r) + g(r) + d
+			}
+			X, Y = c.ShardByK(c.dfr.SolutionX)[0], c.ShardByK(c.dfr.SolutionY)[0]
+			Q    = c.Q[0]
+			gg1  = ns.Gamma * (ns.Gamma - 1)
+		)
+		assert.InDelta(t, 0.1, mu, 1.e-14)
+		assert.Equal(t, 2, len(c.dfr.BCEdges))
+		// The viscous flux of the exact solution, evaluated on the X axis where Utheta = V
+		{
+			r := 2.
+			uTheta, theta := exact(r)
+			dUdr, dTdr := A-B/(r*r), a/r-2*g(r)/r
+			Fx, Fy := ns.GetViscousFlux(
+				[4]float64{1, 0, uTheta, theta/gg1 + 0.5*uTheta*uTheta},
+				[4]float64{0, 0, dUdr, dTdr/gg1 + uTheta*dUdr},
+				[4]float64{0, -uTheta / r, 0, 0})
+			tau := -2 * mu * B / (r * r)
+			assert.InDeltaSlice(t, []float64{0, 0, tau, uTheta*tau + kappa*dTdr}, Fx[:], 1.e-12)
+			assert.InDeltaSlice(t, []float64{0, tau, 0, 0}, Fy[:], 1.e-12)
+		}
+		// Starting from the exact solution, the discrete solution should remain close to it
+		for i := range Q[0].DataP {
+			x, y := X.DataP[i], Y.DataP[i]
+			r := math.Sqrt(x*x + y*y)
+			uTheta, theta := exact(r)
+			rho := Q[0].DataP[i]
+			u, v := -uTheta*y/r, uTheta*x/r
+			Q[1].DataP[i], Q[2].DataP[i] = rho*u, rho*v
+			Q[3].DataP[i] = rho*theta/gg1 + 0.5*rho*(u*u+v*v)
+		}
+		rk := c.NewRungeKuttaSSP()
+		defer rk.Close()
+		for i := 0; i < 200; i++ {
+			rk.Step(c)
+			rk.StepCount++
+		}
+		var uErr, tErr float64
+		for i := range Q[0].DataP {
+			x, y := X.DataP[i], Y.DataP[i]
+			r := math.Sqrt(x*x + y*y)
+			rho, rhoU, rhoV, E := Q[0].DataP[i], Q[1].DataP[i], Q[2].DataP[i], Q[3].DataP[i]
+			uTheta, theta := exact(r)
+			uErr = math.Max(uErr, math.Abs((-y*rhoU+x*rhoV)/(r*rho)-uTheta))
+			tErr = math.Max(tErr, math.Abs(ns.getTheta(rho, rhoU, rhoV, E)-theta))
+		}
+		assert.Less(t, uErr, 0.05*U1)
+		assert.Less(t, tErr, 0.001)
+	}
+}
+
+func writeCouetteMesh(t *testing.T, bz2File string) (meshFile string) {
+	// The Couette meshes name the walls Inflow (R = 4) and Outflow (R = 1), rename them as no slip walls
+	f, err := os.Open(bz2File)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	data, err := ioutil.ReadAll(bzip2.NewReader(f))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mesh := strings.Replace(string(data), "Inflow", "Wall-outer", 1)
+	mesh = strings.Replace(mesh, "Outflow", "Wall-inner", 1)
+	meshFile = filepath.Join(t.TempDir(), "couette.neu")
+	if err = ioutil.WriteFile(meshFile, []byte(mesh), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return
+}
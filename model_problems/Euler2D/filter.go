@@ -17,6 +17,7 @@ type SolutionLimiter struct {
 	ShockFinder          []*ModeAliasShockFinder // Sharded
 	UElement, dUdr, dUds []utils.Matrix          // Sharded scratch areas for assembly and testing of solution values
 	FS                   *FreeStream
+	Positivity           *PositivityLimiter // If not nil, applied after the limiter to keep density and pressure positive
 }
 
 type LimiterType uint8
@@ -25,18 +26,22 @@ const (
 	None LimiterType = iota
 	BarthJesperson
 	PerssonC0
+	Positivity
 )
 
 var (
 	LimiterNames = map[string]LimiterType{
 		"barthjesperson":  BarthJesperson,
 		"barth jesperson": BarthJesperson,
-		"perssonC0":       PerssonC0,
-		"persson C0":      PerssonC0,
+		"perssonc0":       PerssonC0,
+		"persson c0":      PerssonC0,
+		"positivity":      Positivity,
+		"none":            None,
 	}
 	LimiterNamesRev = map[LimiterType]string{
 		BarthJesperson: "BarthJesperson",
 		PerssonC0:      "Persson, C0 viscosity",
+		Positivity:     "Positivity",
 	}
 )
 
@@ -65,6 +70,34 @@ func NewLimiterType(label string) (lt LimiterType) {
 	return
 }
 
+func NewLimiterTypes(label string) (lt LimiterType, positivity, dissipation bool) {
+	/*
+		The positivity limiter can be combined with one of the other limiters, e.g. "BarthJesperson + Positivity".
+		Artificial dissipation is used when there is no other limiter, except when the positivity limiter is used alone,
+		"None + Positivity" combines the dissipation with the positivity limiter. PerssonC0 is the C0 artificial
+		dissipation, which is applied in place of limiting the solution
+	*/
+	var named bool
+	for _, name := range strings.FieldsFunc(label, func(r rune) bool { return r == '+' || r == ',' }) {
+		switch l := NewLimiterType(name); l {
+		case Positivity:
+			positivity = true
+		default:
+			lt, named = l, true
+		}
+	}
+	dissipation = lt == PerssonC0 || (lt == None && (named || !positivity))
+	return
+}
+
+func (bjl *SolutionLimiter) Print() (txt string) {
+	txt = bjl.limiterType.Print()
+	if bjl.Positivity != nil {
+		txt += " + Positivity"
+	}
+	return
+}
+
 func NewSolutionLimiter(t LimiterType, kappa float64, dfr *DG2D.DFR2D, pm *PartitionMap, fs *FreeStream) (bjl *SolutionLimiter) {
 	var (
 		Np       = dfr.SolutionElement.Np
@@ -98,7 +131,11 @@ func (bjl *SolutionLimiter) LimitSolution(myThread int, Qall, Residual [][4]util
 		UE       = bjl.UElement[myThread]
 		//FSFar       = bjl.FSFar
 	)
-	if bjl.limiterType == None {
+	if bjl.Positivity != nil {
+		defer bjl.Positivity.Limit(myThread, Q) // Runs last, after any other limiting
+	}
+	if bjl.limiterType == None || bjl.limiterType == PerssonC0 {
+		// PerssonC0 is implemented by the artificial dissipation, shocked elements are left to evolve
 		return
 	}
 	for k := 0; k < Kmax; k++ {
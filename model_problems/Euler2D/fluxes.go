@@ -20,16 +20,26 @@ const (
 	FLUX_LaxFriedrichs
 	FLUX_Roe
 	FLUX_RoeER
+	FLUX_HLLC
+	FLUX_AUSMPlusUp
+	FLUX_IsmailRoe     // Entropy stable, Ismail-Roe two point flux in the volume and at edges
+	FLUX_Chandrashekar // Entropy stable, Chandrashekar two point flux in the volume and at edges
 )
 
 var (
 	FluxNames = map[string]FluxType{
-		"average": FLUX_Average,
-		"lax":     FLUX_LaxFriedrichs,
-		"roe":     FLUX_Roe,
-		"roe-er":  FLUX_RoeER,
+		"average":       FLUX_Average,
+		"lax":           FLUX_LaxFriedrichs,
+		"roe":           FLUX_Roe,
+		"roe-er":        FLUX_RoeER,
+		"hllc":          FLUX_HLLC,
+		"ausm+":         FLUX_AUSMPlusUp,
+		"ausm+-up":      FLUX_AUSMPlusUp,
+		"ismail-roe":    FLUX_IsmailRoe,
+		"chandrashekar": FLUX_Chandrashekar,
 	}
-	FluxPrintNames = []string{"Average", "Lax Friedrichs", "Roe", "Roe-ER"}
+	FluxPrintNames = []string{"Average", "Lax Friedrichs", "Roe", "Roe-ER", "HLLC", "AUSM+-up",
+		"Ismail-Roe Entropy Stable", "Chandrashekar Entropy Stable"}
 )
 
 func (ft FluxType) Print() (txt string) {
@@ -484,3 +494,138 @@ func (c *Euler) RoeFlux2(kL, kR, KmaxL, KmaxR, shiftL, shiftR int,
 		}
 	}
 }
+
+func (c *Euler) getFaceNormalState(Q_Face [4]utils.Matrix, ind int, normal [2]float64) (rho, u, v, qn, p, E float64) {
+	rho, E = Q_Face[0].DataP[ind], Q_Face[3].DataP[ind]
+	u, v = Q_Face[1].DataP[ind]/rho, Q_Face[2].DataP[ind]/rho
+	qn = u*normal[0] + v*normal[1]
+	p = c.FSFar.GetFlowFunction(Q_Face, ind, StaticPressure)
+	return
+}
+
+func (c *Euler) HLLCFlux(kL, kR, KmaxL, KmaxR, shiftL, shiftR int,
+	Q_FaceL, Q_FaceR [4]utils.Matrix, normal [2]float64, normalFlux [][4]float64) {
+	/*
+		HLLC approximate Riemann solver of Toro, Spruce and Speares, which restores the contact wave missing from HLL.
+		Wave speed estimates are from Einfeldt, using the Roe averaged state.
+	*/
+	var (
+		Nedge                         = c.dfr.FluxElement.NpEdge
+		rhoL, uL, vL, qnL, pL, EL, CL float64
+		rhoR, uR, vR, qnR, pR, ER, CR float64
+		Gamma                         = c.FSFar.Gamma
+		GM1                           = Gamma - 1
+		nx, ny                        = normal[0], normal[1]
+		sqrt, min, max                = math.Sqrt, math.Min, math.Max
+	)
+	physicalFlux := func(rho, u, v, qn, p, E float64) [4]float64 {
+		return [4]float64{rho * qn, rho*u*qn + p*nx, rho*v*qn + p*ny, (E + p) * qn}
+	}
+	starFlux := func(rho, u, v, qn, p, E, S, SM float64) (F [4]float64) {
+		// Flux in the star region on one side of the contact, F* = F + S*(Q* - Q)
+		var (
+			F0     = physicalFlux(rho, u, v, qn, p, E)
+			factor = rho * (S - qn) / (S - SM)
+			Q      = [4]float64{rho, rho * u, rho * v, E}
+			Qstar  = [4]float64{
+				factor,
+				factor * (u + (SM-qn)*nx),
+				factor * (v + (SM-qn)*ny),
+				factor * (E/rho + (SM-qn)*(SM+p/(rho*(S-qn)))),
+			}
+		)
+		for n := 0; n < 4; n++ {
+			F[n] = F0[n] + S*(Qstar[n]-Q[n])
+		}
+		return
+	}
+	for i := 0; i < Nedge; i++ {
+		iL := i + shiftL
+		iR := Nedge - 1 - i + shiftR // Shared edges run in reverse order relative to each other
+		indL, indR := kL+iL*KmaxL, kR+iR*KmaxR
+		rhoL, uL, vL, qnL, pL, EL = c.getFaceNormalState(Q_FaceL, indL, normal)
+		rhoR, uR, vR, qnR, pR, ER = c.getFaceNormalState(Q_FaceR, indR, normal)
+		CL, CR = sqrt(Gamma*pL/rhoL), sqrt(Gamma*pR/rhoR)
+		// Roe averaged normal velocity and sound speed
+		rhoLs, rhoRs := sqrt(rhoL), sqrt(rhoR)
+		ooRs := 1. / (rhoLs + rhoRs)
+		u, v := (rhoLs*uL+rhoRs*uR)*ooRs, (rhoLs*vL+rhoRs*vR)*ooRs
+		h := (rhoLs*(EL+pL)/rhoL + rhoRs*(ER+pR)/rhoR) * ooRs
+		qn := u*nx + v*ny
+		C := sqrt(GM1 * (h - 0.5*(u*u+v*v)))
+		SL, SR := min(qnL-CL, qn-C), max(qnR+CR, qn+C)
+		SM := (pR - pL + rhoL*qnL*(SL-qnL) - rhoR*qnR*(SR-qnR)) / (rhoL*(SL-qnL) - rhoR*(SR-qnR))
+		switch {
+		case SL >= 0:
+			normalFlux[i] = physicalFlux(rhoL, uL, vL, qnL, pL, EL)
+		case SR <= 0:
+			normalFlux[i] = physicalFlux(rhoR, uR, vR, qnR, pR, ER)
+		case SM >= 0:
+			normalFlux[i] = starFlux(rhoL, uL, vL, qnL, pL, EL, SL, SM)
+		default:
+			normalFlux[i] = starFlux(rhoR, uR, vR, qnR, pR, ER, SR, SM)
+		}
+	}
+}
+
+func (c *Euler) AUSMPlusUpFlux(kL, kR, KmaxL, KmaxR, shiftL, shiftR int,
+	Q_FaceL, Q_FaceR [4]utils.Matrix, normal [2]float64, normalFlux [][4]float64) {
+	/*
+		AUSM+-up of Liou (JCP 2006), splits the flux into a convected part and a pressure part, with the pressure
+		diffusion (Kp) and velocity diffusion (Ku) terms for all speeds. The low Mach scaling uses the freestream Mach
+		number as the cutoff, limited to 0.1 for flows started from rest.
+	*/
+	var (
+		Nedge                        = c.dfr.FluxElement.NpEdge
+		rhoL, uL, vL, qnL, pL, EL    float64
+		rhoR, uR, vR, qnR, pR, ER    float64
+		Gamma                        = c.FSFar.Gamma
+		nx, ny                       = normal[0], normal[1]
+		Kp, Ku, sigma, beta          = 0.25, 0.75, 1., 1. / 8.
+		Minf2                        = math.Max(c.FSFar.Minf*c.FSFar.Minf, 0.01)
+		sqrt, abs, min, max, copysgn = math.Sqrt, math.Abs, math.Min, math.Max, math.Copysign
+	)
+	// Split Mach number and pressure polynomials of degree 4 and 5
+	M4 := func(M, sign float64) float64 {
+		if abs(M) >= 1 {
+			return 0.5 * (M + sign*abs(M))
+		}
+		M2p, M2m := 0.25*(M+sign)*(M+sign)*sign, -0.25*(M-sign)*(M-sign)*sign
+		return M2p * (1 - sign*16*beta*M2m)
+	}
+	P5 := func(M, sign, alpha float64) float64 {
+		if abs(M) >= 1 {
+			return 0.5 * (1 + sign*copysgn(1, M))
+		}
+		M2p, M2m := 0.25*(M+sign)*(M+sign)*sign, -0.25*(M-sign)*(M-sign)*sign
+		return M2p * ((2*sign - M) - sign*16*alpha*M*M2m)
+	}
+	for i := 0; i < Nedge; i++ {
+		iL := i + shiftL
+		iR := Nedge - 1 - i + shiftR // Shared edges run in reverse order relative to each other
+		indL, indR := kL+iL*KmaxL, kR+iR*KmaxR
+		rhoL, uL, vL, qnL, pL, EL = c.getFaceNormalState(Q_FaceL, indL, normal)
+		rhoR, uR, vR, qnR, pR, ER = c.getFaceNormalState(Q_FaceR, indR, normal)
+		HL, HR := (EL+pL)/rhoL, (ER+pR)/rhoR
+		// Interface speed of sound from the critical speed of sound
+		CsL, CsR := sqrt(2*(Gamma-1)/(Gamma+1)*HL), sqrt(2*(Gamma-1)/(Gamma+1)*HR)
+		C := min(CsL*CsL/max(CsL, qnL), CsR*CsR/max(CsR, -qnR))
+		ML, MR := qnL/C, qnR/C
+		Mbar2 := 0.5 * (qnL*qnL + qnR*qnR) / (C * C)
+		Mo := sqrt(min(1, max(Mbar2, Minf2)))
+		fa := Mo * (2 - Mo)
+		alpha := 3. / 16. * (-4 + 5*fa*fa)
+		rho := 0.5 * (rhoL + rhoR)
+		Mp := -Kp / fa * max(1-sigma*Mbar2, 0) * (pR - pL) / (rho * C * C)
+		M := M4(ML, 1) + M4(MR, -1) + Mp
+		PL, PR := P5(ML, 1, alpha), P5(MR, -1, alpha)
+		p := PL*pL + PR*pR - Ku*PL*PR*(rhoL+rhoR)*fa*C*(qnR-qnL)
+		if M > 0 {
+			mdot := C * M * rhoL
+			normalFlux[i] = [4]float64{mdot, mdot*uL + p*nx, mdot*vL + p*ny, mdot * HL}
+		} else {
+			mdot := C * M * rhoR
+			normalFlux[i] = [4]float64{mdot, mdot*uR + p*nx, mdot*vR + p*ny, mdot * HR}
+		}
+	}
+}
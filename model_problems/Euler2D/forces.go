@@ -0,0 +1,186 @@
+package Euler2D
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+
+	"github.com/notargets/gocfd/DG1D"
+	"github.com/notargets/gocfd/types"
+)
+
+/*
+Integration of the surface pressure over the wall boundaries to obtain the aerodynamic force and moment coefficients
+
+The pressure is evaluated at the edge flux points of the element adjacent to the wall, which are Gauss-Legendre points
+along each edge, the same points used for the wall flux in WallBC. The face normal of the fluid element points into
+the body, so the force on the body is the integral of (p - Pinf) * normal along the wall.
+*/
+type ForceCoefficients struct {
+	CL, CD, CM float64
+}
+
+type AeroForces struct {
+	Tags         []types.BCTAG       // Wall and Cyl BC tags, sorted
+	Edges        [][]types.EdgeKey   // Edges for each tag
+	MomentCenter [2]float64          // Reference point for the pitching moment
+	RefLength    float64             // Reference length (chord) used for normalization
+	Weights      []float64           // Gauss-Legendre weights for the edge points
+	Coefficients []ForceCoefficients // Most recent coefficients for each tag
+	Total        ForceCoefficients   // Most recent coefficients summed over all tags
+	HistoryFile  string
+	history      *os.File
+}
+
+func (c *Euler) NewAeroForces(momentCenter [2]float64, refLength float64, historyFile string) (af *AeroForces) {
+	var (
+		Nedge = c.dfr.FluxElement.NpEdge
+	)
+	if c.FSFar.QQinf == 0 { // No freestream dynamic pressure to normalize by, e.g. Minf = 0
+		return
+	}
+	for tag := range c.dfr.BCEdges {
+		switch tag.GetFLAG() {
+		case types.BC_Wall, types.BC_Cyl:
+			if af == nil {
+				af = &AeroForces{}
+			}
+			af.Tags = append(af.Tags, tag)
+		}
+	}
+	if af == nil { // No wall boundaries
+		return
+	}
+	sort.Slice(af.Tags, func(i, j int) bool { return af.Tags[i] < af.Tags[j] })
+	af.Edges = make([][]types.EdgeKey, len(af.Tags))
+	for i, tag := range af.Tags {
+		for _, e := range c.dfr.BCEdges[tag] {
+			en := e.GetKey()
+			// Edges replaced by other BCs, e.g. the analytic BC for the vortex, are not integrated
+			if ee, ok := c.dfr.Tris.Edges[en]; ok && (ee.BCType == types.BC_Wall || ee.BCType == types.BC_Cyl) {
+				af.Edges[i] = append(af.Edges[i], en)
+			}
+		}
+	}
+	if refLength <= 0 {
+		refLength = 1
+	}
+	af.RefLength = refLength
+	af.MomentCenter = momentCenter
+	_, W := DG1D.JacobiGQ(0, 0, Nedge-1)
+	af.Weights = W.DataP
+	af.Coefficients = make([]ForceCoefficients, len(af.Tags))
+	af.HistoryFile = historyFile
+	return
+}
+
+func (c *Euler) CalculateForces(af *AeroForces) {
+	/*
+		Forces are normalized by the freestream dynamic pressure and the reference length, lift and drag are
+		perpendicular and parallel to the freestream direction, the moment is positive nose up (clockwise)
+	*/
+	var (
+		dfr         = c.dfr
+		Nedge       = dfr.FluxElement.NpEdge
+		Nint        = dfr.FluxElement.NpInt
+		KmaxGlobal  = dfr.K
+		alpha       = c.FSFar.Alpha * math.Pi / 180.
+		cosA, sinA  = math.Cos(alpha), math.Sin(alpha)
+		qq          = c.FSFar.QQinf * af.RefLength
+		X, Y        = dfr.FluxX.DataP, dfr.FluxY.DataP
+		xRef, yRef  = af.MomentCenter[0], af.MomentCenter[1]
+		FxT, FyT, M float64
+	)
+	for it := range af.Tags {
+		var Fx, Fy, Mz float64
+		for _, en := range af.Edges[it] {
+			e := dfr.Tris.Edges[en]
+			var (
+				kGlobal = int(e.ConnectedTris[0])
+				edgeNum = int(e.ConnectedTriEdgeNumber[0])
+				shift   = edgeNum * Nedge
+				normal  = c.GetFaceNormal(kGlobal, edgeNum)
+				halfLen = 0.5 * e.GetEdgeLength()
+			)
+			QEdge := c.InterpolateEdgeState(kGlobal, edgeNum)
+			for i, qe := range QEdge {
+				p := c.FSFar.GetFlowFunctionQQ(qe, StaticPressure) - c.FSFar.Pinf
+				w := af.Weights[i] * halfLen
+				fx, fy := w*p*normal[0], w*p*normal[1]
+				ind := kGlobal + (2*Nint+shift+i)*KmaxGlobal
+				Fx += fx
+				Fy += fy
+				Mz += (X[ind]-xRef)*fy - (Y[ind]-yRef)*fx
+			}
+		}
+		af.Coefficients[it] = ForceCoefficients{
+			CL: (-Fx*sinA + Fy*cosA) / qq,
+			CD: (Fx*cosA + Fy*sinA) / qq,
+			CM: -Mz / (qq * af.RefLength),
+		}
+		FxT += Fx
+		FyT += Fy
+		M += Mz
+	}
+	af.Total = ForceCoefficients{
+		CL: (-FxT*sinA + FyT*cosA) / qq,
+		CD: (FxT*cosA + FyT*sinA) / qq,
+		CM: -M / (qq * af.RefLength),
+	}
+}
+
+func (c *Euler) InterpolateEdgeState(kGlobal, edgeNum int) (QEdge [][4]float64) {
+	/*
+		Interpolates the solution of element kGlobal to the flux points of one of its edges, in the element's edge order
+	*/
+	var (
+		dfr           = c.dfr
+		Nedge         = dfr.FluxElement.NpEdge
+		Np            = dfr.SolutionElement.Np
+		interpD       = dfr.FluxEdgeInterp.DataP
+		shift         = edgeNum * Nedge
+		k, Kmax, myTh = c.Partitions.GetLocalK(kGlobal)
+		Q             = c.Q[myTh]
+	)
+	QEdge = make([][4]float64, Nedge)
+	for i := 0; i < Nedge; i++ {
+		for n := 0; n < 4; n++ {
+			for j := 0; j < Np; j++ {
+				QEdge[i][n] += interpD[j+(i+shift)*Np] * Q[n].DataP[k+j*Kmax]
+			}
+		}
+	}
+	return
+}
+
+func (af *AeroForces) WriteHistory(Time float64, steps int) (err error) {
+	if len(af.HistoryFile) == 0 {
+		return
+	}
+	if af.history == nil {
+		if af.history, err = os.Create(af.HistoryFile); err != nil {
+			return
+		}
+		header := "iter,time,CL,CD,CM"
+		for _, tag := range af.Tags {
+			header += fmt.Sprintf(",CL[%s],CD[%s],CM[%s]", tag, tag, tag)
+		}
+		if _, err = fmt.Fprintln(af.history, header); err != nil {
+			return
+		}
+	}
+	line := fmt.Sprintf("%d,%.10e,%.10e,%.10e,%.10e", steps, Time, af.Total.CL, af.Total.CD, af.Total.CM)
+	for _, fc := range af.Coefficients {
+		line += fmt.Sprintf(",%.10e,%.10e,%.10e", fc.CL, fc.CD, fc.CM)
+	}
+	_, err = fmt.Fprintln(af.history, line)
+	return
+}
+
+func (af *AeroForces) Close() {
+	if af.history != nil {
+		_ = af.history.Close()
+		af.history = nil
+	}
+}
@@ -0,0 +1,542 @@
+package Euler2D
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/notargets/gocfd/utils"
+)
+
+/*
+Implicit pseudo transient continuation for steady state solutions
+
+Each iteration is a backward Euler step in pseudo time, linearized about the current solution:
+
+	[ I/dTau - dRHS/dQ ] dQ = RHS(Q)
+
+where dTau is the local pseudo time step of each element, set by the implicit CFL number. The Jacobian is split into
+element blocks. The diagonal block of each element is assembled from the transformed flux Jacobians at the interior
+points and a Lax Friedrichs linearization of the numerical flux at the edge points, then LUP factored as a block matrix
+of 4x4 blocks, one block row per solution point. The coupling to the neighbor elements uses the same edge
+linearization and is applied matrix free within the LU-SGS sweeps.
+
+LU-SGS sweeps are done within each parallel partition, elements in other partitions are coupled only through the
+explicit residual, which keeps the sweeps independent of the thread schedule.
+*/
+type ImplicitMethod uint8
+
+const (
+	IMPLICIT_LUSGS ImplicitMethod = iota
+	IMPLICIT_BlockJacobi
+)
+
+var (
+	ImplicitMethodNames = map[string]ImplicitMethod{
+		"lu-sgs":       IMPLICIT_LUSGS,
+		"lusgs":        IMPLICIT_LUSGS,
+		"block-jacobi": IMPLICIT_BlockJacobi,
+		"jacobi":       IMPLICIT_BlockJacobi,
+	}
+	ImplicitMethodPrintNames = []string{"LU-SGS", "Block Jacobi"}
+)
+
+func (im ImplicitMethod) Print() string {
+	return ImplicitMethodPrintNames[im]
+}
+
+func NewImplicitMethod(label string) (im ImplicitMethod) {
+	var (
+		ok  bool
+		err error
+	)
+	if len(label) == 0 {
+		return IMPLICIT_LUSGS
+	}
+	label = strings.ToLower(strings.TrimSpace(label))
+	if im, ok = ImplicitMethodNames[label]; !ok {
+		err = fmt.Errorf("unable to use implicit method named [%s]", label)
+		panic(err)
+	}
+	return
+}
+
+type ImplicitSolver struct {
+	*RungeKutta4SSP                // Storage shared with the explicit solver, Residual holds the update dQ
+	Method                         ImplicitMethod
+	CFL, CFLMin, CFLMax, CFLGrowth float64
+	Sweeps                         int                   // Number of symmetric sweeps for LU-SGS
+	Diag                           [][]utils.BlockMatrix // Sharded LUP factored element diagonal blocks
+	Relaxed                        []int                 // Sharded count of elements with a reduced update
+	RHSNorm, lastRHSNorm           float64               // Norm of the steady residual, used for the CFL control
+	blockScratch                   [][][16]float64       // Sharded assembly storage, Np x Np blocks
+	rhsScratch                     [][]utils.Matrix      // Sharded element RHS, Np blocks of 4x1
+}
+
+func (c *Euler) NewImplicitSolver(rk *RungeKutta4SSP, ip *InputParameters) (is *ImplicitSolver) {
+	var (
+		NPar = c.Partitions.ParallelDegree
+		Np   = rk.NpInt
+	)
+	is = &ImplicitSolver{
+		RungeKutta4SSP: rk,
+		CFL:            c.CFL,
+		CFLMin:         c.CFL,
+		CFLMax:         1000,
+		CFLGrowth:      1.2,
+		Sweeps:         1,
+		Diag:           make([][]utils.BlockMatrix, NPar),
+		Relaxed:        make([]int, NPar),
+		blockScratch:   make([][][16]float64, NPar),
+		rhsScratch:     make([][]utils.Matrix, NPar),
+	}
+	if ip != nil {
+		is.Method = NewImplicitMethod(ip.ImplicitMethod)
+		if ip.ImplicitCFLMax > 0 {
+			is.CFLMax = ip.ImplicitCFLMax
+		}
+		if ip.ImplicitCFLGrowth > 0 {
+			is.CFLGrowth = ip.ImplicitCFLGrowth
+		}
+		if ip.ImplicitSweeps > 0 {
+			is.Sweeps = ip.ImplicitSweeps
+		}
+	}
+	if is.CFLMax < is.CFLMin {
+		is.CFLMax = is.CFLMin
+	}
+	for np := 0; np < NPar; np++ {
+		is.Diag[np] = make([]utils.BlockMatrix, rk.Kmax[np])
+		is.blockScratch[np] = make([][16]float64, Np*Np)
+		is.rhsScratch[np] = make([]utils.Matrix, Np)
+		for i := 0; i < Np; i++ {
+			is.rhsScratch[np][i] = utils.NewMatrix(4, 1)
+		}
+	}
+	return
+}
+
+func (c *Euler) SolveImplicit(pm *PlotMeta) {
+	/*
+		The pseudo time steps are local to each element, so the solution has no time: Time stays at its initial value
+		and the iterations are counted in StepCount, which is what is reported and checkpointed
+	*/
+	c.ImplicitSolver = true
+	c.LocalTimeStepping = true // Pseudo time steps are local to each element
+	c.Solve(pm)
+}
+
+func (is *ImplicitSolver) Print() string {
+	return fmt.Sprintf("Implicit solver: [%s], CFL = %8.3f ramped by %5.3f per iteration to a maximum of %8.3f",
+		is.Method.Print(), is.CFLMin, is.CFLGrowth, is.CFLMax)
+}
+
+func (is *ImplicitSolver) Step(c *Euler) {
+	/*
+		This is the controller thread - it runs the first shard and waits for the workers to finish the iteration
+	*/
+	is.Pool.Run(func(myThread int) {
+		for currentStep := 0; currentStep < 6; currentStep++ {
+			if currentStep > 0 {
+				is.Pool.Barrier.Wait()
+			}
+			is.StepWorker(c, myThread, currentStep)
+		}
+	})
+	is.ResidualNorms = GetResidualNormsFromMax(is.ResidualMax)
+	is.updateCFL(c)
+}
+
+func (is *ImplicitSolver) StepWorker(c *Euler, myThread, currentStep int) {
+	var (
+		Kmax, Jdet, Jinv, F_RT_DOF = is.Kmax[myThread], is.Jdet[myThread], is.Jinv[myThread], is.F_RT_DOF[myThread]
+		DT, Q_Face, Flux_Face      = is.DT[myThread], is.Q_Face[myThread], is.Flux_Face[myThread]
+		Q0                         = c.Q[myThread]
+		Flux                       = is.Flux[myThread]
+		RHSQ                       = is.RHSQ[myThread]
+		EdgeQ1, EdgeQ2             = is.EdgeQ1[myThread], is.EdgeQ2[myThread]
+		SortedEdgeKeys             = c.SortedEdgeKeys[myThread]
+	)
+	switch currentStep {
+	case 0:
+		for k := 0; k < Kmax; k++ {
+			DT.DataP[k] = -100 // Accumulates the max wave speed of each element
+		}
+		c.InterpolateSolutionToEdges(Q0, Q_Face, Flux, Flux_Face) // Interpolates Q_Face values from Q
+	case 1:
+		is.MaxWaveSpeed[myThread] =
+			c.CalculateEdgeFlux(is.Time, true, is.Jdet, is.DT, is.Q_Face, is.Flux_Face, SortedEdgeKeys, EdgeQ1, EdgeQ2)
+		if c.Dissipation != nil {
+			c.Dissipation.CalculateElementViscosity(myThread, c.Q)
+		}
+	case 2:
+		if c.Dissipation != nil {
+			c.Dissipation.propagateEpsilonMaxToVertices(myThread)
+		}
+	case 3:
+		// Pseudo time step for each element, only the first point of each element is used
+		for k := 0; k < Kmax; k++ {
+			DT.DataP[k] = is.CFL / DT.DataP[k]
+		}
+		if c.Dissipation != nil {
+			c.Dissipation.CalculateEpsilonGradient(c, C0, myThread, Q0)
+		}
+		if c.Viscous != nil {
+			c.Viscous.CalculateViscousFlux(c, myThread, Q0)
+		}
+	case 4:
+		if c.Dissipation != nil {
+			c.StoreGradientEdgeFlux(SortedEdgeKeys, EdgeQ1)
+		}
+		if c.Viscous != nil {
+			c.Viscous.StoreViscousEdgeFlux(c, SortedEdgeKeys, EdgeQ1)
+		}
+	case 5:
+		c.SetRTFluxInternal(Kmax, Jdet, Jinv, F_RT_DOF, Q0) // Updates F_RT_DOF with values from Q
+		c.SetRTFluxOnEdges(myThread, Kmax, F_RT_DOF)
+		c.RHSInternalPoints(myThread, Kmax, Jdet, F_RT_DOF, RHSQ)
+		if c.FluxCalcAlgo.IsEntropyStable() {
+			c.AddEntropyConservativeVolumeFlux(myThread, Kmax, Jdet, Jinv, Q0, RHSQ, is.EntropyScratch[myThread])
+		}
+		if c.Dissipation != nil {
+			c.Dissipation.AddDissipation(c, C0, myThread, Jinv, Jdet, Q0, RHSQ)
+		}
+		if c.Viscous != nil {
+			c.Viscous.AddViscousFlux(c, myThread, Jinv, Jdet, RHSQ)
+		}
+		for k := 0; k < Kmax; k++ {
+			is.Diag[myThread][k] = is.AssembleDiagonal(c, myThread, k)
+		}
+		is.SolveLinearSystem(c, myThread)
+		is.Relaxed[myThread] = is.UpdateSolution(c, myThread)
+		is.LimitedPoints[myThread] = c.Limiter.LimitSolution(myThread, c.Q, is.Residual)
+		is.ResidualMax[myThread] = GetShardResidualMax(is.Residual[myThread])
+	}
+	return
+}
+
+func (is *ImplicitSolver) AssembleDiagonal(c *Euler, myThread, k int) (D utils.BlockMatrix) {
+	/*
+		Assembles and factors the diagonal block of [I/dTau - dRHS/dQ] for element k, where:
+			RHS_i = -1/||J|| * (DivInt[i,j]*Fr_j + DivInt[i,Nint+j]*Gs_j + DivInt[i,2*Nint+e]*Fn_e*IInII_e)
+		The interior fluxes Fr_j, Gs_j depend only on the solution at point j. The edge flux is linearized as
+			dFn_e/dQL = 0.5 * (A_n(QL_e) + lambda_e * I), QL_e = EdgeInterp[e,j] * Q_j
+		where A_n is the flux Jacobian normal to the edge and lambda_e is the max wave speed on either side of the edge
+	*/
+	var (
+		dfr        = c.dfr
+		Np         = is.NpInt
+		Nint       = dfr.FluxElement.NpInt
+		NpFlux     = is.NpFlux
+		Nedge      = is.Nedge
+		Kmax       = is.Kmax[myThread]
+		Jdet, Jinv = is.Jdet[myThread], is.Jinv[myThread]
+		oojd       = 1. / Jdet.DataP[k]
+		divD       = dfr.FluxElement.DivInt.DataP
+		interpD    = dfr.FluxEdgeInterp.DataP
+		kGlobal    = c.Partitions.GetGlobalK(k, myThread)
+		Q_Face     = is.Q_Face[myThread]
+		blocks     = is.blockScratch[myThread]
+		err        error
+	)
+	for i := range blocks {
+		blocks[i] = [16]float64{}
+	}
+	// Interior flux points
+	for j := 0; j < Np; j++ {
+		Fr, Gs := c.FluxJacobianTransformed(k, Kmax, j, Jdet, Jinv, c.Q[myThread])
+		for i := 0; i < Np; i++ {
+			a, b := divD[j+i*NpFlux]*oojd, divD[j+Nint+i*NpFlux]*oojd
+			blk := &blocks[j+i*Np]
+			for ii := 0; ii < 16; ii++ {
+				blk[ii] += a*Fr[ii] + b*Gs[ii]
+			}
+		}
+	}
+	// Edge flux points
+	for edgeNum := 0; edgeNum < 3; edgeNum++ {
+		var (
+			shift              = edgeNum * Nedge
+			IInII              = dfr.IInII.DataP[kGlobal+dfr.K*edgeNum]
+			normal             = c.GetFaceNormal(kGlobal, edgeNum)
+			kGR, edgeNumR, nbr = c.getEdgeNeighbor(kGlobal, edgeNum)
+		)
+		for e := 0; e < Nedge; e++ {
+			An, lambda := c.NormalFluxJacobian(getFaceState(Q_Face, k, Kmax, shift+e), normal)
+			if nbr {
+				kR, KmaxR, thR := c.Partitions.GetLocalK(kGR)
+				_, lambdaR := c.NormalFluxJacobian(
+					getFaceState(is.Q_Face[thR], kR, KmaxR, edgeNumR*Nedge+Nedge-1-e), normal)
+				lambda = math.Max(lambda, lambdaR)
+			}
+			for ii := 0; ii < 4; ii++ {
+				An[ii*5] += lambda
+			}
+			for i := 0; i < Np; i++ {
+				d := 0.5 * IInII * oojd * divD[2*Nint+shift+e+i*NpFlux]
+				for j := 0; j < Np; j++ {
+					w := d * interpD[j+(shift+e)*Np]
+					blk := &blocks[j+i*Np]
+					for ii := 0; ii < 16; ii++ {
+						blk[ii] += w * An[ii]
+					}
+				}
+			}
+		}
+	}
+	// Pseudo time term
+	oodt := 1. / is.DT[myThread].DataP[k]
+	if c.Viscous != nil {
+		// The viscous terms are not linearized, their spectral radius bounds the update as the CFL grows
+		oodt += is.viscousSpectralRadius(c, myThread, k)
+	}
+	for i := 0; i < Np; i++ {
+		blk := &blocks[i+i*Np]
+		for ii := 0; ii < 4; ii++ {
+			blk[ii*5] += oodt
+		}
+	}
+	D = utils.NewBlockMatrix(Np, Np)
+	for i := 0; i < Np; i++ {
+		for j := 0; j < Np; j++ {
+			data := blocks[j+i*Np]
+			D.M[i][j] = utils.NewMatrix(4, 4, data[:])
+		}
+	}
+	if err = D.LUPDecompose(); err != nil {
+		err = fmt.Errorf("unable to factor implicit diagonal block of element %d: %s", kGlobal, err.Error())
+		panic(err)
+	}
+	return
+}
+
+func (is *ImplicitSolver) viscousSpectralRadius(c *Euler, myThread, k int) (radius float64) {
+	var (
+		dfr     = c.dfr
+		Kmax    = is.Kmax[myThread]
+		Nedge   = is.Nedge
+		Np12    = float64((dfr.N + 1) * (dfr.N + 1))
+		kGlobal = c.Partitions.GetGlobalK(k, myThread)
+	)
+	for edgeNum := 0; edgeNum < 3; edgeNum++ {
+		edgeLen := dfr.Tris.Edges[dfr.EdgeNumber[kGlobal+dfr.K*edgeNum]].GetEdgeLength()
+		fs := 0.5 * Np12 * edgeLen / is.Jdet[myThread].DataP[k]
+		for e := 0; e < Nedge; e++ {
+			nu := c.Viscous.GetDiffusivity(is.Q_Face[myThread], k+(edgeNum*Nedge+e)*Kmax)
+			radius = math.Max(radius, fs*fs*nu)
+		}
+	}
+	return
+}
+
+func (is *ImplicitSolver) SolveLinearSystem(c *Euler, myThread int) {
+	var (
+		Kmax = is.Kmax[myThread]
+		DQ   = is.Residual[myThread]
+	)
+	for n := 0; n < 4; n++ {
+		for i := range DQ[n].DataP {
+			DQ[n].DataP[i] = 0
+		}
+	}
+	switch is.Method {
+	case IMPLICIT_BlockJacobi:
+		for k := 0; k < Kmax; k++ {
+			is.relaxElement(c, myThread, k, false)
+		}
+	case IMPLICIT_LUSGS:
+		for sweep := 0; sweep < is.Sweeps; sweep++ {
+			for k := 0; k < Kmax; k++ {
+				is.relaxElement(c, myThread, k, true)
+			}
+			for k := Kmax - 1; k >= 0; k-- {
+				is.relaxElement(c, myThread, k, true)
+			}
+		}
+	}
+}
+
+func (is *ImplicitSolver) relaxElement(c *Euler, myThread, k int, coupled bool) {
+	/*
+		Solves D_k * dQ_k = RHS_k - Sum(O_k,nbr * dQ_nbr) using the latest dQ of the neighbors within this partition
+	*/
+	var (
+		Np    = is.NpInt
+		Kmax  = is.Kmax[myThread]
+		RHSQ  = is.RHSQ[myThread]
+		DQ    = is.Residual[myThread]
+		rhs   = is.rhsScratch[myThread]
+		x     utils.BlockMatrix
+		err   error
+		Nedge = is.Nedge
+	)
+	for i := 0; i < Np; i++ {
+		ind := k + i*Kmax
+		for n := 0; n < 4; n++ {
+			rhs[i].DataP[n] = RHSQ[n].DataP[ind]
+		}
+	}
+	if coupled {
+		var (
+			dfr     = c.dfr
+			Nint    = dfr.FluxElement.NpInt
+			NpFlux  = is.NpFlux
+			oojd    = 1. / is.Jdet[myThread].DataP[k]
+			divD    = dfr.FluxElement.DivInt.DataP
+			interpD = dfr.FluxEdgeInterp.DataP
+			kGlobal = c.Partitions.GetGlobalK(k, myThread)
+			Q_Face  = is.Q_Face[myThread]
+		)
+		for edgeNum := 0; edgeNum < 3; edgeNum++ {
+			kGR, edgeNumR, nbr := c.getEdgeNeighbor(kGlobal, edgeNum)
+			if !nbr {
+				continue
+			}
+			kR, KmaxR, thR := c.Partitions.GetLocalK(kGR)
+			if thR != myThread {
+				continue
+			}
+			var (
+				shift, shiftR = edgeNum * Nedge, edgeNumR * Nedge
+				IInII         = dfr.IInII.DataP[kGlobal+dfr.K*edgeNum]
+				normal        = c.GetFaceNormal(kGlobal, edgeNum)
+			)
+			for e := 0; e < Nedge; e++ {
+				var (
+					eR  = shiftR + Nedge - 1 - e // Edge points of the neighbor are in reverse order
+					dqR [4]float64
+					w   [4]float64
+				)
+				for n := 0; n < 4; n++ {
+					for j := 0; j < Np; j++ {
+						dqR[n] += interpD[j+eR*Np] * DQ[n].DataP[kR+j*KmaxR]
+					}
+				}
+				_, lambda := c.NormalFluxJacobian(getFaceState(Q_Face, k, Kmax, shift+e), normal)
+				An, lambdaR := c.NormalFluxJacobian(getFaceState(Q_Face, kR, KmaxR, eR), normal)
+				lambda = math.Max(lambda, lambdaR)
+				for n := 0; n < 4; n++ {
+					w[n] = -lambda * dqR[n]
+					for m := 0; m < 4; m++ {
+						w[n] += An[m+n*4] * dqR[m]
+					}
+				}
+				for i := 0; i < Np; i++ {
+					d := 0.5 * IInII * oojd * divD[2*Nint+shift+e+i*NpFlux]
+					for n := 0; n < 4; n++ {
+						rhs[i].DataP[n] -= d * w[n]
+					}
+				}
+			}
+		}
+	}
+	if x, err = is.Diag[myThread][k].LUPSolve(rhs); err != nil {
+		panic(err)
+	}
+	for i := 0; i < Np; i++ {
+		ind := k + i*Kmax
+		for n := 0; n < 4; n++ {
+			DQ[n].DataP[ind] = x.M[i][0].DataP[n]
+		}
+	}
+}
+
+func (is *ImplicitSolver) UpdateSolution(c *Euler, myThread int) (relaxed int) {
+	/*
+		Adds the update to the solution, halving the update of any element where it would produce a negative density
+		or pressure. The update stored in the residual is the one actually applied.
+	*/
+	var (
+		Np   = is.NpInt
+		Kmax = is.Kmax[myThread]
+		Q    = c.Q[myThread]
+		DQ   = is.Residual[myThread]
+	)
+	physical := func(k int, relax float64) bool {
+		for i := 0; i < Np; i++ {
+			ind := k + i*Kmax
+			var q [4]float64
+			for n := 0; n < 4; n++ {
+				q[n] = Q[n].DataP[ind] + relax*DQ[n].DataP[ind]
+			}
+			if !(q[0] > 0) || !(c.FSFar.GetFlowFunctionQQ(q, StaticPressure) > 0) {
+				return false
+			}
+		}
+		return true
+	}
+	for k := 0; k < Kmax; k++ {
+		relax := 1.
+		for !physical(k, relax) {
+			relax *= 0.5
+			if relax < 1.e-3 {
+				relax = 0
+				break
+			}
+		}
+		if relax != 1 {
+			relaxed++
+		}
+		for n := 0; n < 4; n++ {
+			for i := 0; i < Np; i++ {
+				ind := k + i*Kmax
+				DQ[n].DataP[ind] *= relax
+				Q[n].DataP[ind] += DQ[n].DataP[ind]
+			}
+		}
+	}
+	return
+}
+
+func (is *ImplicitSolver) updateCFL(c *Euler) {
+	/*
+		The CFL grows geometrically while the steady residual is well behaved, and is halved when the update had to be
+		relaxed or the residual jumped up
+	*/
+	var relaxed int
+	for _, r := range is.Relaxed {
+		relaxed += r
+	}
+	is.lastRHSNorm, is.RHSNorm = is.RHSNorm, c.GetResidualNorms(is.RHSQ)[ResL2]
+	switch {
+	case relaxed != 0, math.IsNaN(is.RHSNorm), is.lastRHSNorm != 0 && is.RHSNorm > 2*is.lastRHSNorm:
+		is.CFL = math.Max(is.CFLMin, 0.5*is.CFL)
+	default:
+		is.CFL = math.Min(is.CFLMax, is.CFLGrowth*is.CFL)
+	}
+}
+
+func (c *Euler) getEdgeNeighbor(kGlobal, edgeNum int) (kGlobalR, edgeNumR int, ok bool) {
+	e := c.dfr.Tris.Edges[c.dfr.EdgeNumber[kGlobal+c.dfr.K*edgeNum]]
+	if e.NumConnectedTris != 2 {
+		return
+	}
+	switch kGlobal {
+	case int(e.ConnectedTris[0]):
+		kGlobalR, edgeNumR, ok = int(e.ConnectedTris[1]), int(e.ConnectedTriEdgeNumber[1]), true
+	case int(e.ConnectedTris[1]):
+		kGlobalR, edgeNumR, ok = int(e.ConnectedTris[0]), int(e.ConnectedTriEdgeNumber[0]), true
+	}
+	return
+}
+
+func getFaceState(Q_Face [4]utils.Matrix, k, Kmax, i int) (q [4]float64) {
+	ind := k + i*Kmax
+	for n := 0; n < 4; n++ {
+		q[n] = Q_Face[n].DataP[ind]
+	}
+	return
+}
+
+func (c *Euler) NormalFluxJacobian(q [4]float64, normal [2]float64) (An [16]float64, lambda float64) {
+	/*
+		Jacobian of the flux normal to an edge, A_n = nx*dF/dQ + ny*dG/dQ, and the max wave speed |U.n| + C
+	*/
+	Fx, Gy := c.FluxJacobianCalc(q[0], q[1], q[2], q[3])
+	for ii := range An {
+		An[ii] = normal[0]*Fx[ii] + normal[1]*Gy[ii]
+	}
+	un := (q[1]*normal[0] + q[2]*normal[1]) / q[0]
+	lambda = math.Abs(un) + c.FSFar.GetFlowFunctionQQ(q, SoundSpeed)
+	return
+}
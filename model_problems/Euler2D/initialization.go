@@ -4,7 +4,12 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/notargets/gocfd/model_problems/Euler1D/exact_riemann"
 	"github.com/notargets/gocfd/model_problems/Euler2D/isentropic_vortex"
+	"github.com/notargets/gocfd/model_problems/Euler2D/ringleb"
+	"github.com/notargets/gocfd/model_problems/Euler2D/sod_shock_tube"
+	"github.com/notargets/gocfd/model_problems/Euler2D/supersonic_vortex"
+	"github.com/notargets/gocfd/types"
 	"github.com/notargets/gocfd/utils"
 )
 
@@ -19,15 +24,22 @@ const (
 	FREESTREAM InitType = iota
 	IVORTEX
 	SHOCKTUBE
+	MMS
+	RINGLEB
+	SVORTEX
 )
 
 var (
 	InitNames = map[string]InitType{
-		"freestream": FREESTREAM,
-		"ivortex":    IVORTEX,
-		"shocktube":  SHOCKTUBE,
+		"freestream":       FREESTREAM,
+		"ivortex":          IVORTEX,
+		"shocktube":        SHOCKTUBE,
+		"mms":              MMS,
+		"ringleb":          RINGLEB,
+		"supersonicvortex": SVORTEX,
 	}
-	InitPrintNames = []string{"Freestream", "Inviscid Vortex Analytic Solution", "Shock Tube"}
+	InitPrintNames = []string{"Freestream", "Inviscid Vortex Analytic Solution", "Shock Tube", "Manufactured Solution",
+		"Ringleb Flow Analytic Solution", "Supersonic Vortex Analytic Solution"}
 )
 
 func NewInitType(label string) (it InitType) {
@@ -81,3 +93,102 @@ func (c *Euler) InitializeIVortex(X, Y utils.Matrix) (iv *isentropic_vortex.IVor
 	}
 	return
 }
+
+func (c *Euler) InitializeExactState(es ExactState, gamma, xRef, yRef float64) (count int) {
+	/*
+		Sets the solution to a steady exact solution, with the freestream at the reference location. The inflow,
+		outflow and far field boundaries are set to the exact state, the walls are kept
+	*/
+	var (
+		NP  = c.Partitions.ParallelDegree
+		qq  [4]float64
+		bcs = map[types.BCFLAG]bool{types.BC_In: true, types.BC_Out: true, types.BC_Far: true}
+	)
+	c.AnalyticSolution = es
+	qq[0], qq[1], qq[2], qq[3] = es.GetStateC(0, xRef, yRef)
+	c.FSFar = NewFreestreamFromQinf(gamma, qq)
+	c.SolutionX = c.ShardByK(c.dfr.SolutionX)
+	c.SolutionY = c.ShardByK(c.dfr.SolutionY)
+	for np := 0; np < NP; np++ {
+		var (
+			Np, Kmax = c.SolutionX[np].Dims()
+			xD, yD   = c.SolutionX[np].DataP, c.SolutionY[np].DataP
+		)
+		for n := 0; n < 4; n++ {
+			c.Q[np][n] = utils.NewMatrix(Np, Kmax)
+		}
+		for ind := 0; ind < Np*Kmax; ind++ {
+			c.Q[np][0].DataP[ind], c.Q[np][1].DataP[ind], c.Q[np][2].DataP[ind], c.Q[np][3].DataP[ind] =
+				es.GetStateC(0, xD[ind], yD[ind])
+		}
+	}
+	for _, e := range c.dfr.Tris.Edges {
+		if bcs[e.BCType] {
+			e.BCType = types.BC_Exact
+			count++
+		}
+	}
+	return
+}
+
+func (c *Euler) InitializeShockTube(rp *exact_riemann.RiemannProblem) {
+	/*
+		Sets the solution to the left and right states of the Riemann problem, divided at the diaphragm. Each element
+		takes the state at its centroid, so the initial solution is exact for a mesh with element edges at the
+		diaphragm. The inflow and outflow boundaries are held at the left and right states, the tube length should be
+		long enough to keep the waves from reaching them
+	*/
+	var (
+		NP = c.Partitions.ParallelDegree
+		es = sod_shock_tube.NewExactShockTube(rp)
+	)
+	conserved := func(s exact_riemann.State) (qq [4]float64) {
+		qq[0], qq[1], qq[3] = s.Conserved(rp.Gamma)
+		return
+	}
+	c.AnalyticSolution = es
+	c.FSIn = NewFreestreamFromQinf(rp.Gamma, conserved(rp.Left))
+	c.FSOut = NewFreestreamFromQinf(rp.Gamma, conserved(rp.Right))
+	c.SolutionX = c.ShardByK(c.dfr.SolutionX)
+	c.SolutionY = c.ShardByK(c.dfr.SolutionY)
+	for np := 0; np < NP; np++ {
+		var (
+			Np, Kmax = c.SolutionX[np].Dims()
+			xD       = c.SolutionX[np].DataP
+		)
+		for n := 0; n < 4; n++ {
+			c.Q[np][n] = utils.NewMatrix(Np, Kmax)
+		}
+		for k := 0; k < Kmax; k++ {
+			var xC float64
+			for i := 0; i < Np; i++ {
+				xC += xD[k+i*Kmax] / float64(Np)
+			}
+			qq := c.FSOut.Qinf
+			if xC < rp.X0 {
+				qq = c.FSIn.Qinf
+			}
+			for i := 0; i < Np; i++ {
+				for n := 0; n < 4; n++ {
+					c.Q[np][n].DataP[k+i*Kmax] = qq[n]
+				}
+			}
+		}
+	}
+}
+
+func WriteExactSolutionMesh(it InitType, fileName string, n int) (err error) {
+	// Writes an SU2 mesh of the domain of an exact solution, n is the number of elements across the domain
+	if n < 1 {
+		return fmt.Errorf("unable to generate a mesh with %d elements across the domain", n)
+	}
+	switch it {
+	case RINGLEB:
+		err = ringleb.NewRinglebDefault().WriteMesh(fileName, n)
+	case SVORTEX:
+		err = supersonic_vortex.NewSVortexDefault().WriteMesh(fileName, n)
+	default:
+		err = fmt.Errorf("no mesh generator for init type [%s]", it.Print())
+	}
+	return
+}
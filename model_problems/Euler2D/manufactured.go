@@ -0,0 +1,203 @@
+package Euler2D
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/notargets/gocfd/types"
+	"github.com/notargets/gocfd/utils"
+)
+
+/*
+The method of manufactured solutions verifies the order of accuracy using a chosen smooth solution instead of one of
+the few known exact solutions. The chosen solution does not satisfy the Euler equations, so the solver adds the
+residual of the equations as a source term, S = div(F,G), which makes the chosen solution exact:
+
+	dQ/dt + div(F,G) = S
+
+The manufactured solutions are steady, each of density, velocity and pressure has the trigonometric form of Roy et
+al., "Verification of Euler/Navier-Stokes codes using the method of manufactured solutions", IJNMF 44, 2004:
+
+	phi = phi0 + phiX sin(aX pi x) + phiY cos(aY pi y) + phiXY cos(aXY pi x y)
+
+The coordinates (x, y) are those of the mesh, shifted to the lower left corner of its bounding box and scaled by the
+largest extent, so the same solution resolves similarly on any mesh. All boundaries are set to the exact solution.
+*/
+type ManufacturedSolutionType uint8
+
+const (
+	MMS_SUBSONIC   ManufacturedSolutionType = iota // Mach 0.5
+	MMS_SUPERSONIC                                 // Mach 2.3, all boundaries are inflow or outflow
+	MMS_DENSITY                                    // Density varies in a uniform velocity and pressure
+)
+
+var (
+	ManufacturedSolutionNames = map[string]ManufacturedSolutionType{
+		"subsonic":   MMS_SUBSONIC,
+		"supersonic": MMS_SUPERSONIC,
+		"density":    MMS_DENSITY,
+	}
+	ManufacturedSolutionPrintNames = []string{"Subsonic", "Supersonic", "Density"}
+)
+
+func (mt ManufacturedSolutionType) Print() string {
+	return ManufacturedSolutionPrintNames[mt]
+}
+
+func NewManufacturedSolutionType(label string) (mt ManufacturedSolutionType) {
+	var (
+		ok  bool
+		err error
+	)
+	if len(label) == 0 {
+		return MMS_SUBSONIC
+	}
+	label = strings.ToLower(strings.TrimSpace(label))
+	if mt, ok = ManufacturedSolutionNames[label]; !ok {
+		err = fmt.Errorf("unable to use manufactured solution named [%s]", label)
+		panic(err)
+	}
+	return
+}
+
+type ManufacturedField struct {
+	Phi0, PhiX, PhiY, PhiXY float64 // Constant and amplitudes
+	AX, AY, AXY             float64 // Wave numbers
+}
+
+func (mf ManufacturedField) Get(x, y float64) (phi, dPhidX, dPhidY float64) {
+	var (
+		pi = math.Pi
+	)
+	phi = mf.Phi0 + mf.PhiX*math.Sin(mf.AX*pi*x) + mf.PhiY*math.Cos(mf.AY*pi*y) + mf.PhiXY*math.Cos(mf.AXY*pi*x*y)
+	sXY := mf.PhiXY * mf.AXY * pi * math.Sin(mf.AXY*pi*x*y)
+	dPhidX = mf.PhiX*mf.AX*pi*math.Cos(mf.AX*pi*x) - sXY*y
+	dPhidY = -mf.PhiY*mf.AY*pi*math.Sin(mf.AY*pi*y) - sXY*x
+	return
+}
+
+type ManufacturedSolution struct {
+	Type         ManufacturedSolutionType
+	Rho, U, V, P ManufacturedField
+	Gamma        float64
+	X0, Y0, L    float64 // Origin and length scale of the coordinates
+}
+
+func NewManufacturedSolution(mt ManufacturedSolutionType, gamma, X0, Y0, L float64) (ms *ManufacturedSolution) {
+	ms = &ManufacturedSolution{
+		Type:  mt,
+		Gamma: gamma,
+		X0:    X0,
+		Y0:    Y0,
+		L:     L,
+		Rho:   ManufacturedField{1, 0.15, -0.1, 0.08, 0.75, 1, 1.25},
+	}
+	switch mt {
+	case MMS_SUBSONIC:
+		ms.U = ManufacturedField{0.5, 0.06, -0.04, 0.03, 1.5, 0.6, 0.9}
+		ms.V = ManufacturedField{0.3, -0.05, 0.06, 0.04, 1.5, 1, 0.9}
+		ms.P = ManufacturedField{1, 0.2, 0.1, -0.1, 2, 1, 0.75}
+	case MMS_SUPERSONIC:
+		ms.U = ManufacturedField{2, 0.1, -0.06, 0.05, 1.5, 0.6, 0.9}
+		ms.V = ManufacturedField{0.6, -0.08, 0.1, 0.04, 1.5, 1, 0.9}
+		ms.P = ManufacturedField{0.5, 0.05, 0.03, -0.04, 2, 1, 0.75}
+	case MMS_DENSITY:
+		ms.Rho = ManufacturedField{1, 0.2, 0.1, 0.05, 1, 1, 1}
+		ms.U = ManufacturedField{Phi0: 0.5}
+		ms.V = ManufacturedField{Phi0: 0.3}
+		ms.P = ManufacturedField{Phi0: 1}
+	}
+	return
+}
+
+func (ms *ManufacturedSolution) GetState(x, y float64) (prim, dPdX, dPdY [4]float64) {
+	// Primitive variables [rho, u, v, p] and their derivatives in the mesh coordinates
+	var (
+		xs, ys = (x - ms.X0) / ms.L, (y - ms.Y0) / ms.L
+		ooL    = 1. / ms.L
+	)
+	for n, mf := range [4]ManufacturedField{ms.Rho, ms.U, ms.V, ms.P} {
+		prim[n], dPdX[n], dPdY[n] = mf.Get(xs, ys)
+		dPdX[n] *= ooL
+		dPdY[n] *= ooL
+	}
+	return
+}
+
+func (ms *ManufacturedSolution) GetStateC(t, x, y float64) (rho, rhoU, rhoV, E float64) {
+	prim, _, _ := ms.GetState(x, y)
+	rho, u, v, p := prim[0], prim[1], prim[2], prim[3]
+	rhoU, rhoV, E = rho*u, rho*v, p/(ms.Gamma-1.)+0.5*rho*(u*u+v*v)
+	return
+}
+
+func (ms *ManufacturedSolution) GetDivergence(t, x, y float64) (div [4]float64) {
+	// Divergence of the fluxes (F,G) of the manufactured solution, which is the source term of the steady solution
+	var (
+		prim, dX, dY   = ms.GetState(x, y)
+		rho, u, v, p   = prim[0], prim[1], prim[2], prim[3]
+		rhoX, uX, vX   = dX[0], dX[1], dX[2]
+		rhoY, uY, vY   = dY[0], dY[1], dY[2]
+		pX, pY         = dX[3], dY[3]
+		OOGM1          = 1. / (ms.Gamma - 1.)
+		q2             = u*u + v*v
+		H              = p*OOGM1 + 0.5*rho*q2 + p // Total enthalpy per unit volume, E+p
+		HX             = (OOGM1+1.)*pX + 0.5*rhoX*q2 + rho*(u*uX+v*vX)
+		HY             = (OOGM1+1.)*pY + 0.5*rhoY*q2 + rho*(u*uY+v*vY)
+		rhoUX, rhoVY   = rhoX*u + rho*uX, rhoY*v + rho*vY // d(rhoU)/dx, d(rhoV)/dy
+		rhoUUX, rhoVVY = rhoUX*u + rho*u*uX, rhoVY*v + rho*v*vY
+	)
+	div[0] = rhoUX + rhoVY
+	div[1] = rhoUUX + pX + rhoVY*u + rho*v*uY
+	div[2] = rhoUX*v + rho*u*vX + rhoVVY + pY
+	div[3] = uX*H + u*HX + vY*H + v*HY
+	return
+}
+
+func (c *Euler) InitializeManufactured(mt ManufacturedSolutionType, gamma float64) {
+	/*
+		Sets the solution to the manufactured solution, along with its source terms at the solution points and the
+		exact state on all boundaries, including edges without a boundary condition in the mesh
+	*/
+	var (
+		VX, VY = c.dfr.VX, c.dfr.VY
+		X0, Y0 = VX.Min(), VY.Min()
+		L      = math.Max(VX.Max()-X0, VY.Max()-Y0)
+		ms     = NewManufacturedSolution(mt, gamma, X0, Y0, L)
+		NP     = c.Partitions.ParallelDegree
+	)
+	c.AnalyticSolution = ms
+	c.FSFar = NewFreestreamFromQinf(gamma, ms.conservedAt(X0+0.5*L, Y0+0.5*L))
+	c.SolutionX = c.ShardByK(c.dfr.SolutionX)
+	c.SolutionY = c.ShardByK(c.dfr.SolutionY)
+	c.SourceTerm = make([][4]utils.Matrix, NP)
+	for np := 0; np < NP; np++ {
+		var (
+			Np, Kmax = c.SolutionX[np].Dims()
+			xD, yD   = c.SolutionX[np].DataP, c.SolutionY[np].DataP
+		)
+		for n := 0; n < 4; n++ {
+			c.Q[np][n] = utils.NewMatrix(Np, Kmax)
+			c.SourceTerm[np][n] = utils.NewMatrix(Np, Kmax)
+		}
+		for ind := 0; ind < Np*Kmax; ind++ {
+			qq := ms.conservedAt(xD[ind], yD[ind])
+			div := ms.GetDivergence(0, xD[ind], yD[ind])
+			for n := 0; n < 4; n++ {
+				c.Q[np][n].DataP[ind] = qq[n]
+				c.SourceTerm[np][n].DataP[ind] = div[n]
+			}
+		}
+	}
+	for _, e := range c.dfr.Tris.Edges {
+		if e.NumConnectedTris == 1 { // Periodic edges connect two elements, they are not boundaries
+			e.BCType = types.BC_Exact
+		}
+	}
+}
+
+func (ms *ManufacturedSolution) conservedAt(x, y float64) (Q [4]float64) {
+	Q[0], Q[1], Q[2], Q[3] = ms.GetStateC(0, x, y)
+	return
+}
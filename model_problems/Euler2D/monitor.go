@@ -0,0 +1,170 @@
+package Euler2D
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image/png"
+	"math"
+	"net"
+	"net/http"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+/*
+The Monitor serves the state of a running solution over HTTP, for long runs without anyone watching the terminal.
+
+	/status     JSON of the iteration, time, dt, residuals, limited points and memory use
+	/image.png  The plot field, rendered using the zoom and translation of the PlotMeta
+
+JSON has no NaN or Inf, so non-finite values in the status, e.g. the residual of a diverging run, are sent as zero and
+listed by name in nonFinite, e.g. "nonFinite": {"residual[4]": "NaN"}.
+
+The solver hands the monitor a snapshot between time steps, every StepsBeforePlot iterations alongside PrintUpdate.
+The handlers only read the snapshot under the lock, never the solution, so they are safe to run concurrently with the
+worker shards.
+*/
+type Monitor struct {
+	renderer *FrameRenderer
+	server   *http.Server
+	listener net.Listener
+	mu       sync.RWMutex
+	status   MonitorStatus
+	image    []byte // PNG of the plot field
+}
+
+type MonitorStatus struct {
+	ResidualRecord
+	FinalTime         float64           `json:"finalTime"`
+	Finished          bool              `json:"finished"`
+	Field             string            `json:"field"`
+	SolutionPoints    int               `json:"solutionPoints"`
+	PositivityLimited int               `json:"positivityLimited"` // Elements with the positivity limiter active
+	Memory            MonitorMemory     `json:"memory"`
+	Updated           time.Time         `json:"updated"`
+	NonFinite         map[string]string `json:"nonFinite,omitempty"` // Values of the fields sent as zero
+}
+
+type MonitorMemory struct {
+	AllocMiB      uint64 `json:"allocMiB"`
+	TotalAllocMiB uint64 `json:"totalAllocMiB"`
+	SysMiB        uint64 `json:"sysMiB"`
+	NumGC         uint32 `json:"numGC"`
+}
+
+func NewMonitor(address string, width, height int) (m *Monitor, err error) {
+	// Starts serving on address, e.g. ":8080", use port 0 for any free port and get it from Address
+	m = &Monitor{
+		renderer: NewFrameRenderer("", IMAGE_PNG, width, height, false),
+	}
+	if m.listener, err = net.Listen("tcp", address); err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", m.handleStatus)
+	mux.HandleFunc("/status", m.handleStatus)
+	mux.HandleFunc("/image.png", m.handleImage)
+	m.server = &http.Server{Handler: mux}
+	go func() {
+		_ = m.server.Serve(m.listener)
+	}()
+	return
+}
+
+func (m *Monitor) Address() string {
+	return m.listener.Addr().String()
+}
+
+func (m *Monitor) Close() error {
+	return m.server.Close()
+}
+
+func (c *Euler) UpdateMonitor(m *Monitor, pm *PlotMeta, rec ResidualRecord, finished bool) {
+	// Must be called between time steps, while the worker shards are idle
+	var (
+		st = MonitorStatus{
+			ResidualRecord: rec,
+			FinalTime:      c.FinalTime,
+			Finished:       finished,
+			Field:          pm.Field.String(),
+			SolutionPoints: c.dfr.K * c.dfr.SolutionElement.Np,
+			Updated:        time.Now(),
+		}
+		ms  runtime.MemStats
+		buf bytes.Buffer
+	)
+	if c.Limiter != nil && c.Limiter.Positivity != nil {
+		st.PositivityLimited = c.Limiter.Positivity.LimitedCount
+	}
+	runtime.ReadMemStats(&ms)
+	st.Memory = MonitorMemory{
+		AllocMiB:      ms.Alloc / 1024 / 1024,
+		TotalAllocMiB: ms.TotalAlloc / 1024 / 1024,
+		SysMiB:        ms.Sys / 1024 / 1024,
+		NumGC:         ms.NumGC,
+	}
+	if err := png.Encode(&buf, c.RenderFrame(pm, m.renderer)); err != nil {
+		panic(err)
+	}
+	m.mu.Lock()
+	m.status, m.image = st, buf.Bytes()
+	m.mu.Unlock()
+}
+
+func (m *Monitor) Status() (st MonitorStatus) {
+	m.mu.RLock()
+	st = m.status
+	m.mu.RUnlock()
+	return
+}
+
+func (m *Monitor) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" && r.URL.Path != "/status" {
+		http.NotFound(w, r)
+		return
+	}
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(m.Status().finite()); err != nil {
+		http.Error(w, "unable to encode the status: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(buf.Bytes())
+}
+
+func (st MonitorStatus) finite() (stF MonitorStatus) {
+	// Replaces non-finite values with zero, recording them in NonFinite
+	stF = st
+	check := func(name string, val *float64) {
+		if math.IsNaN(*val) || math.IsInf(*val, 0) {
+			if stF.NonFinite == nil {
+				stF.NonFinite = make(map[string]string)
+			}
+			stF.NonFinite[name] = strconv.FormatFloat(*val, 'g', -1, 64)
+			*val = 0
+		}
+	}
+	check("time", &stF.Time)
+	check("dt", &stF.DT)
+	for i := range stF.Residual {
+		check(fmt.Sprintf("residual[%d]", i), &stF.Residual[i])
+	}
+	check("wallClock", &stF.WallClock)
+	check("finalTime", &stF.FinalTime)
+	return
+}
+
+func (m *Monitor) handleImage(w http.ResponseWriter, r *http.Request) {
+	m.mu.RLock()
+	img := m.image
+	m.mu.RUnlock()
+	if len(img) == 0 {
+		http.Error(w, "no solution has been rendered yet", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	_, _ = w.Write(img)
+}
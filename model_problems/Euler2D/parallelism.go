@@ -4,10 +4,56 @@ import (
 	"fmt"
 	"math"
 	"runtime"
+	"strings"
+
+	"github.com/notargets/gocfd/DG2D"
 
 	"github.com/notargets/gocfd/utils"
 )
 
+/*
+The elements are sharded into contiguous index ranges by the PartitionMap. The contiguous partitioner uses the element
+numbering of the mesh file as is, the inertial partitioner groups the elements by recursive inertial bisection of the
+element centroids, then renumbers the elements so that each group is one of the contiguous ranges.
+*/
+type PartitionerType uint8
+
+const (
+	PARTITION_Contiguous PartitionerType = iota
+	PARTITION_Inertial
+)
+
+var (
+	PartitionerNames = map[string]PartitionerType{
+		"contiguous": PARTITION_Contiguous,
+		"index":      PARTITION_Contiguous,
+		"inertial":   PARTITION_Inertial,
+		"rib":        PARTITION_Inertial,
+		"bisection":  PARTITION_Inertial,
+	}
+	PartitionerPrintNames = []string{"Contiguous", "Inertial Bisection"}
+)
+
+func (pt PartitionerType) Print() string {
+	return PartitionerPrintNames[pt]
+}
+
+func NewPartitionerType(label string) (pt PartitionerType) {
+	var (
+		ok  bool
+		err error
+	)
+	if len(label) == 0 {
+		return PARTITION_Contiguous
+	}
+	label = strings.ToLower(strings.TrimSpace(label))
+	if pt, ok = PartitionerNames[label]; !ok {
+		err = fmt.Errorf("unable to use partitioner named [%s]", label)
+		panic(err)
+	}
+	return
+}
+
 func (c *Euler) ShardByK(A utils.Matrix) (pA []utils.Matrix) {
 	var (
 		NP      = c.Partitions.ParallelDegree
@@ -117,6 +163,52 @@ func (c *Euler) SetParallelDegree(ProcLimit, Kmax int) {
 	c.Partitions = NewPartitionMap(ParallelDegree, Kmax)
 }
 
+func (c *Euler) PartitionElements(pt PartitionerType) {
+	// Renumbers the elements so that the partitions of the partitioner are the ranges of the PartitionMap
+	var (
+		pm = c.Partitions
+	)
+	if pt == PARTITION_Contiguous || pm.ParallelDegree == 1 {
+		return
+	}
+	sizes := make([]int, pm.ParallelDegree)
+	for np := range sizes {
+		sizes[np] = pm.GetBucketDimension(np)
+	}
+	X, Y := c.dfr.ElementCentroids()
+	part := DG2D.InertialBisection(X, Y, sizes)
+	c.dfr.RenumberElements(DG2D.PartitionOrder(part, pm.ParallelDegree))
+}
+
+func (c *Euler) PartitionStatistics() (edgeCut, interiorEdges int, imbalance float64) {
+	/*
+		The edge cut is the number of interior edges connecting elements in different partitions, the load imbalance is
+		the largest partition size divided by the average partition size
+	*/
+	var (
+		pm      = c.Partitions
+		maxSize int
+	)
+	for _, e := range c.dfr.Tris.Edges {
+		if e.NumConnectedTris != 2 {
+			continue
+		}
+		interiorEdges++
+		bn0, _, _ := pm.GetBucket(int(e.ConnectedTris[0]))
+		bn1, _, _ := pm.GetBucket(int(e.ConnectedTris[1]))
+		if bn0 != bn1 {
+			edgeCut++
+		}
+	}
+	for np := 0; np < pm.ParallelDegree; np++ {
+		if size := pm.GetBucketDimension(np); size > maxSize {
+			maxSize = size
+		}
+	}
+	imbalance = float64(maxSize*pm.ParallelDegree) / float64(pm.MaxIndex)
+	return
+}
+
 func (c *Euler) PartitionEdges() {
 	var (
 		NPar                               = c.Partitions.ParallelDegree
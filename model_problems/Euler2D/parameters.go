@@ -3,6 +3,12 @@ package Euler2D
 import (
 	"fmt"
 	"sort"
+	"strings"
+
+	"github.com/notargets/gocfd/DG2D"
+	"github.com/notargets/gocfd/model_problems/Euler1D/exact_riemann"
+
+	"github.com/notargets/gocfd/types"
 
 	"github.com/ghodss/yaml"
 )
@@ -12,7 +18,10 @@ type InputParameters struct {
 	Title             string                                `yaml:"Title"`
 	CFL               float64                               `yaml:"CFL"`
 	FluxType          string                                `yaml:"FluxType"`
+	TimeIntegrator    string                                `yaml:"TimeIntegrator"` // SSP54 (default), Euler, SSP-RK2, SSP-RK3, RK4 or LSRK4
 	InitType          string                                `yaml:"InitType"`
+	Partitioner       string                                `yaml:"Partitioner"`  // Contiguous (default) or Inertial
+	MeshOrdering      string                                `yaml:"MeshOrdering"` // File (default), RCM or Hilbert
 	PolynomialOrder   int                                   `yaml:"PolynomialOrder"`
 	FinalTime         float64                               `yaml:"FinalTime"`
 	Minf              float64                               `yaml:"Minf"`
@@ -22,8 +31,31 @@ type InputParameters struct {
 	LocalTimeStepping bool                                  `yaml:"LocalTimeStep"`
 	MaxIterations     int                                   `yaml:"MaxIterations"`
 	ImplicitSolver    bool                                  `yaml:"ImplicitSolver"`
+	ImplicitMethod    string                                `yaml:"ImplicitMethod"`    // LU-SGS (default) or Block-Jacobi
+	ImplicitCFLMax    float64                               `yaml:"ImplicitCFLMax"`    // CFL ramp limit, default 1000
+	ImplicitCFLGrowth float64                               `yaml:"ImplicitCFLGrowth"` // CFL ramp factor per iteration, default 1.2
+	ImplicitSweeps    int                                   `yaml:"ImplicitSweeps"`    // Symmetric sweeps per iteration for LU-SGS, default 1
 	Limiter           string                                `yaml:"Limiter"`
 	Kappa             float64                               `yaml:"Kappa"`
+	MomentCenter      [2]float64                            `yaml:"MomentCenter"`    // Reference point for the pitching moment
+	ReferenceLength   float64                               `yaml:"ReferenceLength"` // Chord used to normalize forces, default 1
+	// Convergence criteria for steady runs, a zero value disables the criterion
+	ResidualTarget      float64 `yaml:"ResidualTarget"`      // Absolute residual target
+	ResidualReduction   float64 `yaml:"ResidualReduction"`   // Orders of magnitude reduction from the first iteration
+	ResidualNorm        string  `yaml:"ResidualNorm"`        // One of Res0, Res1, Res2, Res3, L1, L2 (default)
+	ResidualHistoryFile string  `yaml:"ResidualHistoryFile"` // Per iteration residual history, .csv or .json
+	// Navier-Stokes viscous terms are added when the Reynolds number is nonzero
+	Reynolds             float64 `yaml:"Reynolds"`             // Based on freestream velocity and ReferenceLength
+	Prandtl              float64 `yaml:"Prandtl"`              // Default 0.72
+	ViscosityModel       string  `yaml:"ViscosityModel"`       // Sutherland (default) or Constant
+	SutherlandConstant   float64 `yaml:"SutherlandConstant"`   // Kelvin, default 110.4
+	ReferenceTemperature float64 `yaml:"ReferenceTemperature"` // Freestream temperature in Kelvin, default 288.15
+	// Manufactured solution used with InitType MMS
+	ManufacturedSolution string `yaml:"ManufacturedSolution"` // Subsonic (default), Supersonic or Density
+	// Riemann problem used with InitType ShockTube, Sod's problem by default
+	ShockTubeLeft  []float64 `yaml:"ShockTubeLeft"`  // Density, X velocity and pressure left of the diaphragm
+	ShockTubeRight []float64 `yaml:"ShockTubeRight"` // Density, X velocity and pressure right of the diaphragm
+	ShockTubeX0    float64   `yaml:"ShockTubeX0"`    // Diaphragm X location, default 0.5
 }
 
 func (ip *InputParameters) Parse(data []byte) error {
@@ -35,8 +67,36 @@ func (ip *InputParameters) Print() {
 	fmt.Printf("%8.5f\t\t= CFL\n", ip.CFL)
 	fmt.Printf("%8.5f\t\t= FinalTime\n", ip.FinalTime)
 	fmt.Printf("[%s]\t\t\t= Flux Type\n", ip.FluxType)
+	if !ip.ImplicitSolver {
+		fmt.Printf("[%s]\t\t\t= Time Integrator\n", NewTimeIntegratorType(ip.TimeIntegrator).Print())
+	}
 	fmt.Printf("[%s]\t= InitType\n", ip.InitType)
+	if len(ip.ManufacturedSolution) != 0 {
+		fmt.Printf("[%s]\t\t= Manufactured Solution\n", NewManufacturedSolutionType(ip.ManufacturedSolution).Print())
+	}
+	if len(ip.ShockTubeLeft) != 0 || len(ip.ShockTubeRight) != 0 {
+		fmt.Printf("%v, %v\t= Shock Tube Left and Right [rho, u, p], Diaphragm at %5.3f\n",
+			ip.ShockTubeLeft, ip.ShockTubeRight, ip.ShockTubeX0)
+	}
+	if len(ip.Partitioner) != 0 {
+		fmt.Printf("[%s]\t\t= Partitioner\n", NewPartitionerType(ip.Partitioner).Print())
+	}
+	if len(ip.MeshOrdering) != 0 {
+		fmt.Printf("[%s]\t\t= Mesh Ordering\n", DG2D.NewMeshOrdering(ip.MeshOrdering).Print())
+	}
 	fmt.Printf("[%d]\t\t\t\t= Polynomial Order\n", ip.PolynomialOrder)
+	if ip.ImplicitSolver {
+		fmt.Printf("[%s]\t\t\t= Implicit Method, CFL Max = %8.3f, CFL Growth = %5.3f, Sweeps = %d\n",
+			NewImplicitMethod(ip.ImplicitMethod).Print(), ip.ImplicitCFLMax, ip.ImplicitCFLGrowth, ip.ImplicitSweeps)
+	}
+	if ip.ResidualTarget != 0 || ip.ResidualReduction != 0 {
+		fmt.Printf("[%s]\t\t\t= Residual Norm, Target = %8.3e, Reduction = %5.2f orders\n",
+			NewResidualNorm(ip.ResidualNorm).Print(), ip.ResidualTarget, ip.ResidualReduction)
+	}
+	if ip.Reynolds != 0 {
+		fmt.Printf("[%s]\t\t= Viscosity Model, Reynolds = %8.3e, Prandtl = %5.3f\n",
+			NewViscosityModel(ip.ViscosityModel).Print(), ip.Reynolds, ip.Prandtl)
+	}
 	keys := make([]string, len(ip.BCs))
 	i := 0
 	for k := range ip.BCs {
@@ -48,3 +108,48 @@ func (ip *InputParameters) Print() {
 		fmt.Printf("BCs[%s] = %v\n", key, ip.BCs[key])
 	}
 }
+
+func (ip *InputParameters) GetBCParameters(tag types.BCTAG) (params map[string]float64) {
+	/*
+		Returns the parameters in the BCs section for the BC named by tag, e.g. "Wall-inner", merged across the BC
+		numbers listed under that name. Names are matched without case, parameter names are returned in lower case.
+	*/
+	params = make(map[string]float64)
+	for name, bcNums := range ip.BCs {
+		if types.NewBCTAG(name) != tag {
+			continue
+		}
+		for _, bcParams := range bcNums {
+			for key, val := range bcParams {
+				params[strings.ToLower(key)] = val
+			}
+		}
+	}
+	return
+}
+
+func (ip *InputParameters) GetShockTube() (rp *exact_riemann.RiemannProblem) {
+	// The Riemann problem of the shock tube, with defaults from Sod's problem for the unset parameters
+	var (
+		sod   = exact_riemann.NewSod()
+		gamma = ip.Gamma
+		x0    = ip.ShockTubeX0
+	)
+	getState := func(vals []float64, def exact_riemann.State, side string) exact_riemann.State {
+		switch len(vals) {
+		case 0:
+			return def
+		case 3:
+			return exact_riemann.State{Rho: vals[0], U: vals[1], P: vals[2]}
+		}
+		panic(fmt.Errorf("shock tube %s state must be [rho, u, p], have %v", side, vals))
+	}
+	if gamma == 0 {
+		gamma = sod.Gamma
+	}
+	if x0 == 0 {
+		x0 = sod.X0
+	}
+	return exact_riemann.NewRiemannProblem(getState(ip.ShockTubeLeft, sod.Left, "left"),
+		getState(ip.ShockTubeRight, sod.Right, "right"), gamma, x0)
+}
@@ -21,6 +21,8 @@ type PlotMeta struct {
 	FrameTime              time.Duration
 	StepsBeforePlot        int
 	LineType               chart2d.LineType
+	Render                 *FrameRenderer // nil if no image files are written
+	Monitor                *Monitor       // nil if the solution is not served over HTTP
 }
 
 type ChartState struct {
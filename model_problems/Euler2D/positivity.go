@@ -0,0 +1,169 @@
+package Euler2D
+
+import (
+	"math"
+
+	"github.com/notargets/gocfd/DG2D"
+	"github.com/notargets/gocfd/utils"
+)
+
+/*
+Positivity preserving limiter of Zhang and Shu (2010). The solution in each element is scaled toward the element average
+until the density and then the pressure are positive at all solution points and at the flux points on the edges. The
+scaling preserves the element average, so provided the averages are positive the limited solution is conservative and
+positive everywhere the DFR scheme uses it.
+*/
+type PositivityLimiter struct {
+	EdgeInterp   utils.Matrix // Interpolates from solution points to the flux points on the edges
+	Weights      []float64    // Integration weights of the solution points, used for element averages
+	Gamma        float64
+	Limited      [][]bool       // Sharded, true for elements limited since the counts were last updated
+	Values       [][][4]float64 // Sharded scratch area for the solution and edge values of an element
+	LimitedCount int            // Number of elements limited during the last step
+}
+
+func NewPositivityLimiter(dfr *DG2D.DFR2D, pm *PartitionMap, fs *FreeStream) (pl *PositivityLimiter) {
+	var (
+		Np       = dfr.SolutionElement.Np
+		NpEdge   = 3 * dfr.FluxElement.NpEdge
+		MD       = dfr.SolutionElement.MassMatrix.DataP
+		Nthreads = pm.ParallelDegree
+	)
+	pl = &PositivityLimiter{
+		EdgeInterp: dfr.FluxEdgeInterp,
+		Weights:    make([]float64, Np),
+		Gamma:      fs.Gamma,
+		Limited:    make([][]bool, Nthreads),
+		Values:     make([][][4]float64, Nthreads),
+	}
+	// The row sums of the mass matrix integrate the solution polynomial exactly
+	for i := 0; i < Np; i++ {
+		for j := 0; j < Np; j++ {
+			pl.Weights[i] += MD[j+i*Np]
+		}
+	}
+	for np := 0; np < Nthreads; np++ {
+		pl.Limited[np] = make([]bool, pm.GetBucketDimension(np))
+		pl.Values[np] = make([][4]float64, Np+NpEdge)
+	}
+	return
+}
+
+func (pl *PositivityLimiter) UpdateCount() {
+	// Must be called from the controller thread between steps
+	pl.LimitedCount = 0
+	for _, limited := range pl.Limited {
+		for k := range limited {
+			if limited[k] {
+				pl.LimitedCount++
+				limited[k] = false
+			}
+		}
+	}
+}
+
+func (pl *PositivityLimiter) pressure(Q [4]float64) float64 {
+	return (pl.Gamma - 1) * (Q[3] - 0.5*(Q[1]*Q[1]+Q[2]*Q[2])/Q[0])
+}
+
+func (pl *PositivityLimiter) pressureRoot(Qave, Q [4]float64, eps float64) (t float64) {
+	/*
+		Finds t in [0,1] where the pressure of Qave + t*(Q-Qave) equals eps, given that the pressure of Qave is above eps
+		and that of Q is below. With positive density the sign of the pressure minus eps is the sign of:
+			f(t) = rho(t)*(E(t) - eps/(gamma-1)) - 0.5*|rhoU(t)|^2
+		which is quadratic in t, with f(0) > 0 and f(1) < 0 there is one root in between.
+	*/
+	var (
+		dRho, dRhoU, dRhoV, dE = Q[0] - Qave[0], Q[1] - Qave[1], Q[2] - Qave[2], Q[3] - Qave[3]
+		Ea                     = Qave[3] - eps/(pl.Gamma-1)
+		a                      = dE*dRho - 0.5*(dRhoU*dRhoU+dRhoV*dRhoV)
+		b                      = Ea*dRho + dE*Qave[0] - (Qave[1]*dRhoU + Qave[2]*dRhoV)
+		c                      = Ea*Qave[0] - 0.5*(Qave[1]*Qave[1]+Qave[2]*Qave[2])
+	)
+	if math.Abs(a) < 1.e-14*math.Abs(b) {
+		t = -c / b
+	} else {
+		// Numerically stable form of the quadratic roots
+		q := -0.5 * (b + math.Copysign(math.Sqrt(math.Max(b*b-4*a*c, 0)), b))
+		t = 1.
+		for _, r := range []float64{q / a, c / q} {
+			if r >= 0 && r < t {
+				t = r
+			}
+		}
+	}
+	return math.Max(0, math.Min(1, t))
+}
+
+func (pl *PositivityLimiter) Limit(myThread int, Q [4]utils.Matrix) {
+	var (
+		Np, Kmax  = Q[0].Dims()
+		NpEdge, _ = pl.EdgeInterp.Dims()
+		EI        = pl.EdgeInterp.DataP
+		values    = pl.Values[myThread]
+		w         = pl.Weights
+		min       = math.Min
+	)
+	for k := 0; k < Kmax; k++ {
+		var (
+			Qave [4]float64
+			wSum float64
+		)
+		// Element average and the values at the solution points followed by the edge flux points
+		for i := 0; i < Np; i++ {
+			ind := k + i*Kmax
+			for n := 0; n < 4; n++ {
+				values[i][n] = Q[n].DataP[ind]
+				Qave[n] += w[i] * values[i][n]
+			}
+			wSum += w[i]
+		}
+		for n := 0; n < 4; n++ {
+			Qave[n] /= wSum
+		}
+		for i := 0; i < NpEdge; i++ {
+			values[Np+i] = [4]float64{}
+			for j := 0; j < Np; j++ {
+				for n := 0; n < 4; n++ {
+					values[Np+i][n] += EI[j+i*Np] * values[j][n]
+				}
+			}
+		}
+		pAve := pl.pressure(Qave)
+		if Qave[0] <= 0 || pAve <= 0 {
+			continue // The average is not positive and can not be recovered by scaling
+		}
+		epsRho, epsP := min(1.e-13, Qave[0]), min(1.e-13, pAve)
+		// Limit density
+		rhoMin := Qave[0]
+		for i := range values {
+			rhoMin = min(rhoMin, values[i][0])
+		}
+		if rhoMin < epsRho {
+			theta := (Qave[0] - epsRho) / (Qave[0] - rhoMin)
+			for i := range values {
+				values[i][0] = Qave[0] + theta*(values[i][0]-Qave[0])
+			}
+			for i := 0; i < Np; i++ {
+				Q[0].DataP[k+i*Kmax] = values[i][0]
+			}
+			pl.Limited[myThread][k] = true
+		}
+		// Limit pressure, scaling all variables
+		theta := 1.
+		for i := range values {
+			if pl.pressure(values[i]) < epsP {
+				theta = min(theta, pl.pressureRoot(Qave, values[i], epsP))
+			}
+		}
+		if theta < 1 {
+			for n := 0; n < 4; n++ {
+				for i := 0; i < Np; i++ {
+					ind := k + i*Kmax
+					Q[n].DataP[ind] = Qave[n] + theta*(Q[n].DataP[ind]-Qave[n])
+				}
+			}
+			pl.Limited[myThread][k] = true
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package Euler2D
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/notargets/gocfd/DG2D"
+)
+
+/*
+Headless rendering of a flow function to image files, for machines without a display. The field is interpolated
+linearly over each triangle of the DFR2D output mesh and rasterized into a paletted image, so the same frame can be
+written as a PNG or appended to an animated GIF. The view is the bounding box of the mesh, zoomed and translated using
+the settings of the PlotMeta, expanded to the aspect ratio of the image.
+*/
+type ImageFormat uint8
+
+const (
+	IMAGE_PNG ImageFormat = iota
+	IMAGE_GIF
+)
+
+var (
+	ImageFormatNames = map[string]ImageFormat{
+		"png": IMAGE_PNG,
+		"gif": IMAGE_GIF,
+	}
+	ImageFormatPrintNames = []string{"PNG", "GIF"}
+)
+
+func (imf ImageFormat) Print() string {
+	return ImageFormatPrintNames[imf]
+}
+
+func NewImageFormat(label string) (imf ImageFormat) {
+	var (
+		ok  bool
+		err error
+	)
+	if len(label) == 0 {
+		return IMAGE_PNG
+	}
+	label = strings.ToLower(strings.TrimSpace(label))
+	if imf, ok = ImageFormatNames[label]; !ok {
+		err = fmt.Errorf("unable to use image format named [%s]", label)
+		panic(err)
+	}
+	return
+}
+
+const (
+	paletteBackground = 0
+	paletteMesh       = 1
+	paletteFirstColor = 2
+	paletteColors     = 254
+)
+
+type FrameRenderer struct {
+	Prefix        string // PNG frames are written to Prefix_<step>.png, the animation to Prefix.gif
+	Format        ImageFormat
+	Width, Height int
+	MeshOverlay   bool // Draw the element edges over the field
+	Palette       color.Palette
+	nodeX, nodeY  []float64     // Output mesh vertices
+	tris          [][3]int      // Output mesh triangles, indexing into the vertices
+	box           [2][2]float64 // View box, [min, max] of [x, y]
+	anim          *gif.GIF
+}
+
+func NewFrameRenderer(prefix string, format ImageFormat, width, height int, meshOverlay bool) (fr *FrameRenderer) {
+	if width <= 0 || height <= 0 {
+		panic(fmt.Errorf("image dimensions must be positive, have %d x %d", width, height))
+	}
+	fr = &FrameRenderer{
+		Prefix:      prefix,
+		Format:      format,
+		Width:       width,
+		Height:      height,
+		MeshOverlay: meshOverlay,
+		Palette:     NewColorMapPalette(),
+	}
+	if format == IMAGE_GIF {
+		fr.anim = &gif.GIF{}
+	}
+	return
+}
+
+func NewColorMapPalette() (p color.Palette) {
+	// White background, black mesh lines, then a blue to red rainbow color map
+	p = make(color.Palette, paletteFirstColor+paletteColors)
+	p[paletteBackground] = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	p[paletteMesh] = color.RGBA{A: 255}
+	for i := 0; i < paletteColors; i++ {
+		var (
+			f       = float64(i) / float64(paletteColors-1)
+			r, g, b float64
+		)
+		// Piecewise linear through blue, cyan, green, yellow and red
+		switch {
+		case f < 0.25:
+			r, g, b = 0, 4*f, 1
+		case f < 0.5:
+			r, g, b = 0, 1, 1-4*(f-0.25)
+		case f < 0.75:
+			r, g, b = 4*(f-0.5), 1, 0
+		default:
+			r, g, b = 1, 1-4*(f-0.75), 0
+		}
+		p[paletteFirstColor+i] = color.RGBA{R: uint8(255 * r), G: uint8(255 * g), B: uint8(255 * b), A: 255}
+	}
+	return
+}
+
+func (c *Euler) RenderFrame(pm *PlotMeta, fr *FrameRenderer) (img *image.Paletted) {
+	var (
+		Q      = c.RecombineShardsKBy4(c.Q)
+		oField = c.GetPlotField(Q, pm.Field)
+		fI     = c.dfr.ConvertScalarToOutputMesh(oField)
+		fmin   = oField.Min()
+		fmax   = oField.Max()
+	)
+	if fr.tris == nil {
+		fr.setMesh(c.dfr)
+		fr.setViewBox(c.dfr, pm)
+	}
+	if pm.FieldMinP != nil {
+		fmin = *pm.FieldMinP
+	}
+	if pm.FieldMaxP != nil {
+		fmax = *pm.FieldMaxP
+	}
+	img = image.NewPaletted(image.Rect(0, 0, fr.Width, fr.Height), fr.Palette)
+	for _, tri := range fr.tris {
+		var (
+			px, py [3]float64
+			f      [3]float64
+		)
+		for i, node := range tri {
+			px[i], py[i] = fr.toPixel(fr.nodeX[node], fr.nodeY[node])
+			f[i] = float64(fI[node])
+		}
+		fr.fillTriangle(img, px, py, f, fmin, fmax)
+	}
+	if fr.MeshOverlay {
+		for k := 0; k < c.dfr.K; k++ {
+			verts := c.dfr.Tris.GetTriVerts(uint32(k))
+			for i := 0; i < 3; i++ {
+				v1, v2 := verts[i], verts[(i+1)%3]
+				x1, y1 := fr.toPixel(c.dfr.VX.DataP[v1], c.dfr.VY.DataP[v1])
+				x2, y2 := fr.toPixel(c.dfr.VX.DataP[v2], c.dfr.VY.DataP[v2])
+				drawLine(img, x1, y1, x2, y2, paletteMesh)
+			}
+		}
+	}
+	return
+}
+
+func (c *Euler) WriteFrame(pm *PlotMeta, fr *FrameRenderer, steps int) (err error) {
+	// Renders the plot field, then writes it to a PNG file or adds it to the animation
+	img := c.RenderFrame(pm, fr)
+	switch fr.Format {
+	case IMAGE_PNG:
+		var f *os.File
+		if f, err = os.Create(fmt.Sprintf("%s_%06d.png", fr.Prefix, steps)); err != nil {
+			return
+		}
+		if err = png.Encode(f, img); err != nil {
+			_ = f.Close()
+			return
+		}
+		err = f.Close()
+	case IMAGE_GIF:
+		delay := int(pm.FrameTime.Milliseconds() / 10) // Hundredths of a second
+		if delay <= 0 {
+			delay = 10
+		}
+		fr.anim.Image = append(fr.anim.Image, img)
+		fr.anim.Delay = append(fr.anim.Delay, delay)
+	}
+	return
+}
+
+func (fr *FrameRenderer) Close() (err error) {
+	// Writes the animation, if any frames were rendered into one
+	if fr.anim == nil || len(fr.anim.Image) == 0 {
+		return
+	}
+	var f *os.File
+	if f, err = os.Create(fr.Prefix + ".gif"); err != nil {
+		return
+	}
+	if err = gif.EncodeAll(f, fr.anim); err != nil {
+		_ = f.Close()
+		return
+	}
+	err = f.Close()
+	return
+}
+
+func (fr *FrameRenderer) setMesh(dfr *DG2D.DFR2D) {
+	// The vertices of each element are laid out as in ConvertScalarToOutputMesh: the three corners, then the RT nodes
+	// skipping the first NpInt repeated points
+	var (
+		Kmax   = dfr.K
+		Nint   = dfr.FluxElement.NpInt
+		NpFlux = dfr.FluxElement.Np
+		Np     = NpFlux - Nint + 3
+	)
+	Ind := func(k, i, Kmax int) (ind int) {
+		ind = k + i*Kmax
+		return
+	}
+	fr.nodeX, fr.nodeY = make([]float64, Kmax*Np), make([]float64, Kmax*Np)
+	for k := 0; k < Kmax; k++ {
+		verts := dfr.Tris.GetTriVerts(uint32(k))
+		for ii := 0; ii < Np; ii++ {
+			ind := Ind(k, ii, Kmax)
+			switch {
+			case ii < 3:
+				fr.nodeX[ind], fr.nodeY[ind] = dfr.VX.DataP[verts[ii]], dfr.VY.DataP[verts[ii]]
+			case ii >= 3:
+				indFlux := Ind(k, ii-3+Nint, Kmax)
+				fr.nodeX[ind], fr.nodeY[ind] = dfr.FluxX.DataP[indFlux], dfr.FluxY.DataP[indFlux]
+			}
+		}
+	}
+	fr.tris = dfr.OutputMeshTriangles()
+}
+
+func (fr *FrameRenderer) setViewBox(dfr *DG2D.DFR2D, pm *PlotMeta) {
+	// The bounding box of the mesh vertices, scaled about its center then translated
+	var (
+		xMin, xMax = dfr.VX.Min(), dfr.VX.Max()
+		yMin, yMax = dfr.VY.Min(), dfr.VY.Max()
+		scale      = pm.Scale
+		aspect     = float64(fr.Width) / float64(fr.Height)
+	)
+	if scale == 0 {
+		scale = 1
+	}
+	xc, yc := 0.5*(xMin+xMax), 0.5*(yMin+yMax)
+	xMin, xMax = scale*(xMin-xc)+xc+pm.TranslateX, scale*(xMax-xc)+xc+pm.TranslateX
+	yMin, yMax = scale*(yMin-yc)+yc+pm.TranslateY, scale*(yMax-yc)+yc+pm.TranslateY
+	// Expand the box in one direction to keep the geometry undistorted
+	if (xMax-xMin)/(yMax-yMin) < aspect {
+		half := 0.5 * (yMax - yMin) * aspect
+		xc = 0.5 * (xMin + xMax)
+		xMin, xMax = xc-half, xc+half
+	} else {
+		half := 0.5 * (xMax - xMin) / aspect
+		yc = 0.5 * (yMin + yMax)
+		yMin, yMax = yc-half, yc+half
+	}
+	fr.box = [2][2]float64{{xMin, xMax}, {yMin, yMax}}
+}
+
+func (fr *FrameRenderer) toPixel(x, y float64) (px, py float64) {
+	// Pixel coordinates, with y increasing downward in the image
+	px = (x - fr.box[0][0]) / (fr.box[0][1] - fr.box[0][0]) * float64(fr.Width)
+	py = (fr.box[1][1] - y) / (fr.box[1][1] - fr.box[1][0]) * float64(fr.Height)
+	return
+}
+
+func (fr *FrameRenderer) fillTriangle(img *image.Paletted, px, py, f [3]float64, fmin, fmax float64) {
+	// Fills the pixels whose centers are inside the triangle, interpolating the field using barycentric coordinates
+	var (
+		area = (px[1]-px[0])*(py[2]-py[0]) - (px[2]-px[0])*(py[1]-py[0])
+	)
+	if area == 0 {
+		return
+	}
+	var (
+		iMin = int(math.Max(0, math.Floor(math.Min(px[0], math.Min(px[1], px[2])))))
+		iMax = int(math.Min(float64(fr.Width-1), math.Ceil(math.Max(px[0], math.Max(px[1], px[2])))))
+		jMin = int(math.Max(0, math.Floor(math.Min(py[0], math.Min(py[1], py[2])))))
+		jMax = int(math.Min(float64(fr.Height-1), math.Ceil(math.Max(py[0], math.Max(py[1], py[2])))))
+	)
+	for j := jMin; j <= jMax; j++ {
+		y := float64(j) + 0.5
+		for i := iMin; i <= iMax; i++ {
+			x := float64(i) + 0.5
+			l0 := ((px[1]-x)*(py[2]-y) - (px[2]-x)*(py[1]-y)) / area
+			l1 := ((px[2]-x)*(py[0]-y) - (px[0]-x)*(py[2]-y)) / area
+			l2 := 1 - l0 - l1
+			if l0 < 0 || l1 < 0 || l2 < 0 {
+				continue
+			}
+			img.SetColorIndex(i, j, colorIndex(l0*f[0]+l1*f[1]+l2*f[2], fmin, fmax))
+		}
+	}
+}
+
+func colorIndex(f, fmin, fmax float64) (ind uint8) {
+	var (
+		frac float64
+	)
+	if fmax > fmin {
+		frac = (f - fmin) / (fmax - fmin)
+	}
+	frac = math.Max(0, math.Min(1, frac))
+	ind = uint8(paletteFirstColor + int(math.Round(frac*float64(paletteColors-1))))
+	return
+}
+
+func drawLine(img *image.Paletted, x1, y1, x2, y2 float64, ind uint8) {
+	// Samples the line at one pixel intervals
+	var (
+		n = int(math.Ceil(math.Max(math.Abs(x2-x1), math.Abs(y2-y1)))) + 1
+	)
+	for s := 0; s <= n; s++ {
+		t := float64(s) / float64(n)
+		x, y := int(math.Floor(x1+t*(x2-x1))), int(math.Floor(y1+t*(y2-y1)))
+		if image.Pt(x, y).In(img.Rect) {
+			img.SetColorIndex(x, y, ind)
+		}
+	}
+}
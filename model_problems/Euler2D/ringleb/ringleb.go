@@ -0,0 +1,181 @@
+package ringleb
+
+import (
+	"math"
+
+	"github.com/notargets/gocfd/readfiles"
+	"github.com/notargets/gocfd/types"
+	"github.com/notargets/gocfd/utils"
+)
+
+/*
+Ringleb flow is an exact irrotational and isentropic solution from the hodograph transformation, with gamma = 1.4. The
+streamlines are the curves of constant k, and the speed q is constant along curves in the (x, y) plane:
+
+	c = sqrt(1 - (gamma-1)/2 * q^2),    rho = c^(2/(gamma-1)),    p = rho^gamma / gamma
+	J = 1/c + 1/(3c^3) + 1/(5c^5) - 1/2 * ln((1+c)/(1-c))
+	x = 1/(2 rho) * (1/q^2 - 2/k^2) + J/2,    y = +/- 1/(k rho q) * sqrt(1 - (q/k)^2)
+
+The flow angle theta has sin(theta) = q/k. The flow turns around the nose of each streamline on the x axis, where
+q = k, moving from the lower half plane to the upper.
+
+The domain is bounded by the walls on streamlines KMin and KMax and by the inflow and outflow on the curves of speed
+Q0 below and above the x axis. The flow is subsonic when KMax is less than the sonic speed, sqrt(2/(gamma+1)).
+*/
+type Ringleb struct {
+	KMin, KMax, Q0, Gamma float64
+}
+
+func NewRingleb(KMin, KMax, Q0 float64) (rb *Ringleb) {
+	rb = &Ringleb{
+		KMin:  KMin,
+		KMax:  KMax,
+		Q0:    Q0,
+		Gamma: 1.4,
+	}
+	return
+}
+
+func NewRinglebDefault() (rb *Ringleb) {
+	// A subsonic domain, with a maximum Mach number of 0.86 at the nose of the outer wall
+	return NewRingleb(0.5, 0.8, 0.4)
+}
+
+func (rb *Ringleb) speedState(q float64) (c, rho, J float64) {
+	c = math.Sqrt(1. - 0.5*(rb.Gamma-1.)*q*q)
+	rho = math.Pow(c, 2./(rb.Gamma-1.))
+	J = 1./c + 1./(3.*c*c*c) + 1./(5.*math.Pow(c, 5)) - 0.5*math.Log((1.+c)/(1.-c))
+	return
+}
+
+func (rb *Ringleb) GetXY(q, k float64, upper bool) (x, y float64) {
+	// The location of speed q on streamline k, in the upper or lower half plane
+	_, rho, J := rb.speedState(q)
+	x = 0.5/rho*(1./(q*q)-2./(k*k)) + 0.5*J
+	y = math.Sqrt(math.Max(0, 1.-q*q/(k*k))) / (k * rho * q)
+	if !upper {
+		y = -y
+	}
+	return
+}
+
+func (rb *Ringleb) GetQK(x, y float64) (q, k float64) {
+	/*
+		The speed is the root of (x - J/2)^2 + y^2 = 1/(2 rho q^2)^2, found by bisection on the subsonic speeds,
+		then the streamline k follows from x
+	*/
+	var (
+		lo, hi = 0., math.Sqrt(2. / (rb.Gamma + 1.))
+	)
+	for i := 0; i < 60; i++ {
+		q = 0.5 * (lo + hi)
+		_, rho, J := rb.speedState(q)
+		xJ, r := x-0.5*J, 0.5/(rho*q*q)
+		if xJ*xJ+y*y > r*r {
+			hi = q
+		} else {
+			lo = q
+		}
+	}
+	_, rho, J := rb.speedState(q)
+	k = math.Sqrt(2. / (1./(q*q) - 2.*rho*(x-0.5*J)))
+	return
+}
+
+func (rb *Ringleb) GetState(t, x, y float64) (u, v, rho, p float64) {
+	var (
+		q, k       = rb.GetQK(x, y)
+		c, rhoQ, _ = rb.speedState(q)
+		sinT       = math.Min(q/k, 1.)
+		cosT       = math.Sqrt(1. - sinT*sinT)
+	)
+	u, v = q*cosT, q*sinT
+	if y < 0 {
+		u = -u
+	}
+	rho = rhoQ
+	p = c * c * rho / rb.Gamma
+	return
+}
+
+func (rb *Ringleb) GetStateC(t, x, y float64) (Rho, RhoU, RhoV, E float64) {
+	var (
+		ooGM1 = 1. / (rb.Gamma - 1.)
+	)
+	u, v, rho, p := rb.GetState(t, x, y)
+	q := 0.5 * rho * (u*u + v*v)
+	Rho, RhoU, RhoV, E = rho, rho*u, rho*v, p*ooGM1+q
+	return
+}
+
+func (rb *Ringleb) GetDivergence(t, x, y float64) (div [4]float64) {
+	// The flow is an exact steady solution, the divergence of the fluxes is zero
+	return
+}
+
+func (rb *Ringleb) GetMesh(nK int) (VX, VY utils.Vector, EToV utils.Matrix, BCEdges types.BCMAP) {
+	/*
+		A structured mesh of quadrilaterals, nK elements across the streamlines and 4*nK along them. Along each
+		streamline the flow angle is uniformly spaced from the inflow to the nose and from the nose to the outflow.
+		The vertices on the walls are on the streamlines, the edges between them are straight.
+		The BCs are "in" below the x axis, "out" above it and "wall-inner" and "wall-outer" on streamlines KMax and KMin.
+	*/
+	var (
+		nS    = 4 * nK
+		Nv    = (nK + 1) * (nS + 1)
+		vID   = func(i, j int) int { return i + j*(nK+1) } // i is across the streamlines, j is along them
+		inner = make([]types.EdgeInt, nS)
+		outer = make([]types.EdgeInt, nS)
+		in    = make([]types.EdgeInt, nK)
+		out   = make([]types.EdgeInt, nK)
+	)
+	VX, VY = utils.NewVector(Nv), utils.NewVector(Nv)
+	EToV = utils.NewMatrix(nK*nS, 4)
+	for i := 0; i <= nK; i++ {
+		k := rb.KMin + float64(i)*(rb.KMax-rb.KMin)/float64(nK)
+		if i == nK {
+			k = rb.KMax
+		}
+		theta0 := math.Asin(rb.Q0 / k)
+		for j := 0; j <= nS; j++ {
+			var (
+				s     = 2.*float64(j)/float64(nS) - 1. // From -1 at the inflow to 1 at the outflow
+				theta = theta0 + (0.5*math.Pi-theta0)*(1.-math.Abs(s))
+				q     = k * math.Sin(theta)
+			)
+			switch {
+			case j == 0 || j == nS:
+				q = rb.Q0
+			case 2*j == nS:
+				q = k
+			}
+			VX.DataP[vID(i, j)], VY.DataP[vID(i, j)] = rb.GetXY(q, k, 2*j > nS)
+		}
+	}
+	for j := 0; j < nS; j++ {
+		for i := 0; i < nK; i++ {
+			kk := i + j*nK
+			EToV.Set(kk, 0, float64(vID(i, j)))
+			EToV.Set(kk, 1, float64(vID(i+1, j)))
+			EToV.Set(kk, 2, float64(vID(i+1, j+1)))
+			EToV.Set(kk, 3, float64(vID(i, j+1)))
+		}
+		inner[j] = types.NewEdgeInt([2]int{vID(nK, j), vID(nK, j+1)})
+		outer[j] = types.NewEdgeInt([2]int{vID(0, j+1), vID(0, j)})
+	}
+	for i := 0; i < nK; i++ {
+		in[i] = types.NewEdgeInt([2]int{vID(i, 0), vID(i+1, 0)})
+		out[i] = types.NewEdgeInt([2]int{vID(i+1, nS), vID(i, nS)})
+	}
+	BCEdges = make(types.BCMAP)
+	BCEdges.AddEdges(types.NewBCTAG("in"), in)
+	BCEdges.AddEdges(types.NewBCTAG("out"), out)
+	BCEdges.AddEdges(types.NewBCTAG("wall-inner"), inner)
+	BCEdges.AddEdges(types.NewBCTAG("wall-outer"), outer)
+	return
+}
+
+func (rb *Ringleb) WriteMesh(fileName string, nK int) (err error) {
+	VX, VY, EToV, BCEdges := rb.GetMesh(nK)
+	return readfiles.WriteSU2(fileName, VX, VY, EToV, BCEdges)
+}
@@ -0,0 +1,82 @@
+package ringleb
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/notargets/gocfd/model_problems/Euler2D/isentropic_vortex"
+	"github.com/notargets/gocfd/readfiles"
+	"github.com/notargets/gocfd/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRingleb(t *testing.T) {
+	rb := NewRinglebDefault()
+	{ // The speed and streamline are recovered from the location
+		for _, qk := range [][2]float64{{0.4, 0.5}, {0.45, 0.6}, {0.7, 0.75}, {0.8, 0.8}} {
+			for _, upper := range []bool{true, false} {
+				x, y := rb.GetXY(qk[0], qk[1], upper)
+				q, k := rb.GetQK(x, y)
+				assert.InDelta(t, qk[0], q, 1.e-10)
+				assert.InDelta(t, qk[1], k, 1.e-6)
+			}
+		}
+	}
+	{ // The flow is tangent to the streamlines and satisfies the steady Euler equations
+		for _, qk := range [][2]float64{{0.45, 0.6}, {0.7, 0.75}, {0.55, 0.7}} {
+			for _, upper := range []bool{true, false} {
+				var (
+					x, y   = rb.GetXY(qk[0], qk[1], upper)
+					x2, y2 = rb.GetXY(qk[0]+1.e-6, qk[1], upper)
+					h      = 1.e-5
+				)
+				u, v, _, _ := rb.GetState(0, x, y)
+				assert.InDelta(t, 0, u*(y2-y)-v*(x2-x), 1.e-10)
+				assert.InDelta(t, qk[0], math.Sqrt(u*u+v*v), 1.e-10)
+				assert.True(t, v > 0)
+				Fp, _ := flux(rb, x+h, y)
+				Fm, _ := flux(rb, x-h, y)
+				_, Gp := flux(rb, x, y+h)
+				_, Gm := flux(rb, x, y-h)
+				for n := 0; n < 4; n++ {
+					assert.InDelta(t, 0, (Fp[n]-Fm[n]+Gp[n]-Gm[n])/(2*h), 1.e-6)
+				}
+			}
+		}
+	}
+	{ // The mesh elements are counter clockwise and the walls are on the streamlines
+		nK := 4
+		VX, VY, EToV, BCEdges := rb.GetMesh(nK)
+		K, _ := EToV.Dims()
+		assert.Equal(t, 4*nK*nK, K)
+		for k := 0; k < K; k++ {
+			var area float64
+			for i := 0; i < 4; i++ {
+				v1, v2 := int(EToV.At(k, i)), int(EToV.At(k, (i+1)%4))
+				area += VX.DataP[v1]*VY.DataP[v2] - VX.DataP[v2]*VY.DataP[v1]
+			}
+			assert.True(t, area > 0)
+		}
+		for tag, kWall := range map[string]float64{"wall-inner": rb.KMax, "wall-outer": rb.KMin} {
+			assert.Equal(t, 4*nK, len(BCEdges[types.NewBCTAG(tag)]))
+			for _, e := range BCEdges[types.NewBCTAG(tag)] {
+				for _, v := range e.GetVertices() {
+					_, k := rb.GetQK(VX.DataP[v], VY.DataP[v])
+					assert.InDelta(t, kWall, k, 1.e-6)
+				}
+			}
+		}
+		fileName := filepath.Join(t.TempDir(), "ringleb.su2")
+		assert.Nil(t, rb.WriteMesh(fileName, nK))
+		Kr, _, _, _, BCEdgesR := readfiles.ReadSU2(fileName, false)
+		assert.Equal(t, 2*K, Kr)
+		assert.Equal(t, 4, len(BCEdgesR))
+	}
+}
+
+func flux(rb *Ringleb, x, y float64) (Fx, Fy [4]float64) {
+	rho, rhoU, rhoV, E := rb.GetStateC(0, x, y)
+	return isentropic_vortex.FluxCalc(rb.Gamma, rho, rhoU, rhoV, E)
+}
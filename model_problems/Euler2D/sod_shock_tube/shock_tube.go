@@ -7,6 +7,7 @@ import (
 	"github.com/notargets/gocfd/DG2D"
 
 	"github.com/notargets/gocfd/model_problems/Euler1D"
+	"github.com/notargets/gocfd/model_problems/Euler1D/exact_riemann"
 
 	"github.com/notargets/gocfd/utils"
 )
@@ -24,9 +25,10 @@ type SODShockTube struct {
 	Npts                int       // Npts = # Xlocations, Np = Interior solution polynomial nodes
 	DFR2D               *DG2D.DFR2D
 	LineChart           *utils.LineChart
+	Riemann             *exact_riemann.RiemannProblem // Exact solution along the tube
 }
 
-func NewSODShockTube(nPts int, dfr *DG2D.DFR2D) (st *SODShockTube) {
+func NewSODShockTube(nPts int, dfr *DG2D.DFR2D, rp *exact_riemann.RiemannProblem) (st *SODShockTube) {
 	st = &SODShockTube{
 		XLocations:          make([]float64, nPts),
 		InterpolationTarget: make([]InterpolationTarget, nPts),
@@ -36,6 +38,7 @@ func NewSODShockTube(nPts int, dfr *DG2D.DFR2D) (st *SODShockTube) {
 		E:                   make([]float64, nPts),
 		DFR2D:               dfr,
 		LineChart:           utils.NewLineChart(1920, 1080, 0, 1, -.1, 2.6),
+		Riemann:             rp,
 	}
 	xfrac := 1. / float64(nPts-1) // Equal spaced samples across [0->1]
 	for i := range st.XLocations {
@@ -142,7 +145,42 @@ func (st *SODShockTube) Plot(timeT float64, graphDelay time.Duration, Q [4]utils
 	st.LineChart.Plot(0, st.XLocations, st.Rho, -.7, "Rho")
 	st.LineChart.Plot(0, st.XLocations, st.RhoU, 0., "RhoU")
 	st.LineChart.Plot(graphDelay, st.XLocations, st.E, 0.7, "E")
-	iRho = Euler1D.AddAnalyticSod(st.LineChart.Chart, st.LineChart.ColorMap, timeT)
+	iRho = Euler1D.AddAnalyticShockTube(st.LineChart.Chart, st.LineChart.ColorMap, st.Riemann, timeT, 0, 1)
 	time.Sleep(graphDelay)
 	return
 }
+
+/*
+ExactShockTube is the exact solution of the Riemann problem along the tube, uniform in Y with no Y velocity
+*/
+type ExactShockTube struct {
+	Riemann *exact_riemann.RiemannProblem
+}
+
+func NewExactShockTube(rp *exact_riemann.RiemannProblem) (es *ExactShockTube) {
+	return &ExactShockTube{Riemann: rp}
+}
+
+func (es *ExactShockTube) GetStateC(t, x, y float64) (Rho, RhoU, RhoV, E float64) {
+	Rho, RhoU, E = es.Riemann.GetStateC(t, x)
+	return
+}
+
+func (es *ExactShockTube) GetDivergence(t, x, y float64) (div [4]float64) {
+	// The divergence of the fluxes by central difference, zero in the uniform regions and unbounded at a shock
+	var (
+		h     = 1.e-6
+		gamma = es.Riemann.Gamma
+	)
+	flux := func(x float64) (F [4]float64) {
+		s := es.Riemann.Get(t, x)
+		_, rhoU, E := s.Conserved(gamma)
+		F[0], F[1], F[3] = rhoU, rhoU*s.U+s.P, (E+s.P)*s.U
+		return
+	}
+	Fp, Fm := flux(x+h), flux(x-h)
+	for n := 0; n < 4; n++ {
+		div[n] = (Fp[n] - Fm[n]) / (2. * h)
+	}
+	return
+}
@@ -0,0 +1,126 @@
+package supersonic_vortex
+
+import (
+	"math"
+
+	"github.com/notargets/gocfd/readfiles"
+	"github.com/notargets/gocfd/types"
+	"github.com/notargets/gocfd/utils"
+)
+
+/*
+The supersonic vortex is an isentropic flow turning between two concentric circular arcs, the walls at radius Ri and
+Ro. The speed varies inversely with the radius, so the flow is irrotational, and the density follows from the constant
+total enthalpy:
+
+	rho = RhoI * [1 + (gamma-1)/2 * Mi^2 * (1 - (Ri/r)^2)]^(1/(gamma-1)),    p = rho^gamma / gamma,    U = Mi * Ri / r
+
+The flow is counter clockwise around the origin, entering the quarter annulus in the first quadrant across the x axis
+and leaving across the y axis. The inner wall state is RhoI = 1, with a sound speed of 1 and the Mach number Mi.
+*/
+type SVortex struct {
+	Ri, Ro, Mi, Gamma float64
+}
+
+func NewSVortex(Ri, Ro, Mi, Gamma float64) (sv *SVortex) {
+	sv = &SVortex{
+		Ri:    Ri,
+		Ro:    Ro,
+		Mi:    Mi,
+		Gamma: Gamma,
+	}
+	return
+}
+
+func NewSVortexDefault() (sv *SVortex) {
+	// The case of Aftosmis et al., "Behavior of linear reconstruction techniques on unstructured meshes", AIAA J. 1995
+	return NewSVortex(1, 1.384, 2.25, 1.4)
+}
+
+func (sv *SVortex) GetState(t, x, y float64) (u, v, rho, p float64) {
+	var (
+		Gamma = sv.Gamma
+		GM1   = Gamma - 1
+		r2    = x*x + y*y
+		ri2   = sv.Ri * sv.Ri
+		c2    = 1. + 0.5*GM1*sv.Mi*sv.Mi*(1.-ri2/r2) // Square of the sound speed
+		oor2U = sv.Mi * sv.Ri / r2                   // Speed divided by the radius
+	)
+	u, v = -oor2U*y, oor2U*x
+	rho = math.Pow(c2, 1./GM1)
+	p = c2 * rho / Gamma
+	return
+}
+
+func (sv *SVortex) GetStateC(t, x, y float64) (Rho, RhoU, RhoV, E float64) {
+	var (
+		ooGM1 = 1. / (sv.Gamma - 1.)
+	)
+	u, v, rho, p := sv.GetState(t, x, y)
+	q := 0.5 * rho * (u*u + v*v)
+	Rho, RhoU, RhoV, E = rho, rho*u, rho*v, p*ooGM1+q
+	return
+}
+
+func (sv *SVortex) GetDivergence(t, x, y float64) (div [4]float64) {
+	// The flow is an exact steady solution, the divergence of the fluxes is zero
+	return
+}
+
+func (sv *SVortex) GetMesh(nR int) (VX, VY utils.Vector, EToV utils.Matrix, BCEdges types.BCMAP) {
+	/*
+		A structured mesh of quadrilaterals in polar coordinates, nR elements across the annulus and 4*nR around it,
+		which are nearly square. The vertices on the walls are on the arcs, the edges between them are straight.
+		The BCs are "in" on the x axis, "out" on the y axis and "wall-inner" and "wall-outer" on the arcs.
+	*/
+	var (
+		nT     = 4 * nR
+		Nv     = (nR + 1) * (nT + 1)
+		vID    = func(i, j int) int { return i + j*(nR+1) } // i is radial, j is circumferential
+		inner  = make([]types.EdgeInt, nT)
+		outer  = make([]types.EdgeInt, nT)
+		in     = make([]types.EdgeInt, nR)
+		out    = make([]types.EdgeInt, nR)
+		dR, dT = (sv.Ro - sv.Ri) / float64(nR), 0.5 * math.Pi / float64(nT)
+	)
+	VX, VY = utils.NewVector(Nv), utils.NewVector(Nv)
+	EToV = utils.NewMatrix(nR*nT, 4)
+	for j := 0; j <= nT; j++ {
+		for i := 0; i <= nR; i++ {
+			r, theta := sv.Ri+float64(i)*dR, float64(j)*dT
+			if i == nR {
+				r = sv.Ro
+			}
+			if j == nT {
+				theta = 0.5 * math.Pi
+			}
+			VX.DataP[vID(i, j)], VY.DataP[vID(i, j)] = r*math.Cos(theta), r*math.Sin(theta)
+		}
+	}
+	for j := 0; j < nT; j++ {
+		for i := 0; i < nR; i++ {
+			k := i + j*nR
+			EToV.Set(k, 0, float64(vID(i, j)))
+			EToV.Set(k, 1, float64(vID(i+1, j)))
+			EToV.Set(k, 2, float64(vID(i+1, j+1)))
+			EToV.Set(k, 3, float64(vID(i, j+1)))
+		}
+		inner[j] = types.NewEdgeInt([2]int{vID(0, j+1), vID(0, j)})
+		outer[j] = types.NewEdgeInt([2]int{vID(nR, j), vID(nR, j+1)})
+	}
+	for i := 0; i < nR; i++ {
+		in[i] = types.NewEdgeInt([2]int{vID(i, 0), vID(i+1, 0)})
+		out[i] = types.NewEdgeInt([2]int{vID(i+1, nT), vID(i, nT)})
+	}
+	BCEdges = make(types.BCMAP)
+	BCEdges.AddEdges(types.NewBCTAG("in"), in)
+	BCEdges.AddEdges(types.NewBCTAG("out"), out)
+	BCEdges.AddEdges(types.NewBCTAG("wall-inner"), inner)
+	BCEdges.AddEdges(types.NewBCTAG("wall-outer"), outer)
+	return
+}
+
+func (sv *SVortex) WriteMesh(fileName string, nR int) (err error) {
+	VX, VY, EToV, BCEdges := sv.GetMesh(nR)
+	return readfiles.WriteSU2(fileName, VX, VY, EToV, BCEdges)
+}
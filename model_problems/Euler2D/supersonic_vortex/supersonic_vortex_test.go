@@ -0,0 +1,69 @@
+package supersonic_vortex
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/notargets/gocfd/model_problems/Euler2D/isentropic_vortex"
+	"github.com/notargets/gocfd/readfiles"
+	"github.com/notargets/gocfd/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSVortex(t *testing.T) {
+	sv := NewSVortexDefault()
+	{ // Inner wall state
+		u, v, rho, p := sv.GetState(0, sv.Ri, 0)
+		assert.InDelta(t, 0, u, 1.e-12)
+		assert.InDelta(t, sv.Mi, v, 1.e-12)
+		assert.InDelta(t, 1, rho, 1.e-12)
+		assert.InDelta(t, 1/sv.Gamma, p, 1.e-12)
+	}
+	{ // The flow satisfies the steady Euler equations
+		h := 1.e-5
+		for _, rt := range [][2]float64{{1.05, 0.1}, {1.2, 0.7}, {1.35, 1.5}} {
+			x, y := rt[0]*math.Cos(rt[1]), rt[0]*math.Sin(rt[1])
+			Fp, _ := flux(sv, x+h, y)
+			Fm, _ := flux(sv, x-h, y)
+			_, Gp := flux(sv, x, y+h)
+			_, Gm := flux(sv, x, y-h)
+			for n := 0; n < 4; n++ {
+				assert.InDelta(t, 0, (Fp[n]-Fm[n]+Gp[n]-Gm[n])/(2*h), 1.e-6)
+			}
+		}
+	}
+	{ // The mesh elements are counter clockwise and the walls are on the arcs
+		nR := 3
+		VX, VY, EToV, BCEdges := sv.GetMesh(nR)
+		K, _ := EToV.Dims()
+		assert.Equal(t, 4*nR*nR, K)
+		for k := 0; k < K; k++ {
+			var area float64
+			for i := 0; i < 4; i++ {
+				v1, v2 := int(EToV.At(k, i)), int(EToV.At(k, (i+1)%4))
+				area += VX.DataP[v1]*VY.DataP[v2] - VX.DataP[v2]*VY.DataP[v1]
+			}
+			assert.True(t, area > 0)
+		}
+		for tag, rWall := range map[string]float64{"wall-inner": sv.Ri, "wall-outer": sv.Ro} {
+			assert.Equal(t, 4*nR, len(BCEdges[types.NewBCTAG(tag)]))
+			for _, e := range BCEdges[types.NewBCTAG(tag)] {
+				for _, v := range e.GetVertices() {
+					assert.InDelta(t, rWall, math.Hypot(VX.DataP[v], VY.DataP[v]), 1.e-12)
+				}
+			}
+		}
+		fileName := filepath.Join(t.TempDir(), "svortex.su2")
+		assert.Nil(t, sv.WriteMesh(fileName, nR))
+		Kr, _, _, _, BCEdgesR := readfiles.ReadSU2(fileName, false)
+		assert.Equal(t, 2*K, Kr)
+		assert.Equal(t, 4, len(BCEdgesR))
+	}
+}
+
+func flux(sv *SVortex, x, y float64) (Fx, Fy [4]float64) {
+	rho, rhoU, rhoV, E := sv.GetStateC(0, x, y)
+	return isentropic_vortex.FluxCalc(sv.Gamma, rho, rhoU, rhoV, E)
+}
@@ -0,0 +1,155 @@
+package Euler2D
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+
+	"github.com/notargets/gocfd/types"
+)
+
+type SurfacePoint struct {
+	Segment            int     // Connected curve of the tag, in order of their first edge within the mesh file
+	X, Y, S            float64 // Location and arc length along the wall curve, restarting at zero on each segment
+	Cp, Mach, Pressure float64
+}
+
+func (c *Euler) GetSurfaceDistribution(tag types.BCTAG) (pts []SurfacePoint, err error) {
+	/*
+		Walks the ordered wall curve for this BC tag and evaluates the surface data at every edge flux point, in order of
+		increasing arc length. A tag can hold more than one connected curve, e.g. a main element and a flap, each is
+		walked as its own segment. A segment starts at its first edge within the mesh file.
+	*/
+	var (
+		dfr   = c.dfr
+		edges types.Curve
+		ok    bool
+	)
+	if edges, ok = dfr.BCEdges[tag]; !ok || len(edges) == 0 {
+		err = fmt.Errorf("no boundary edges found with tag [%s]", tag)
+		return
+	}
+	switch tag.GetFLAG() {
+	case types.BC_Wall, types.BC_Cyl:
+	default:
+		err = fmt.Errorf("boundary tag [%s] is not a wall", tag)
+		return
+	}
+	for segment, curve := range edges.Components() {
+		// A curve can be walked if no vertex is shared by more than two of its edges
+		vertCount := make(map[int]int)
+		for _, e := range curve {
+			for _, v := range e.GetVertices() {
+				if vertCount[v]++; vertCount[v] > 2 {
+					err = fmt.Errorf("boundary tag [%s] branches at vertex %d, unable to order it as a curve", tag, v)
+					return
+				}
+			}
+		}
+		cc, _ := curve.ReOrder(false)
+		pts = append(pts, c.getSurfaceSegment(segment, cc)...)
+	}
+	return
+}
+
+func (c *Euler) getSurfaceSegment(segment int, cc types.Curve) (pts []SurfacePoint) {
+	var (
+		dfr   = c.dfr
+		Nedge = dfr.FluxElement.NpEdge
+		Nint  = dfr.FluxElement.NpInt
+		Kmax  = dfr.K
+	)
+	// Find the starting vertex of the curve, the vertex of the first edge not shared with the second edge
+	verts := cc[0].GetVertices()
+	start := verts[0]
+	if len(cc) > 1 {
+		v2 := cc[1].GetVertices()
+		if verts[0] == v2[0] || verts[0] == v2[1] {
+			start = verts[1]
+		}
+	}
+	var arcLength float64
+	for _, ei := range cc {
+		var (
+			e       = dfr.Tris.Edges[ei.GetKey()]
+			kGlobal = int(e.ConnectedTris[0])
+			edgeNum = int(e.ConnectedTriEdgeNumber[0])
+			shift   = edgeNum * Nedge
+			tri     = dfr.Tris.GetTriVerts(uint32(kGlobal))
+			ev      = ei.GetVertices()
+			end     = ev[0]
+		)
+		if end == start {
+			end = ev[1]
+		}
+		xs, ys := dfr.VX.DataP[start], dfr.VY.DataP[start]
+		// Edge points are ordered counter clockwise within the element, from vertex [edgeNum] to [edgeNum+1]
+		forward := tri[edgeNum] == start
+		QEdge := c.InterpolateEdgeState(kGlobal, edgeNum)
+		for ii := 0; ii < Nedge; ii++ {
+			i := ii
+			if !forward {
+				i = Nedge - 1 - ii
+			}
+			ind := kGlobal + (2*Nint+shift+i)*Kmax
+			x, y := dfr.FluxX.DataP[ind], dfr.FluxY.DataP[ind]
+			p := c.FSFar.GetFlowFunctionQQ(QEdge[i], StaticPressure)
+			pts = append(pts, SurfacePoint{
+				Segment:  segment,
+				X:        x,
+				Y:        y,
+				S:        arcLength + math.Hypot(x-xs, y-ys),
+				Cp:       (p - c.FSFar.Pinf) / c.FSFar.QQinf,
+				Mach:     c.FSFar.GetFlowFunctionQQ(QEdge[i], Mach),
+				Pressure: p,
+			})
+		}
+		arcLength += e.GetEdgeLength()
+		start = end
+	}
+	return
+}
+
+func (c *Euler) WriteSurfaceDistribution(prefix string) (err error) {
+	/*
+		Writes one CSV file per wall BC tag, named prefix_tag.csv
+	*/
+	var (
+		tags []types.BCTAG
+	)
+	for tag := range c.dfr.BCEdges {
+		switch tag.GetFLAG() {
+		case types.BC_Wall, types.BC_Cyl:
+			tags = append(tags, tag)
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i] < tags[j] })
+	for _, tag := range tags {
+		var (
+			pts []SurfacePoint
+			f   *os.File
+		)
+		if pts, err = c.GetSurfaceDistribution(tag); err != nil {
+			return
+		}
+		if f, err = os.Create(fmt.Sprintf("%s_%s.csv", prefix, string(tag))); err != nil {
+			return
+		}
+		w := bufio.NewWriter(f)
+		fmt.Fprintln(w, "x,y,s,Cp,Mach,p,segment")
+		for _, pt := range pts {
+			fmt.Fprintf(w, "%.10e,%.10e,%.10e,%.10e,%.10e,%.10e,%d\n", pt.X, pt.Y, pt.S, pt.Cp, pt.Mach, pt.Pressure,
+				pt.Segment)
+		}
+		if err = w.Flush(); err != nil {
+			_ = f.Close()
+			return
+		}
+		if err = f.Close(); err != nil {
+			return
+		}
+	}
+	return
+}
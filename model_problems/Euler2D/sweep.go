@@ -0,0 +1,189 @@
+package Euler2D
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/ghodss/yaml"
+)
+
+/*
+A parameter sweep runs one solution for each combination of the swept parameters, with all other parameters taken from
+a base input file. Each parameter is a list of values, Minf and Alpha can also be a range that includes both ends:
+
+	Minf: [0.5, 0.8]
+	Alpha: {From: -4, To: 8, Step: 2}
+	PolynomialOrder: [1, 2]
+	FluxType: [Roe, Lax]
+	WarmStart: true
+
+A parameter that is not swept keeps its value from the base input file. Alpha varies fastest and PolynomialOrder
+slowest, so consecutive cases share the mesh and finite elements whenever possible. With WarmStart, a case that shares
+the elements of the previous case starts from the previous solution instead of the initial condition.
+*/
+type SweepSpec struct {
+	Minf            SweepRange `yaml:"Minf"`
+	Alpha           SweepRange `yaml:"Alpha"`
+	PolynomialOrder []int      `yaml:"PolynomialOrder"`
+	FluxType        []string   `yaml:"FluxType"`
+	WarmStart       bool       `yaml:"WarmStart"`
+}
+
+type SweepRange []float64
+
+func (sr *SweepRange) UnmarshalJSON(data []byte) (err error) {
+	// The YAML is converted to JSON before parsing, the values are a number, a list or a range {From, To, Step}
+	var (
+		val  float64
+		vals []float64
+		rng  struct{ From, To, Step float64 }
+	)
+	switch {
+	case json.Unmarshal(data, &val) == nil:
+		*sr = SweepRange{val}
+	case json.Unmarshal(data, &vals) == nil:
+		*sr = vals
+	case json.Unmarshal(data, &rng) == nil:
+		if rng.Step == 0 || (rng.To-rng.From)/rng.Step < 0 {
+			err = fmt.Errorf("sweep range from %g to %g can not be reached using step %g", rng.From, rng.To, rng.Step)
+			return
+		}
+		n := int(math.Floor((rng.To-rng.From)/rng.Step + 1.e-9))
+		*sr = make(SweepRange, n+1)
+		for i := range *sr {
+			(*sr)[i] = rng.From + float64(i)*rng.Step
+		}
+	default:
+		err = fmt.Errorf("sweep values must be a number, a list or a range {From, To, Step}, have %s", string(data))
+	}
+	return
+}
+
+func ParseSweepSpec(data []byte) (ss *SweepSpec, err error) {
+	ss = &SweepSpec{}
+	if err = yaml.Unmarshal(data, ss); err != nil {
+		return nil, err
+	}
+	return
+}
+
+type SweepCase struct {
+	Minf, Alpha     float64
+	PolynomialOrder int
+	FluxType        string
+}
+
+func (ss *SweepSpec) Cases(base *InputParameters) (cases []SweepCase) {
+	var (
+		minf   = []float64(ss.Minf)
+		alpha  = []float64(ss.Alpha)
+		orders = ss.PolynomialOrder
+		fluxes = ss.FluxType
+	)
+	if len(minf) == 0 {
+		minf = []float64{base.Minf}
+	}
+	if len(alpha) == 0 {
+		alpha = []float64{base.Alpha}
+	}
+	if len(orders) == 0 {
+		orders = []int{base.PolynomialOrder}
+	}
+	if len(fluxes) == 0 {
+		fluxes = []string{base.FluxType}
+	}
+	for _, N := range orders {
+		for _, flux := range fluxes {
+			for _, m := range minf {
+				for _, a := range alpha {
+					cases = append(cases, SweepCase{Minf: m, Alpha: a, PolynomialOrder: N, FluxType: flux})
+				}
+			}
+		}
+	}
+	return
+}
+
+func (sc SweepCase) InputParameters(base *InputParameters) (ip *InputParameters) {
+	ip = &InputParameters{}
+	*ip = *base
+	ip.Minf, ip.Alpha, ip.PolynomialOrder, ip.FluxType = sc.Minf, sc.Alpha, sc.PolynomialOrder, sc.FluxType
+	return
+}
+
+type SweepResult struct {
+	SweepCase
+	WarmStarted bool
+	Converged   bool
+	Result      ResidualRecord
+	Forces      ForceCoefficients // Zero if there are no walls
+}
+
+func RunSweep(base *InputParameters, ss *SweepSpec, meshFile string, ProcLimit int, pm *PlotMeta,
+	summaryFile string) (results []SweepResult, err error) {
+	/*
+		Solves every case of the sweep, a line is added to the summary CSV file as each case finishes
+	*/
+	var (
+		file  *os.File
+		prev  *Euler
+		cases = ss.Cases(base)
+	)
+	if file, err = os.Create(summaryFile); err != nil {
+		return
+	}
+	defer func() {
+		if errC := file.Close(); err == nil {
+			err = errC
+		}
+	}()
+	if _, err = fmt.Fprintln(file,
+		"case,Minf,Alpha,N,FluxType,warmstart,converged,iter,time,Res0,Res1,Res2,Res3,L1,L2,CL,CD,CM,wallclock"); err != nil {
+		return
+	}
+	for i, sc := range cases {
+		var (
+			ip = sc.InputParameters(base)
+			c  *Euler
+			sr = SweepResult{SweepCase: sc}
+		)
+		fmt.Printf("\nSweep case %d of %d: Minf = %8.5f, Alpha = %8.5f, N = %d, Flux = [%s]\n",
+			i+1, len(cases), sc.Minf, sc.Alpha, sc.PolynomialOrder, sc.FluxType)
+		if prev != nil && prev.dfr.N == sc.PolynomialOrder {
+			c = NewEulerSharingGeometry(ip, prev, false, false)
+			if ss.WarmStart {
+				c.CopySolution(prev)
+				sr.WarmStarted = true
+			}
+		} else {
+			c = NewEuler(ip, meshFile, ProcLimit, false, false, false)
+		}
+		c.Solve(pm)
+		sr.Result, sr.Converged = c.Result, c.Converged
+		if c.Forces != nil {
+			sr.Forces = c.Forces.Total
+		}
+		results = append(results, sr)
+		r := sr.Result.Residual
+		if _, err = fmt.Fprintf(file,
+			"%d,%.6f,%.6f,%d,%s,%t,%t,%d,%.10e,%.10e,%.10e,%.10e,%.10e,%.10e,%.10e,%.10e,%.10e,%.10e,%.6f\n",
+			i+1, sc.Minf, sc.Alpha, sc.PolynomialOrder, NewFluxType(sc.FluxType).Print(), sr.WarmStarted,
+			sr.Converged, sr.Result.Iteration, sr.Result.Time, r[0], r[1], r[2], r[3], r[4], r[5],
+			sr.Forces.CL, sr.Forces.CD, sr.Forces.CM, sr.Result.WallClock); err != nil {
+			return
+		}
+		prev = c
+	}
+	return
+}
+
+func (c *Euler) CopySolution(prev *Euler) {
+	// Copies the solution of a solver sharing the same elements and partitions
+	for np := 0; np < c.Partitions.ParallelDegree; np++ {
+		for n := 0; n < 4; n++ {
+			c.Q[np][n] = prev.Q[np][n].Copy()
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package Euler2D
+
+import (
+	"fmt"
+	"strings"
+)
+
+type TimeIntegratorType uint8
+
+const (
+	RK_SSP54 TimeIntegratorType = iota
+	RK_ForwardEuler
+	RK_SSP2
+	RK_SSP3
+	RK_Classic4
+	RK_LowStorage4
+)
+
+var (
+	TimeIntegratorNames = map[string]TimeIntegratorType{
+		"ssp54":         RK_SSP54,
+		"ssp-rk54":      RK_SSP54,
+		"euler":         RK_ForwardEuler,
+		"forwardeuler":  RK_ForwardEuler,
+		"forward-euler": RK_ForwardEuler,
+		"ssp2":          RK_SSP2,
+		"ssprk2":        RK_SSP2,
+		"ssp-rk2":       RK_SSP2,
+		"ssp3":          RK_SSP3,
+		"ssprk3":        RK_SSP3,
+		"ssp-rk3":       RK_SSP3,
+		"rk4":           RK_Classic4,
+		"classic4":      RK_Classic4,
+		"lsrk4":         RK_LowStorage4,
+		"lowstorage":    RK_LowStorage4,
+		"low-storage":   RK_LowStorage4,
+	}
+	TimeIntegratorPrintNames = []string{"SSP54", "Forward Euler", "SSP-RK2", "SSP-RK3", "RK4", "Low Storage RK4"}
+)
+
+func (ti TimeIntegratorType) Print() string {
+	return TimeIntegratorPrintNames[ti]
+}
+
+func NewTimeIntegratorType(label string) (ti TimeIntegratorType) {
+	var (
+		ok  bool
+		err error
+	)
+	if len(label) == 0 {
+		return RK_SSP54
+	}
+	label = strings.ToLower(strings.TrimSpace(label))
+	if ti, ok = TimeIntegratorNames[label]; !ok {
+		err = fmt.Errorf("unable to use time integrator named [%s]", label)
+		panic(err)
+	}
+	return
+}
+
+/*
+A TimeIntegrator advances the solution through the stages of an explicit Runge Kutta step. The solution is register 0,
+the other registers hold intermediate states and are allocated by the caller. For each stage the caller computes
+dT*RHS using the register named by StageInput, then calls Advance once for each variable with the registers of that
+variable. The last stage updates the solution and leaves the change in the solution over the step in R.
+*/
+type TimeIntegrator interface {
+	Print() string
+	NumStages() int
+	NumRegisters() int        // Number of registers in addition to the solution
+	StageInput(stage int) int // Register used to compute the RHS of the stage
+	Advance(stage int, U [][]float64, R, dtRHS []float64)
+}
+
+func NewTimeIntegrator(ti TimeIntegratorType) (integrator TimeIntegrator) {
+	switch ti {
+	case RK_SSP54:
+		integrator = &SSP54{}
+	case RK_ForwardEuler:
+		integrator = &ForwardEuler{}
+	case RK_SSP2:
+		integrator = &SSPRK2{}
+	case RK_SSP3:
+		integrator = &SSPRK3{}
+	case RK_Classic4:
+		integrator = &ClassicRK4{}
+	case RK_LowStorage4:
+		integrator = NewLowStorageRK4()
+	}
+	return
+}
+
+/*
+SSP54 RK Coefficients from:
+"A numerical study of diagonally split Runge-Kutta methods for PDEs with discontinuities"
+Colin B. Macdonald, Sigal Gottlieb and Steven J. Ruuth, 2007
+*/
+type SSP54 struct{}
+
+func (rk *SSP54) Print() string            { return RK_SSP54.Print() }
+func (rk *SSP54) NumStages() int           { return 5 }
+func (rk *SSP54) NumRegisters() int        { return 4 }
+func (rk *SSP54) StageInput(stage int) int { return stage }
+
+func (rk *SSP54) Advance(stage int, U [][]float64, R, dtRHS []float64) {
+	var (
+		U0, U1, U2, U3, U4 = U[0], U[1], U[2], U[3], U[4]
+	)
+	switch stage {
+	case 0:
+		for i := range U0 {
+			U1[i] = U0[i] + 0.391752226571890*dtRHS[i]
+		}
+	case 1:
+		for i := range U0 {
+			U2[i] = 0.444370493651235*U0[i] + 0.555629506348765*U1[i] + 0.368410593050371*dtRHS[i]
+		}
+	case 2:
+		for i := range U0 {
+			U3[i] = 0.620101851488403*U0[i] + 0.379898148511597*U2[i] + 0.251891774271694*dtRHS[i]
+		}
+	case 3:
+		for i := range U0 {
+			R[i] = dtRHS[i] // Store the current RHS for use in the last RK step
+			U4[i] = 0.178079954393132*U0[i] + 0.821920045606868*U3[i] + 0.544974750228521*dtRHS[i]
+		}
+	case 4:
+		for i := range U0 {
+			R[i] = -U0[i] + 0.517231671970585*U2[i] + 0.096059710526146*U3[i] + 0.386708617503269*U4[i] +
+				0.063692468666290*R[i] + 0.226007483236906*dtRHS[i]
+			U0[i] += R[i]
+		}
+	}
+}
+
+type ForwardEuler struct{}
+
+func (rk *ForwardEuler) Print() string            { return RK_ForwardEuler.Print() }
+func (rk *ForwardEuler) NumStages() int           { return 1 }
+func (rk *ForwardEuler) NumRegisters() int        { return 0 }
+func (rk *ForwardEuler) StageInput(stage int) int { return 0 }
+
+func (rk *ForwardEuler) Advance(stage int, U [][]float64, R, dtRHS []float64) {
+	U0 := U[0]
+	for i := range U0 {
+		R[i] = dtRHS[i]
+		U0[i] += R[i]
+	}
+}
+
+// Two stage, second order SSP RK (Heun's method) in the Shu-Osher form
+type SSPRK2 struct{}
+
+func (rk *SSPRK2) Print() string            { return RK_SSP2.Print() }
+func (rk *SSPRK2) NumStages() int           { return 2 }
+func (rk *SSPRK2) NumRegisters() int        { return 1 }
+func (rk *SSPRK2) StageInput(stage int) int { return stage }
+
+func (rk *SSPRK2) Advance(stage int, U [][]float64, R, dtRHS []float64) {
+	var (
+		U0, U1 = U[0], U[1]
+	)
+	switch stage {
+	case 0:
+		for i := range U0 {
+			U1[i] = U0[i] + dtRHS[i]
+		}
+	case 1:
+		for i := range U0 {
+			R[i] = 0.5*(U1[i]-U0[i]) + 0.5*dtRHS[i]
+			U0[i] += R[i]
+		}
+	}
+}
+
+// Three stage, third order SSP RK of Shu and Osher, the second stage overwrites the first in place
+type SSPRK3 struct{}
+
+func (rk *SSPRK3) Print() string     { return RK_SSP3.Print() }
+func (rk *SSPRK3) NumStages() int    { return 3 }
+func (rk *SSPRK3) NumRegisters() int { return 1 }
+func (rk *SSPRK3) StageInput(stage int) int {
+	if stage == 0 {
+		return 0
+	}
+	return 1
+}
+
+func (rk *SSPRK3) Advance(stage int, U [][]float64, R, dtRHS []float64) {
+	var (
+		U0, U1 = U[0], U[1]
+	)
+	switch stage {
+	case 0:
+		for i := range U0 {
+			U1[i] = U0[i] + dtRHS[i]
+		}
+	case 1:
+		for i := range U0 {
+			U1[i] = 0.75*U0[i] + 0.25*U1[i] + 0.25*dtRHS[i]
+		}
+	case 2:
+		for i := range U0 {
+			R[i] = 2. / 3. * (U1[i] - U0[i] + dtRHS[i])
+			U0[i] += R[i]
+		}
+	}
+}
+
+// Classical fourth order RK, the weighted sum of the stage derivatives is accumulated in R
+type ClassicRK4 struct{}
+
+func (rk *ClassicRK4) Print() string     { return RK_Classic4.Print() }
+func (rk *ClassicRK4) NumStages() int    { return 4 }
+func (rk *ClassicRK4) NumRegisters() int { return 1 }
+func (rk *ClassicRK4) StageInput(stage int) int {
+	if stage == 0 {
+		return 0
+	}
+	return 1
+}
+
+func (rk *ClassicRK4) Advance(stage int, U [][]float64, R, dtRHS []float64) {
+	var (
+		U0, U1 = U[0], U[1]
+	)
+	switch stage {
+	case 0:
+		for i := range U0 {
+			R[i] = dtRHS[i] / 6.
+			U1[i] = U0[i] + 0.5*dtRHS[i]
+		}
+	case 1:
+		for i := range U0 {
+			R[i] += dtRHS[i] / 3.
+			U1[i] = U0[i] + 0.5*dtRHS[i]
+		}
+	case 2:
+		for i := range U0 {
+			R[i] += dtRHS[i] / 3.
+			U1[i] = U0[i] + dtRHS[i]
+		}
+	case 3:
+		for i := range U0 {
+			R[i] += dtRHS[i] / 6.
+			U0[i] += R[i]
+		}
+	}
+}
+
+/*
+Five stage, fourth order low storage (2N) RK from:
+"Fourth-Order 2N-Storage Runge-Kutta Schemes", Mark H. Carpenter and Christopher A. Kennedy, NASA TM 109112, 1994
+
+Each stage is:
+
+	dU = A[s]*dU + dT*RHS(U)
+	U  = U + B[s]*dU
+
+so the solution is updated in place and dU is the only other register. R accumulates the change over the step.
+*/
+type LowStorageRK4 struct {
+	A, B [5]float64
+}
+
+func NewLowStorageRK4() (rk *LowStorageRK4) {
+	rk = &LowStorageRK4{
+		A: [5]float64{
+			0.,
+			-567301805773. / 1357537059087.,
+			-2404267990393. / 2016746695238.,
+			-3550918686646. / 2091501179385.,
+			-1275806237668. / 842570457699.,
+		},
+		B: [5]float64{
+			1432997174477. / 9575080441755.,
+			5161836677717. / 13612068292357.,
+			1720146321549. / 2090206949498.,
+			3134564353537. / 4481467310338.,
+			2277821191437. / 14882151754819.,
+		},
+	}
+	return
+}
+
+func (rk *LowStorageRK4) Print() string            { return RK_LowStorage4.Print() }
+func (rk *LowStorageRK4) NumStages() int           { return 5 }
+func (rk *LowStorageRK4) NumRegisters() int        { return 1 }
+func (rk *LowStorageRK4) StageInput(stage int) int { return 0 }
+
+func (rk *LowStorageRK4) Advance(stage int, U [][]float64, R, dtRHS []float64) {
+	var (
+		U0, dU = U[0], U[1]
+		a, b   = rk.A[stage], rk.B[stage]
+	)
+	for i := range U0 {
+		dU[i] = a*dU[i] + dtRHS[i]
+		U0[i] += b * dU[i]
+		if stage == 0 {
+			R[i] = b * dU[i]
+		} else {
+			R[i] += b * dU[i]
+		}
+	}
+}
@@ -0,0 +1,359 @@
+package Euler2D
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/notargets/gocfd/DG2D"
+	"github.com/notargets/gocfd/types"
+	"github.com/notargets/gocfd/utils"
+)
+
+type ViscosityModel uint8
+
+const (
+	VISCOSITY_Sutherland ViscosityModel = iota
+	VISCOSITY_Constant
+)
+
+var (
+	ViscosityModelNames = map[string]ViscosityModel{
+		"sutherland": VISCOSITY_Sutherland,
+		"constant":   VISCOSITY_Constant,
+	}
+	ViscosityModelPrintNames = []string{"Sutherland", "Constant"}
+)
+
+func (vm ViscosityModel) Print() string {
+	return ViscosityModelPrintNames[vm]
+}
+
+func NewViscosityModel(label string) (vm ViscosityModel) {
+	var (
+		ok  bool
+		err error
+	)
+	if len(label) == 0 {
+		return VISCOSITY_Sutherland
+	}
+	label = strings.ToLower(strings.TrimSpace(label))
+	if vm, ok = ViscosityModelNames[label]; !ok {
+		err = fmt.Errorf("unable to use viscosity model named [%s]", label)
+		panic(err)
+	}
+	return
+}
+
+/*
+A no slip wall is adiabatic unless a wall temperature is given. Parameters are read from the BCs section of the input
+using the wall's tag, all are optional:
+
+	T      - Wall temperature relative to the freestream temperature, makes the wall isothermal
+	U, V   - Translational velocity of the wall
+	Omega  - Angular velocity of the wall about (X0, Y0), positive counter clockwise
+
+Velocities are in solver units, where the freestream speed of sound is 1.
+*/
+type NoSlipWall struct {
+	Tag         types.BCTAG
+	Isothermal  bool
+	Temperature float64
+	Velocity    [2]float64
+	Omega       float64
+	Center      [2]float64
+}
+
+func NewNoSlipWall(tag types.BCTAG, params map[string]float64) (w *NoSlipWall) {
+	w = &NoSlipWall{
+		Tag:      tag,
+		Velocity: [2]float64{params["u"], params["v"]},
+		Omega:    params["omega"],
+		Center:   [2]float64{params["x0"], params["y0"]},
+	}
+	w.Temperature, w.Isothermal = params["t"]
+	return
+}
+
+func (w *NoSlipWall) GetVelocity(x, y float64) (u, v float64) {
+	u = w.Velocity[0] - w.Omega*(y-w.Center[1])
+	v = w.Velocity[1] + w.Omega*(x-w.Center[0])
+	return
+}
+
+/*
+The compressible Navier-Stokes equations add the divergence of a viscous flux to the Euler equations:
+
+	dQ/dt = -Div(F,G) + Div(Fv,Gv)
+
+Where:
+
+	Fv = [0, Txx, Txy, u*Txx + v*Txy - qx]
+	Gv = [0, Txy, Tyy, u*Txy + v*Tyy - qy]
+	Txx = mu*(4/3*ux - 2/3*vy), Tyy = mu*(4/3*vy - 2/3*ux), Txy = mu*(uy + vx)
+	(qx, qy) = -mu/(Pr*(Gamma-1)) * Grad(Theta), with Theta = C*C = Gamma*p/rho, which is T/Tinf in solver units
+
+The gradient of the solution is computed using the RT element from the solution values in the interior and the
+single valued edge solution (QFluxForGradient), then the normal viscous flux on each edge is stored from the element on
+the opposite side (ViscousFluxForNavierStokes), which is the alternating flux choice of the Local Discontinuous
+Galerkin (LDG) method of Cockburn and Shu. On no slip walls, the edge solution is replaced by the wall state.
+*/
+type NavierStokes struct {
+	Reynolds, Prandtl, Gamma float64
+	MuInf                    float64 // Freestream dynamic viscosity in solver units
+	Model                    ViscosityModel
+	SutherlandRatio          float64                       // Sutherland constant divided by the freestream temperature
+	Walls                    map[types.EdgeKey]*NoSlipWall // Wall edges, not sharded
+	DOF, DOF2, Div           []utils.Matrix                // Sharded NpFlux x Kmax, DOF for gradient and divergence
+	GradX, GradY             [][4]utils.Matrix             // Sharded NpFlux x Kmax, gradient of the solution
+	FluxX, FluxY             [][4]utils.Matrix             // Sharded NpFlux x Kmax, viscous flux
+	PMap                     *PartitionMap
+	dfr                      *DG2D.DFR2D
+}
+
+func NewNavierStokes(ip *InputParameters, fs *FreeStream, dfr *DG2D.DFR2D, pm *PartitionMap) (ns *NavierStokes) {
+	var (
+		NPar       = pm.ParallelDegree
+		Np         = dfr.SolutionElement.Np
+		NpFlux     = dfr.FluxElement.Np
+		velocity   = fs.Minf
+		length     = ip.ReferenceLength
+		sutherland = ip.SutherlandConstant
+		tRef       = ip.ReferenceTemperature
+	)
+	if ip.Reynolds <= 0 {
+		panic(fmt.Errorf("reynolds number must be positive, have %8.5f", ip.Reynolds))
+	}
+	if velocity == 0 { // Use the speed of sound as the velocity scale for flows started from rest
+		velocity = 1
+	}
+	if length == 0 {
+		length = 1
+	}
+	if sutherland == 0 {
+		sutherland = 110.4
+	}
+	if tRef == 0 {
+		tRef = 288.15
+	}
+	ns = &NavierStokes{
+		Reynolds:        ip.Reynolds,
+		Prandtl:         ip.Prandtl,
+		Gamma:           fs.Gamma,
+		MuInf:           fs.Qinf[0] * velocity * length / ip.Reynolds,
+		Model:           NewViscosityModel(ip.ViscosityModel),
+		SutherlandRatio: sutherland / tRef,
+		Walls:           make(map[types.EdgeKey]*NoSlipWall),
+		DOF:             make([]utils.Matrix, NPar),
+		DOF2:            make([]utils.Matrix, NPar),
+		Div:             make([]utils.Matrix, NPar),
+		GradX:           make([][4]utils.Matrix, NPar),
+		GradY:           make([][4]utils.Matrix, NPar),
+		FluxX:           make([][4]utils.Matrix, NPar),
+		FluxY:           make([][4]utils.Matrix, NPar),
+		PMap:            pm,
+		dfr:             dfr,
+	}
+	if ns.Prandtl == 0 {
+		ns.Prandtl = 0.72
+	}
+	for tag, edges := range dfr.BCEdges {
+		switch tag.GetFLAG() {
+		case types.BC_Wall, types.BC_Cyl:
+			w := NewNoSlipWall(tag, ip.GetBCParameters(tag))
+			for _, e := range edges {
+				ns.Walls[e.GetKey()] = w
+			}
+		}
+	}
+	for np := 0; np < NPar; np++ {
+		Kmax := pm.GetBucketDimension(np)
+		ns.DOF[np] = utils.NewMatrix(NpFlux, Kmax)
+		ns.DOF2[np] = utils.NewMatrix(NpFlux, Kmax)
+		ns.Div[np] = utils.NewMatrix(Np, Kmax)
+		for n := 0; n < 4; n++ {
+			ns.GradX[np][n] = utils.NewMatrix(NpFlux, Kmax)
+			ns.GradY[np][n] = utils.NewMatrix(NpFlux, Kmax)
+			ns.FluxX[np][n] = utils.NewMatrix(NpFlux, Kmax)
+			ns.FluxY[np][n] = utils.NewMatrix(NpFlux, Kmax)
+		}
+	}
+	return
+}
+
+func (ns *NavierStokes) Print() string {
+	return fmt.Sprintf("Navier-Stokes: Reynolds = %8.3e, Prandtl = %5.3f, Viscosity Model: [%s], Mu = %8.3e, %d wall edges",
+		ns.Reynolds, ns.Prandtl, ns.Model.Print(), ns.MuInf, len(ns.Walls))
+}
+
+func (ns *NavierStokes) GetViscosity(theta float64) (mu float64) {
+	switch ns.Model {
+	case VISCOSITY_Constant:
+		mu = ns.MuInf
+	case VISCOSITY_Sutherland:
+		S := ns.SutherlandRatio
+		mu = ns.MuInf * theta * math.Sqrt(theta) * (1. + S) / (theta + S)
+	}
+	return
+}
+
+func (ns *NavierStokes) GetDiffusivity(Q [4]utils.Matrix, ind int) (nu float64) {
+	// The largest kinematic diffusion coefficient, used for the time step limit
+	var (
+		rho   = Q[0].DataP[ind]
+		theta = ns.getTheta(rho, Q[1].DataP[ind], Q[2].DataP[ind], Q[3].DataP[ind])
+	)
+	nu = ns.GetViscosity(theta) / rho * math.Max(4./3., ns.Gamma/ns.Prandtl)
+	return
+}
+
+func (ns *NavierStokes) getTheta(rho, rhoU, rhoV, E float64) (theta float64) {
+	var (
+		u, v = rhoU / rho, rhoV / rho
+	)
+	theta = ns.Gamma * (ns.Gamma - 1.) * (E/rho - 0.5*(u*u+v*v))
+	return
+}
+
+func (ns *NavierStokes) GetViscousFlux(Q, QX, QY [4]float64) (Fx, Fy [4]float64) {
+	/*
+		Calculates the viscous flux from the solution and the gradient of the solution
+	*/
+	var (
+		Gamma    = ns.Gamma
+		rho, E   = Q[0], Q[3]
+		oorho    = 1. / rho
+		u, v     = Q[1] * oorho, Q[2] * oorho
+		ux       = (QX[1] - u*QX[0]) * oorho
+		uy       = (QY[1] - u*QY[0]) * oorho
+		vx       = (QX[2] - v*QX[0]) * oorho
+		vy       = (QY[2] - v*QY[0]) * oorho
+		gg1      = Gamma * (Gamma - 1.)
+		thetaX   = gg1 * ((QX[3]-E*oorho*QX[0])*oorho - (u*ux + v*vx))
+		thetaY   = gg1 * ((QY[3]-E*oorho*QY[0])*oorho - (u*uy + v*vy))
+		mu       = ns.GetViscosity(ns.getTheta(Q[0], Q[1], Q[2], Q[3]))
+		kappa    = mu / (ns.Prandtl * (Gamma - 1.))
+		div      = ux + vy
+		txx, tyy = mu * (2.*ux - 2./3.*div), mu * (2.*vy - 2./3.*div)
+		txy      = mu * (uy + vx)
+	)
+	Fx = [4]float64{0, txx, txy, u*txx + v*txy + kappa*thetaX}
+	Fy = [4]float64{0, txy, tyy, u*txy + v*tyy + kappa*thetaY}
+	return
+}
+
+func (ns *NavierStokes) SetWallGradientState(en types.EdgeKey, kGlobal, edgeNumber int, qFace [][4]float64) {
+	/*
+		Replaces the edge solution used for the gradient with the no slip wall state, which retains the density and,
+		for adiabatic walls, the temperature of the interior solution
+	*/
+	var (
+		w, ok  = ns.Walls[en]
+		Gamma  = ns.Gamma
+		K      = ns.dfr.K
+		Nint   = ns.dfr.FluxElement.NpInt
+		NpEdge = ns.dfr.FluxElement.NpEdge
+		shift  = edgeNumber * NpEdge
+		X, Y   = ns.dfr.FluxX.DataP, ns.dfr.FluxY.DataP
+	)
+	if !ok {
+		return
+	}
+	for i := 0; i < NpEdge; i++ {
+		var (
+			ind   = kGlobal + (2*Nint+shift+i)*K
+			rho   = qFace[i][0]
+			u, v  = w.GetVelocity(X[ind], Y[ind])
+			theta = w.Temperature
+		)
+		if !w.Isothermal {
+			theta = ns.getTheta(qFace[i][0], qFace[i][1], qFace[i][2], qFace[i][3])
+		}
+		p := rho * theta / Gamma
+		qFace[i] = [4]float64{rho, rho * u, rho * v, p/(Gamma-1.) + 0.5*rho*(u*u+v*v)}
+	}
+}
+
+func (ns *NavierStokes) CalculateViscousFlux(c *Euler, myThread int, Q [4]utils.Matrix) {
+	/*
+		Calculates the viscous flux at the interior points and on the edges of each element
+	*/
+	var (
+		Kmax         = ns.PMap.GetBucketDimension(myThread)
+		Nint         = ns.dfr.FluxElement.NpInt
+		NpEdge       = ns.dfr.FluxElement.NpEdge
+		GradX, GradY = ns.GradX[myThread], ns.GradY[myThread]
+		FluxX, FluxY = ns.FluxX[myThread], ns.FluxY[myThread]
+		edgeVals     [4][]float64
+		sign         int
+	)
+	for n := 0; n < 4; n++ {
+		c.GetSolutionGradientUsingRTElement(myThread, n, Q, GradX[n], GradY[n], ns.DOF[myThread], ns.DOF2[myThread])
+	}
+	setFlux := func(ind int, q [4]float64) {
+		var qx, qy [4]float64
+		for n := 0; n < 4; n++ {
+			qx[n], qy[n] = GradX[n].DataP[ind], GradY[n].DataP[ind]
+		}
+		Fx, Fy := ns.GetViscousFlux(q, qx, qy)
+		for n := 0; n < 4; n++ {
+			FluxX[n].DataP[ind], FluxY[n].DataP[ind] = Fx[n], Fy[n]
+		}
+	}
+	for k := 0; k < Kmax; k++ {
+		for i := 0; i < Nint; i++ {
+			ind := k + i*Kmax
+			setFlux(ind, [4]float64{Q[0].DataP[ind], Q[1].DataP[ind], Q[2].DataP[ind], Q[3].DataP[ind]})
+		}
+		for edgeNum := 0; edgeNum < 3; edgeNum++ {
+			for n := 0; n < 4; n++ {
+				edgeVals[n], sign = c.EdgeStore.GetEdgeValues(QFluxForGradient, myThread, k, n, edgeNum, ns.dfr)
+			}
+			var (
+				ii    int
+				shift = edgeNum * NpEdge
+			)
+			for i := 0; i < NpEdge; i++ {
+				if sign < 0 {
+					ii = NpEdge - 1 - i
+				} else {
+					ii = i
+				}
+				ind := k + (2*Nint+i+shift)*Kmax
+				setFlux(ind, [4]float64{edgeVals[0][ii], edgeVals[1][ii], edgeVals[2][ii], edgeVals[3][ii]})
+			}
+		}
+	}
+}
+
+func (ns *NavierStokes) StoreViscousEdgeFlux(c *Euler, edgeKeys EdgeKeySlice, EdgeQ1 [][4]float64) {
+	var (
+		K      = ns.dfr.K
+		Nint   = ns.dfr.FluxElement.NpInt
+		NpEdge = ns.dfr.FluxElement.NpEdge
+		X, Y   = ns.dfr.FluxX.DataP, ns.dfr.FluxY.DataP
+	)
+	adiabaticWallFlux := func(en types.EdgeKey, normal [2]float64, normalFlux [][4]float64) {
+		// No heat flux through adiabatic walls, only the work done by the wall shear stress
+		w, ok := ns.Walls[en]
+		if !ok || w.Isothermal {
+			return
+		}
+		var (
+			e       = ns.dfr.Tris.Edges[en]
+			kGlobal = int(e.ConnectedTris[0])
+			shift   = int(e.ConnectedTriEdgeNumber[0]) * NpEdge
+		)
+		for i := 0; i < NpEdge; i++ {
+			ind := kGlobal + (2*Nint+shift+i)*K
+			u, v := w.GetVelocity(X[ind], Y[ind])
+			normalFlux[i][3] = u*normalFlux[i][1] + v*normalFlux[i][2]
+		}
+	}
+	c.StoreNormalEdgeFlux(edgeKeys, EdgeQ1, ViscousFluxForNavierStokes, ns.FluxX, ns.FluxY, adiabaticWallFlux)
+}
+
+func (ns *NavierStokes) AddViscousFlux(c *Euler, myThread int, Jinv, Jdet utils.Matrix, RHSQ [4]utils.Matrix) {
+	c.AddFluxDivergence(myThread, ViscousFluxForNavierStokes, Jinv, Jdet, ns.FluxX[myThread], ns.FluxY[myThread],
+		ns.DOF[myThread], ns.Div[myThread], RHSQ)
+}
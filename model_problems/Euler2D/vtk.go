@@ -0,0 +1,232 @@
+package Euler2D
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/notargets/gocfd/DG2D"
+	"github.com/notargets/gocfd/utils"
+)
+
+/*
+VTK output of the solution for post processing in ParaView and similar tools
+
+Each element is written as a VTK Lagrange triangle (cell type 69) of the same polynomial order as the solution, so the
+high order content of the solution is preserved. VTK Lagrange cells use equispaced nodes in a fixed order, so the
+solution is interpolated from the solution points to the equispaced nodes within each element. Nodes are not shared
+between elements, which keeps the element to element discontinuities of the DFR solution visible.
+*/
+const VTK_LAGRANGE_TRIANGLE = 69
+
+type VTKOutput struct {
+	Prefix     string         // Base name of the output, files are named Prefix_NNNNNN.vtu and Prefix.pvd
+	Fields     []FlowFunction // Flow functions written in addition to the conserved variables
+	Order      int            // Order of the VTK Lagrange cells, equal to the solution order (minimum 1)
+	Nodes      [][2]int       // Equispaced node indices in VTK order
+	Interp     utils.Matrix   // Interpolates from the solution points to the VTK nodes
+	X, Y       utils.Matrix   // Coordinates of the VTK nodes, Npts x K
+	Collection *PVDCollection
+}
+
+func (c *Euler) NewVTKOutput(prefix string, fields []FlowFunction) (vo *VTKOutput) {
+	var (
+		order = c.dfr.N
+	)
+	if order < 1 {
+		order = 1
+	}
+	vo = &VTKOutput{
+		Prefix:     prefix,
+		Fields:     fields,
+		Order:      order,
+		Nodes:      VTKLagrangeTriangleNodes(order),
+		Collection: &PVDCollection{FileName: prefix + ".pvd"},
+	}
+	var (
+		Npts = len(vo.Nodes)
+		R, S = utils.NewVector(Npts), utils.NewVector(Npts)
+	)
+	for i, ij := range vo.Nodes {
+		R.DataP[i] = -1. + 2.*float64(ij[0])/float64(order)
+		S.DataP[i] = -1. + 2.*float64(ij[1])/float64(order)
+	}
+	vo.Interp = c.dfr.SolutionBasis.GetInterpMatrix(R, S)
+	vo.X, vo.Y = DG2D.CalculateElementLocalGeometry(c.dfr.Tris.EToV, c.dfr.VX, c.dfr.VY, R, S)
+	return
+}
+
+func VTKLagrangeTriangleNodes(order int) (nodes [][2]int) {
+	/*
+		Returns the [i,j] index of each equispaced node within the unit triangle in the order used by VTK for Lagrange
+		triangles, where the node location is (r,s) = (-1 + 2i/order, -1 + 2j/order)
+		The ordering is: the three corners, the interior points of each of the three edges traversed counter clockwise,
+		then the interior points, which are ordered recursively as a triangle of order-3
+	*/
+	var (
+		recurse func(order, offset int)
+	)
+	recurse = func(order, offset int) {
+		switch {
+		case order < 0:
+			return
+		case order == 0:
+			nodes = append(nodes, [2]int{offset, offset})
+			return
+		}
+		nodes = append(nodes,
+			[2]int{offset, offset}, [2]int{offset + order, offset}, [2]int{offset, offset + order})
+		for i := 1; i < order; i++ { // Edge 0 -> 1
+			nodes = append(nodes, [2]int{offset + i, offset})
+		}
+		for i := 1; i < order; i++ { // Edge 1 -> 2
+			nodes = append(nodes, [2]int{offset + order - i, offset + i})
+		}
+		for i := 1; i < order; i++ { // Edge 2 -> 0
+			nodes = append(nodes, [2]int{offset, offset + order - i})
+		}
+		recurse(order-3, offset+1)
+	}
+	recurse(order, 0)
+	return
+}
+
+func (c *Euler) WriteVTU(fileName string, vo *VTKOutput) (err error) {
+	var (
+		Q        = c.RecombineShardsKBy4(c.Q)
+		Kmax     = c.dfr.K
+		Npts     = len(vo.Nodes)
+		QI       [4]utils.Matrix
+		f        *os.File
+		elements = c.MeshFileElements()
+	)
+	for n := 0; n < 4; n++ {
+		QI[n] = vo.Interp.Mul(Q[n])
+	}
+	if f, err = os.Create(fileName); err != nil {
+		return
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	fmt.Fprintf(w, "<?xml version=\"1.0\"?>\n")
+	fmt.Fprintf(w, "<VTKFile type=\"UnstructuredGrid\" version=\"1.0\" byte_order=\"LittleEndian\" header_type=\"UInt64\">\n")
+	fmt.Fprintf(w, "<UnstructuredGrid>\n")
+	fmt.Fprintf(w, "<Piece NumberOfPoints=\"%d\" NumberOfCells=\"%d\">\n", Npts*Kmax, Kmax)
+	// Point coordinates, ordered element by element in the mesh file element order
+	fmt.Fprintf(w, "<Points>\n<DataArray type=\"Float64\" NumberOfComponents=\"3\" format=\"ascii\">\n")
+	for _, k := range elements {
+		for i := 0; i < Npts; i++ {
+			ind := k + i*Kmax
+			fmt.Fprintf(w, "%.16g %.16g 0\n", vo.X.DataP[ind], vo.Y.DataP[ind])
+		}
+	}
+	fmt.Fprintf(w, "</DataArray>\n</Points>\n")
+	// Cells
+	fmt.Fprintf(w, "<Cells>\n<DataArray type=\"Int64\" Name=\"connectivity\" format=\"ascii\">\n")
+	for k := 0; k < Kmax; k++ {
+		for i := 0; i < Npts; i++ {
+			fmt.Fprintf(w, "%d ", k*Npts+i)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+	fmt.Fprintf(w, "</DataArray>\n<DataArray type=\"Int64\" Name=\"offsets\" format=\"ascii\">\n")
+	for k := 0; k < Kmax; k++ {
+		fmt.Fprintf(w, "%d\n", (k+1)*Npts)
+	}
+	fmt.Fprintf(w, "</DataArray>\n<DataArray type=\"UInt8\" Name=\"types\" format=\"ascii\">\n")
+	for k := 0; k < Kmax; k++ {
+		fmt.Fprintf(w, "%d\n", VTK_LAGRANGE_TRIANGLE)
+	}
+	fmt.Fprintf(w, "</DataArray>\n</Cells>\n")
+	// Solution fields
+	fmt.Fprintf(w, "<PointData Scalars=\"Density\">\n")
+	writeField := func(name string, fn func(ind int) float64) {
+		fmt.Fprintf(w, "<DataArray type=\"Float64\" Name=\"%s\" format=\"ascii\">\n", name)
+		for _, k := range elements {
+			for i := 0; i < Npts; i++ {
+				fmt.Fprintf(w, "%.16g\n", fn(k+i*Kmax))
+			}
+		}
+		fmt.Fprintf(w, "</DataArray>\n")
+	}
+	for n := 0; n < 4; n++ {
+		writeField(FlowFunction(n).VTKName(), func(ind int) float64 { return QI[n].DataP[ind] })
+	}
+	for _, pf := range vo.Fields {
+		if pf <= Energy { // Already written
+			continue
+		}
+		if pf > Entropy {
+			err = fmt.Errorf("unable to write field %s to VTK output, only point flow functions are supported",
+				pf.String())
+			return
+		}
+		writeField(pf.VTKName(), func(ind int) float64 { return c.FSFar.GetFlowFunction(QI, ind, pf) })
+	}
+	fmt.Fprintf(w, "</PointData>\n")
+	fmt.Fprintf(w, "</Piece>\n</UnstructuredGrid>\n</VTKFile>\n")
+	err = w.Flush()
+	return
+}
+
+func (c *Euler) WriteVTKStep(vo *VTKOutput, Time float64, steps int) (err error) {
+	fileName := fmt.Sprintf("%s_%06d.vtu", vo.Prefix, steps)
+	if err = c.WriteVTU(fileName, vo); err != nil {
+		return
+	}
+	err = vo.Collection.AddDataSet(Time, fileName)
+	return
+}
+
+func (pm FlowFunction) VTKName() string {
+	// Names used as VTK array names, without spaces
+	return strings.Replace(pm.String(), " ", "", -1)
+}
+
+type PVDDataSet struct {
+	Time float64
+	File string
+}
+
+/*
+A PVD collection lists the VTU files of an unsteady run along with the time of each, ParaView reads it as a time
+series. The collection file is rewritten as each dataset is added so that it is usable while the solver runs.
+*/
+type PVDCollection struct {
+	FileName string
+	DataSets []PVDDataSet
+}
+
+func (pvd *PVDCollection) AddDataSet(Time float64, fileName string) (err error) {
+	pvd.DataSets = append(pvd.DataSets, PVDDataSet{Time: Time, File: fileName})
+	err = pvd.Write()
+	return
+}
+
+func (pvd *PVDCollection) Write() (err error) {
+	var (
+		f *os.File
+	)
+	if f, err = os.Create(pvd.FileName); err != nil {
+		return
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	fmt.Fprintf(w, "<?xml version=\"1.0\"?>\n")
+	fmt.Fprintf(w, "<VTKFile type=\"Collection\" version=\"0.1\" byte_order=\"LittleEndian\">\n")
+	fmt.Fprintf(w, "<Collection>\n")
+	dir := filepath.Dir(pvd.FileName)
+	for _, ds := range pvd.DataSets {
+		// Dataset file names are relative to the location of the collection file
+		file := ds.File
+		if rel, err := filepath.Rel(dir, ds.File); err == nil {
+			file = rel
+		}
+		fmt.Fprintf(w, "<DataSet timestep=\"%.16g\" group=\"\" part=\"0\" file=\"%s\"/>\n", ds.Time, file)
+	}
+	fmt.Fprintf(w, "</Collection>\n</VTKFile>\n")
+	err = w.Flush()
+	return
+}
@@ -0,0 +1,122 @@
+package Euler2D
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+/*
+A WorkerPool keeps one goroutine for each shard alive for the life of the solver, instead of starting a goroutine for
+every substep of every time step. The controller thread runs shard 0 itself and hands the same task to the other
+shards through their channels. Within a task the workers synchronize the substeps using the pool's Barrier, which also
+carries the reductions across shards like the maximum wave speed for the global time step.
+
+With Spawn set, Run starts a new goroutine for every shard and waits for them instead of using the persistent workers.
+This is how the time step was run before the pool, and is kept to benchmark the two against each other.
+*/
+type WorkerPool struct {
+	NWorkers int
+	Barrier  *Barrier
+	Spawn    bool
+	tasks    []chan func(myThread int)
+}
+
+func NewWorkerPool(NWorkers int) (wp *WorkerPool) {
+	wp = &WorkerPool{
+		NWorkers: NWorkers,
+		Barrier:  NewBarrier(NWorkers),
+		tasks:    make([]chan func(myThread int), NWorkers),
+	}
+	for np := 1; np < NWorkers; np++ {
+		wp.tasks[np] = make(chan func(myThread int), 1)
+		go wp.work(np)
+	}
+	return
+}
+
+func (wp *WorkerPool) work(myThread int) {
+	for task := range wp.tasks[myThread] {
+		task(myThread)
+		wp.Barrier.Wait()
+	}
+}
+
+func (wp *WorkerPool) Run(task func(myThread int)) {
+	// Runs task on all shards, returns after every shard has completed it
+	if wp.Spawn {
+		var wg sync.WaitGroup
+		for np := 0; np < wp.NWorkers; np++ {
+			wg.Add(1)
+			go func(myThread int) {
+				defer wg.Done()
+				task(myThread)
+			}(np)
+		}
+		wg.Wait()
+		return
+	}
+	for np := 1; np < wp.NWorkers; np++ {
+		wp.tasks[np] <- task
+	}
+	task(0)
+	wp.Barrier.Wait()
+}
+
+func (wp *WorkerPool) Close() {
+	// Stops the workers, the pool can not be used afterward
+	for np := 1; np < wp.NWorkers; np++ {
+		close(wp.tasks[np])
+	}
+}
+
+/*
+A reusable barrier for a fixed number of workers. The last worker to arrive releases the others by advancing the
+generation, waiting workers spin on the generation and yield the processor when there are more workers than processors.
+
+The reduction values are double buffered by generation: a worker leaving one reduction can enter the next one before
+the slower workers have read the results, but can not get two reductions ahead because of the barrier in between.
+*/
+type Barrier struct {
+	n          int32
+	count      int32
+	generation uint32
+	values     [2][]float64
+}
+
+func NewBarrier(n int) (b *Barrier) {
+	b = &Barrier{
+		n:      int32(n),
+		values: [2][]float64{make([]float64, n), make([]float64, n)},
+	}
+	return
+}
+
+func (b *Barrier) Wait() {
+	gen := atomic.LoadUint32(&b.generation)
+	if atomic.AddInt32(&b.count, 1) == b.n {
+		atomic.StoreInt32(&b.count, 0)
+		atomic.AddUint32(&b.generation, 1)
+		return
+	}
+	spin := runtime.GOMAXPROCS(0) >= int(b.n)
+	for i := 0; atomic.LoadUint32(&b.generation) == gen; i++ {
+		if !spin || i > 1000 {
+			runtime.Gosched()
+		}
+	}
+}
+
+func (b *Barrier) ReduceMax(myThread int, val float64) (max float64) {
+	// Returns the maximum of the values from all workers, all workers must call it
+	vals := b.values[atomic.LoadUint32(&b.generation)%2]
+	vals[myThread] = val
+	b.Wait()
+	max = vals[0]
+	for _, v := range vals[1:] {
+		if v > max {
+			max = v
+		}
+	}
+	return
+}
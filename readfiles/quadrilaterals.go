@@ -0,0 +1,81 @@
+package readfiles
+
+import (
+	"fmt"
+
+	"github.com/notargets/gocfd/utils"
+)
+
+/*
+Mesh readers store elements in a K x 4 element to vertex matrix, where triangles have -1 as their 4th vertex. The
+quadrilaterals are then split into triangles, the split preserves the outer edges of each quadrilateral and with them
+the boundary edges of the mesh.
+*/
+
+func isQuad(EToV utils.Matrix, k int) bool {
+	_, ncols := EToV.Dims()
+	return ncols == 4 && EToV.At(k, 3) >= 0
+}
+
+func getFaceVertices(EToV utils.Matrix, k, faceNumber int) (verts [2]int) {
+	// Face numbers start at 0, face i connects vertex i to the next vertex counter clockwise
+	var (
+		nv = 3
+	)
+	if isQuad(EToV, k) {
+		nv = 4
+	}
+	if faceNumber < 0 || faceNumber >= nv {
+		panic(fmt.Errorf("face number %d out of range for element %d with %d vertices", faceNumber+1, k+1, nv))
+	}
+	verts[0] = int(EToV.At(k, faceNumber))
+	verts[1] = int(EToV.At(k, (faceNumber+1)%nv))
+	return
+}
+
+func SplitQuads(VX, VY utils.Vector, EToV utils.Matrix) (K int, EToVTri utils.Matrix, NQuads int) {
+	/*
+		Splits each quadrilateral into two triangles along its shorter diagonal, which avoids the flatter of the two
+		possible triangle pairs. Triangles keep the vertex ordering of the quadrilateral and follow it in element order.
+	*/
+	var (
+		Kin, _ = EToV.Dims()
+		vx, vy = VX.DataP, VY.DataP
+	)
+	for k := 0; k < Kin; k++ {
+		if isQuad(EToV, k) {
+			NQuads++
+		}
+	}
+	K = Kin + NQuads
+	EToVTri = utils.NewMatrix(K, 3)
+	var kk int
+	setTri := func(v0, v1, v2 int) {
+		EToVTri.Set(kk, 0, float64(v0))
+		EToVTri.Set(kk, 1, float64(v1))
+		EToVTri.Set(kk, 2, float64(v2))
+		kk++
+	}
+	for k := 0; k < Kin; k++ {
+		var (
+			v = [4]int{int(EToV.At(k, 0)), int(EToV.At(k, 1)), int(EToV.At(k, 2)), -1}
+		)
+		if !isQuad(EToV, k) {
+			setTri(v[0], v[1], v[2])
+			continue
+		}
+		v[3] = int(EToV.At(k, 3))
+		dist2 := func(a, b int) float64 {
+			dx, dy := vx[a]-vx[b], vy[a]-vy[b]
+			return dx*dx + dy*dy
+		}
+		if dist2(v[0], v[2]) <= dist2(v[1], v[3]) {
+			setTri(v[0], v[1], v[2])
+			setTri(v[0], v[2], v[3])
+		} else {
+			setTri(v[0], v[1], v[3])
+			setTri(v[1], v[2], v[3])
+		}
+	}
+	return
+}
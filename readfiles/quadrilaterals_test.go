@@ -0,0 +1,51 @@
+package readfiles
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/notargets/gocfd/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitQuads(t *testing.T) {
+	var (
+		// A quadrilateral stretched in Y, so the 1-3 diagonal is shorter
+		VX = utils.NewVector(5, []float64{0, 1, 0.2, -1, 3})
+		VY = utils.NewVector(5, []float64{-2, 0, 2, 0, 0})
+	)
+	EToV := utils.NewMatrix(2, 4, []float64{
+		0, 1, 2, 3,
+		1, 4, 2, -1,
+	})
+	assert.Equal(t, [2]int{3, 0}, getFaceVertices(EToV, 0, 3))
+	assert.Equal(t, [2]int{2, 1}, getFaceVertices(EToV, 1, 2))
+	assert.Panics(t, func() { getFaceVertices(EToV, 1, 3) })
+	K, EToVTri, NQuads := SplitQuads(VX, VY, EToV)
+	assert.Equal(t, 3, K)
+	assert.Equal(t, 1, NQuads)
+	assert.Equal(t, []float64{0, 1, 3, 1, 2, 3, 1, 4, 2}, EToVTri.DataP)
+}
+
+func TestReadTrisAndQuads(t *testing.T) {
+	reader := bufio.NewReader(bytes.NewReader([]byte(
+		`       1  2  4        1       2       4       3
+       2  3  3        3       4       5
+`)))
+	EToV := ReadTrisAndQuads(2, reader)
+	assert.Equal(t, []float64{0, 1, 3, 2, 2, 3, 4, -1}, EToV.DataP)
+	// Gambit BC faces are numbered from 1 around the element, including the 4th face of quadrilaterals
+	reader = bufio.NewReader(bytes.NewReader([]byte(
+		` BOUNDARY CONDITIONS 1.3.0
+                     Wall       1       2       0       6
+       1       2       1
+       1       2       4
+ENDOFSECTION
+`)))
+	skipLines(1, reader)
+	BCEdges := ReadBCS(1, 2, 3, reader, EToV)
+	assert.Equal(t, [2]int{0, 1}, BCEdges["wall"][0].GetVertices())
+	assert.Equal(t, [2]int{2, 0}, BCEdges["wall"][1].GetVertices())
+}
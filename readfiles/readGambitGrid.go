@@ -73,7 +73,7 @@ func ReadGambit2d(filename string, verbose bool) (K int, VX, VY utils.Vector, ET
 	if bTET {
 		EToV = ReadTets(K, reader)
 	} else {
-		EToV = ReadTris(K, reader)
+		EToV = ReadTrisAndQuads(K, reader)
 	}
 	skipLines(2, reader)
 
@@ -104,6 +104,13 @@ func ReadGambit2d(filename string, verbose bool) (K int, VX, VY utils.Vector, ET
 
 	// Read BCs
 	BCEdges = ReadBCS(Nbcs, K, NFaces, reader, EToV)
+	if !bTET {
+		var NQuads int
+		K, EToV, NQuads = SplitQuads(VX, VY, EToV)
+		if verbose && NQuads != 0 {
+			fmt.Printf("Split %d quadrilaterals into %d triangles, K = %d\n", NQuads, 2*NQuads, K)
+		}
+	}
 	return
 }
 
@@ -196,7 +203,6 @@ func ReadBCS(Nbcs, K, NFaces int, reader *bufio.Reader, EToV utils.Matrix) (BCEd
 			}
 		}
 		edges := make([]types.EdgeInt, numfaces)
-		var verts [3]int
 		for i := 0; i < numfaces; i++ {
 			line = getLine(reader)
 			nargs = 3
@@ -207,19 +213,7 @@ func ReadBCS(Nbcs, K, NFaces int, reader *bufio.Reader, EToV utils.Matrix) (BCEd
 				}
 				panic(err)
 			}
-			verts[0] = int(EToV.At(kp1-1, 0))
-			verts[1] = int(EToV.At(kp1-1, 1))
-			verts[2] = int(EToV.At(kp1-1, 2))
-			var e types.EdgeInt
-			switch faceNumberp1 {
-			case 1:
-				e = types.NewEdgeInt([2]int{verts[0], verts[1]})
-			case 2:
-				e = types.NewEdgeInt([2]int{verts[1], verts[2]})
-			case 3:
-				e = types.NewEdgeInt([2]int{verts[2], verts[0]})
-			}
-			edges[i] = e
+			edges[i] = types.NewEdgeInt(getFaceVertices(EToV, kp1-1, faceNumberp1-1))
 		}
 		//fmt.Printf("Adding BC = [%s]\n", types.NewBCTAG(bctyp))
 		BCEdges.AddEdges(types.NewBCTAG(bctyp), edges)
@@ -395,33 +389,43 @@ func ReadTets(K int, reader *bufio.Reader) (EToV utils.Matrix) {
 	return
 }
 
-func ReadTris(K int, reader *bufio.Reader) (EToV utils.Matrix) {
+func ReadTrisAndQuads(K int, reader *bufio.Reader) (EToV utils.Matrix) {
 	//-------------------------------------
-	// Triangles in 3D:
+	// Triangles and Quadrilaterals in 2D:
 	//-------------------------------------
 	// ENDOFSECTION
 	//    ELEMENTS/CELLS 1.3.0
 	//      1  3  3        1       2       3
-	//      2  3  3        3       2       4
+	//      2  2  4        3       2       4       5
+	// EToV is K x 4, the 4th vertex is -1 for triangles
 	var (
 		line                       string
 		err                        error
 		n, ind, typ, nfaces, nargs int
 	)
-	EToV = utils.NewMatrix(K, 3)
+	EToV = utils.NewMatrix(K, 4)
 	for i := 0; i < K; i++ {
 		line = getLine(reader)
 		nargs = 6
-		var n1, n2, n3 int
-		if n, err = fmt.Sscanf(line, "%d %d %d %d %d %d", &ind, &typ, &nfaces, &n1, &n2, &n3); err != nil || n < nargs {
-			if err == nil && n < nargs {
+		var n1, n2, n3, n4 int
+		if n, err = fmt.Sscanf(line, "%d %d %d %d %d %d %d", &ind, &typ, &nfaces, &n1, &n2, &n3, &n4); n < nargs ||
+			(nfaces == 4 && n < nargs+1) {
+			if err == nil {
 				err = fmt.Errorf("read fewer than required dimensions, read %d, need %d\n, line: %s", n, nargs, line)
 			}
 			panic(err)
 		}
+		switch nfaces {
+		case 3:
+			n4 = 0
+		case 4:
+		default:
+			panic(fmt.Errorf("unable to deal with elements other than triangles and quadrilaterals, line: %s", line))
+		}
 		EToV.Set(ind-1, 0, float64(n1-1))
 		EToV.Set(ind-1, 1, float64(n2-1))
 		EToV.Set(ind-1, 2, float64(n3-1))
+		EToV.Set(ind-1, 3, float64(n4-1))
 	}
 	return
 }
@@ -0,0 +1,367 @@
+package readfiles
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/notargets/gocfd/types"
+
+	"github.com/notargets/gocfd/utils"
+)
+
+// From here: https://gmsh.info/doc/texinfo/gmsh.html#MSH-file-format
+type GmshElementType uint8
+
+const (
+	GmshType_Line          GmshElementType = 1
+	GmshType_Triangle                      = 2
+	GmshType_Quadrilateral                 = 3
+	GmshType_Point                         = 15
+)
+
+type gmshMesh struct {
+	Version        float64
+	PhysicalNames  map[[2]int]string // Key is [dimension, physical tag]
+	EntityPhysical map[[2]int][]int  // Physical tags for each entity, key is [dimension, entity tag], MSH 4 only
+	NodeIndex      map[int]int       // Node tag to vertex index
+	VX, VY         []float64
+	Elements       [][4]int                // Triangles and quadrilaterals, the 4th vertex is -1 for triangles
+	BCLines        map[int][]types.EdgeInt // Boundary edges for each physical line group
+}
+
+func ReadGmsh(filename string, verbose bool) (K int, VX, VY utils.Vector, EToV utils.Matrix, BCEdges types.BCMAP) {
+	/*
+		Reads an ASCII Gmsh file in MSH 2.2 or 4.1 format. Triangles and quadrilaterals form the mesh and line elements
+		that belong to a named physical group become boundary edges, where the physical group name is used as the BC
+		tag, e.g. "Wall"
+	*/
+	var (
+		file   *os.File
+		err    error
+		reader *bufio.Reader
+	)
+	if verbose {
+		fmt.Printf("Reading Gmsh file named: %s\n", filename)
+	}
+	if file, err = os.Open(filename); err != nil {
+		panic(fmt.Errorf("unable to open file %s\n %s", filename, err))
+	}
+	defer file.Close()
+	reader = bufio.NewReader(file)
+
+	gm := readGmsh(reader)
+	if verbose {
+		fmt.Printf("MSH Version %3.1f, Nv = %d, K = %d, %d boundary groups\n",
+			gm.Version, len(gm.VX), len(gm.Elements), len(gm.BCLines))
+	}
+	var NQuads int
+	K, VX, VY, EToV, BCEdges = gm.getMesh()
+	K, EToV, NQuads = SplitQuads(VX, VY, EToV)
+	if verbose && NQuads != 0 {
+		fmt.Printf("Split %d quadrilaterals into %d triangles, K = %d\n", NQuads, 2*NQuads, K)
+	}
+	return
+}
+
+func readGmsh(reader *bufio.Reader) (gm *gmshMesh) {
+	gm = &gmshMesh{
+		PhysicalNames:  make(map[[2]int]string),
+		EntityPhysical: make(map[[2]int][]int),
+		NodeIndex:      make(map[int]int),
+		BCLines:        make(map[int][]types.EdgeInt),
+	}
+	for {
+		section, ok := nextGmshSection(reader)
+		if !ok {
+			break
+		}
+		switch section {
+		case "MeshFormat":
+			gm.readFormat(reader)
+		case "PhysicalNames":
+			gm.readPhysicalNames(reader)
+		case "Entities":
+			gm.readEntities(reader)
+		case "Nodes":
+			if gm.Version < 4 {
+				gm.readNodes2(reader)
+			} else {
+				gm.readNodes4(reader)
+			}
+		case "Elements":
+			if gm.Version < 4 {
+				gm.readElements2(reader)
+			} else {
+				gm.readElements4(reader)
+			}
+		}
+		skipToGmshSectionEnd(section, reader)
+	}
+	if gm.Version == 0 {
+		panic("missing $MeshFormat section, not a Gmsh file")
+	}
+	return
+}
+
+func (gm *gmshMesh) getMesh() (K int, VX, VY utils.Vector, EToV utils.Matrix, BCEdges types.BCMAP) {
+	var (
+		Nv      = len(gm.VX)
+		physTag = make([]int, 0, len(gm.BCLines))
+	)
+	K = len(gm.Elements)
+	if K == 0 {
+		panic("no triangles or quadrilaterals found in mesh")
+	}
+	VX, VY = utils.NewVector(Nv, gm.VX), utils.NewVector(Nv, gm.VY)
+	EToV = utils.NewMatrix(K, 4)
+	for k, el := range gm.Elements {
+		// Gmsh orients elements with the surface normal, which can point in -Z, order all counter clockwise
+		var (
+			nv   = 4
+			area float64
+		)
+		if el[3] < 0 {
+			nv = 3
+		}
+		for i := 0; i < nv; i++ {
+			v1, v2 := el[i], el[(i+1)%nv]
+			area += gm.VX[v1]*gm.VY[v2] - gm.VX[v2]*gm.VY[v1]
+		}
+		if area < 0 {
+			el[1], el[nv-1] = el[nv-1], el[1]
+		}
+		for i := 0; i < 4; i++ {
+			EToV.Set(k, i, float64(el[i]))
+		}
+	}
+	// Add groups in order of physical tag so that paired groups with the same name, e.g. "Periodic", stay in order
+	for tag := range gm.BCLines {
+		physTag = append(physTag, tag)
+	}
+	sort.Ints(physTag)
+	BCEdges = make(types.BCMAP, len(physTag))
+	for _, tag := range physTag {
+		name, ok := gm.PhysicalNames[[2]int{1, tag}]
+		if !ok {
+			panic(fmt.Errorf("physical line group %d has no name, unable to map it to a BC", tag))
+		}
+		BCEdges.AddEdges(types.NewBCTAG(name), gm.BCLines[tag])
+	}
+	return
+}
+
+func (gm *gmshMesh) readFormat(reader *bufio.Reader) {
+	var (
+		fileType, dataSize int
+		err                error
+	)
+	line := getLine(reader)
+	if _, err = fmt.Sscanf(line, "%f %d %d", &gm.Version, &fileType, &dataSize); err != nil {
+		panic(fmt.Errorf("unable to read mesh format from line: [%s]", line))
+	}
+	if fileType != 0 {
+		panic("binary Gmsh files are not supported, save the mesh in ASCII format")
+	}
+	if gm.Version < 2 || gm.Version >= 5 {
+		panic(fmt.Errorf("unsupported MSH version %3.1f, use version 2.2 or 4.1", gm.Version))
+	}
+}
+
+func (gm *gmshMesh) readPhysicalNames(reader *bufio.Reader) {
+	var (
+		dim, tag int
+		err      error
+	)
+	N := readGmshInts(reader, 1)[0]
+	for i := 0; i < N; i++ {
+		line := getLine(reader)
+		if _, err = fmt.Sscanf(line, "%d %d", &dim, &tag); err != nil {
+			panic(fmt.Errorf("unable to read physical name from line: [%s]", line))
+		}
+		// The name follows the dimension and tag, it is quoted when written by Gmsh and may contain spaces
+		name := strings.TrimSpace(line)
+		for j := 0; j < 2; j++ {
+			ind := strings.IndexAny(name, " \t")
+			if ind < 0 {
+				panic(fmt.Errorf("unable to read physical name from line: [%s]", line))
+			}
+			name = strings.TrimSpace(name[ind:])
+		}
+		if name = strings.Trim(name, "\""); len(name) == 0 {
+			panic(fmt.Errorf("unable to read physical name from line: [%s]", line))
+		}
+		gm.PhysicalNames[[2]int{dim, tag}] = name
+	}
+}
+
+func (gm *gmshMesh) readEntities(reader *bufio.Reader) {
+	/*
+		Each entity lists its physical tags after its coordinates, points have 3 coordinates and the others have a
+		bounding box of 6 coordinates
+	*/
+	counts := readGmshInts(reader, 4)
+	for dim := 0; dim < 4; dim++ {
+		nCoords := 6
+		if dim == 0 {
+			nCoords = 3
+		}
+		for i := 0; i < counts[dim]; i++ {
+			fields := strings.Fields(getLine(reader))
+			tag := atoi(fields[0])
+			nPhys := atoi(fields[nCoords+1])
+			phys := make([]int, nPhys)
+			for j := range phys {
+				phys[j] = atoi(fields[nCoords+2+j])
+			}
+			gm.EntityPhysical[[2]int{dim, tag}] = phys
+		}
+	}
+}
+
+func (gm *gmshMesh) addNode(tag int, line string) {
+	var (
+		x, y float64
+		err  error
+	)
+	if _, err = fmt.Sscanf(line, "%f %f", &x, &y); err != nil {
+		panic(fmt.Errorf("unable to read coordinates from line: [%s]", line))
+	}
+	gm.NodeIndex[tag] = len(gm.VX)
+	gm.VX = append(gm.VX, x)
+	gm.VY = append(gm.VY, y)
+}
+
+func (gm *gmshMesh) readNodes2(reader *bufio.Reader) {
+	Nv := readGmshInts(reader, 1)[0]
+	for i := 0; i < Nv; i++ {
+		line := strings.TrimSpace(getLine(reader))
+		ind := strings.IndexAny(line, " \t")
+		gm.addNode(atoi(line[:ind]), line[ind+1:])
+	}
+}
+
+func (gm *gmshMesh) readNodes4(reader *bufio.Reader) {
+	// Node blocks list the node tags of the block followed by the coordinates
+	nBlocks := readGmshInts(reader, 4)[0]
+	for b := 0; b < nBlocks; b++ {
+		nNodes := readGmshInts(reader, 4)[3]
+		tags := make([]int, nNodes)
+		for i := range tags {
+			tags[i] = readGmshInts(reader, 1)[0]
+		}
+		for i := range tags {
+			gm.addNode(tags[i], getLine(reader))
+		}
+	}
+}
+
+func (gm *gmshMesh) addElement(elType GmshElementType, physTags []int, nodeTags []string) {
+	nodes := make([]int, len(nodeTags))
+	for i, nt := range nodeTags {
+		var ok bool
+		if nodes[i], ok = gm.NodeIndex[atoi(nt)]; !ok {
+			panic(fmt.Errorf("element refers to undefined node %s", nt))
+		}
+	}
+	switch elType {
+	case GmshType_Triangle:
+		gm.Elements = append(gm.Elements, [4]int{nodes[0], nodes[1], nodes[2], -1})
+	case GmshType_Quadrilateral:
+		gm.Elements = append(gm.Elements, [4]int{nodes[0], nodes[1], nodes[2], nodes[3]})
+	case GmshType_Line:
+		// Lines outside of a physical group are not boundaries
+		for _, tag := range physTags {
+			gm.BCLines[tag] = append(gm.BCLines[tag], types.NewEdgeInt([2]int{nodes[0], nodes[1]}))
+		}
+	case GmshType_Point:
+	default:
+		panic("unable to deal with elements other than triangles and quadrilaterals")
+	}
+}
+
+func (gm *gmshMesh) readElements2(reader *bufio.Reader) {
+	// Each element is: tag, type, number of tags, tags (physical tag first), nodes
+	K := readGmshInts(reader, 1)[0]
+	for k := 0; k < K; k++ {
+		var (
+			fields   = strings.Fields(getLine(reader))
+			elType   = GmshElementType(atoi(fields[1]))
+			nTags    = atoi(fields[2])
+			physTags []int
+		)
+		if nTags > 0 && atoi(fields[3]) != 0 {
+			physTags = []int{atoi(fields[3])}
+		}
+		gm.addElement(elType, physTags, fields[3+nTags:])
+	}
+}
+
+func (gm *gmshMesh) readElements4(reader *bufio.Reader) {
+	// Element blocks share the entity and the element type, physical tags come from the entity
+	nBlocks := readGmshInts(reader, 4)[0]
+	for b := 0; b < nBlocks; b++ {
+		var (
+			block    = readGmshInts(reader, 4)
+			physTags = gm.EntityPhysical[[2]int{block[0], block[1]}]
+			elType   = GmshElementType(block[2])
+		)
+		for i := 0; i < block[3]; i++ {
+			fields := strings.Fields(getLine(reader))
+			gm.addElement(elType, physTags, fields[1:])
+		}
+	}
+}
+
+func nextGmshSection(reader *bufio.Reader) (section string, ok bool) {
+	for {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "$") {
+			return line[1:], true
+		}
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+func skipToGmshSectionEnd(section string, reader *bufio.Reader) {
+	for {
+		line, err := reader.ReadString('\n')
+		if strings.TrimSpace(line) == "$End"+section {
+			return
+		}
+		if err != nil {
+			panic(fmt.Errorf("missing $End%s", section))
+		}
+	}
+}
+
+func readGmshInts(reader *bufio.Reader, n int) (vals []int) {
+	line := getLine(reader)
+	fields := strings.Fields(line)
+	if len(fields) < n {
+		panic(fmt.Errorf("read fewer than required dimensions, read %d, need %d\n, line: %s", len(fields), n, line))
+	}
+	vals = make([]int, n)
+	for i := range vals {
+		vals[i] = atoi(fields[i])
+	}
+	return
+}
+
+func atoi(field string) (val int) {
+	var err error
+	if val, err = strconv.Atoi(field); err != nil {
+		panic(err)
+	}
+	return
+}
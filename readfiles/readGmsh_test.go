@@ -0,0 +1,150 @@
+package readfiles
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/notargets/gocfd/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadGmsh(t *testing.T) {
+	for _, file := range [][]byte{gmshFile22, gmshFile41} {
+		gm := readGmsh(bufio.NewReader(bytes.NewReader(file)))
+		K, VX, VY, EToV, BCEdges := gm.getMesh()
+		K, EToV, _ = SplitQuads(VX, VY, EToV)
+		assert.Equal(t, 2, K)
+		assert.Equal(t, []float64{0, 1, 1, 0}, VX.DataP)
+		assert.Equal(t, []float64{0, 0, 1, 1}, VY.DataP)
+		// Both triangles are counter clockwise, including the clockwise one in the 4.1 file
+		assert.Equal(t, []float64{0, 1, 2, 0, 2, 3}, EToV.DataP)
+		assert.Equal(t, 2, len(BCEdges))
+		edges := BCEdges[types.NewBCTAG("Wall-bottom")]
+		assert.Equal(t, 1, len(edges))
+		assert.Equal(t, [2]int{0, 1}, edges[0].GetVertices())
+		assert.Equal(t, types.BC_Wall, types.NewBCTAG("Wall-bottom").GetFLAG())
+		edges = BCEdges["far"]
+		assert.Equal(t, 3, len(edges))
+		assert.Equal(t, [2]int{1, 2}, edges[0].GetVertices())
+		assert.Equal(t, [2]int{3, 0}, edges[2].GetVertices())
+	}
+	{ // Read from a file
+		fileName := filepath.Join(t.TempDir(), "square.msh")
+		assert.Nil(t, ioutil.WriteFile(fileName, gmshFile41, 0644))
+		K, _, _, _, BCEdges := ReadGmsh(fileName, false)
+		assert.Equal(t, 2, K)
+		assert.Equal(t, 2, len(BCEdges))
+	}
+	{ // A clockwise quadrilateral is reordered and split
+		gm := readGmsh(bufio.NewReader(bytes.NewReader(bytes.Replace(gmshFile22,
+			[]byte("7\n1 15 2 0 1 1"), []byte("7\n1 3 2 3 1 1 4 3 2"), 1))))
+		gm.Elements = gm.Elements[:1]
+		K, VX, VY, EToV, _ := gm.getMesh()
+		assert.Equal(t, []float64{0, 1, 2, 3}, EToV.DataP)
+		K, EToV, NQuads := SplitQuads(VX, VY, EToV)
+		assert.Equal(t, 2, K)
+		assert.Equal(t, 1, NQuads)
+		assert.Equal(t, []float64{0, 1, 2, 0, 2, 3}, EToV.DataP)
+	}
+	{ // Physical names are read with or without quotes, a missing name is rejected
+		gm := readGmsh(bufio.NewReader(bytes.NewReader(bytes.Replace(gmshFile22,
+			[]byte(`1 1 "Wall-bottom"`+"\n"+`1 2 "Far"`), []byte("1 1 Wall-bottom\n1\t2  \"Far field\" "), 1))))
+		assert.Equal(t, "Wall-bottom", gm.PhysicalNames[[2]int{1, 1}])
+		assert.Equal(t, "Far field", gm.PhysicalNames[[2]int{1, 2}])
+		for _, line := range []string{"1 1", "1 1 \"\""} {
+			assert.Panics(t, func() {
+				readGmsh(bufio.NewReader(bytes.NewReader(bytes.Replace(gmshFile22, []byte(`1 1 "Wall-bottom"`),
+					[]byte(line), 1))))
+			}, line)
+		}
+	}
+	// Binary files and elements other than triangles and quadrilaterals are rejected
+	assert.Panics(t, func() {
+		readGmsh(bufio.NewReader(bytes.NewReader([]byte("$MeshFormat\n4.1 1 8\n$EndMeshFormat\n"))))
+	})
+	assert.Panics(t, func() {
+		readGmsh(bufio.NewReader(bytes.NewReader(bytes.Replace(gmshFile22, []byte("7 2 2 3 1 1 3 4"),
+			[]byte("7 4 2 3 1 1 2 3 4"), 1))))
+	})
+}
+
+var (
+	gmshFile22 = []byte(`$MeshFormat
+2.2 0 8
+$EndMeshFormat
+$PhysicalNames
+3
+1 1 "Wall-bottom"
+1 2 "Far"
+2 3 "fluid"
+$EndPhysicalNames
+$Nodes
+4
+1 0 0 0
+2 1 0 0
+3 1 1 0
+4 0 1 0
+$EndNodes
+$Elements
+7
+1 15 2 0 1 1
+2 1 2 1 1 1 2
+3 1 2 2 2 2 3
+4 1 2 2 3 3 4
+5 1 2 2 4 4 1
+6 2 2 3 1 1 2 3
+7 2 2 3 1 1 3 4
+$EndElements
+`)
+	gmshFile41 = []byte(`$MeshFormat
+4.1 0 8
+$EndMeshFormat
+$PhysicalNames
+3
+1 1 "Wall-bottom"
+1 2 "Far"
+2 3 "fluid"
+$EndPhysicalNames
+$Entities
+4 4 1 0
+1 0 0 0 0
+2 1 0 0 0
+3 1 1 0 0
+4 0 1 0 0
+1 0 0 0 1 0 0 1 1 2 1 -2
+2 1 0 0 1 1 0 1 2 2 2 -3
+3 0 1 0 1 1 0 1 2 2 3 -4
+4 0 0 0 0 1 0 1 2 2 4 -1
+1 0 0 0 1 1 0 1 3 4 1 2 3 4
+$EndEntities
+$Nodes
+1 4 10 40
+2 1 0 4
+10
+20
+30
+40
+0 0 0
+1 0 0
+1 1 0
+0 1 0
+$EndNodes
+$Elements
+5 6 1 6
+1 1 1 1
+1 10 20
+1 2 1 1
+2 20 30
+1 3 1 1
+3 30 40
+1 4 1 1
+4 40 10
+2 1 2 2
+5 10 20 30
+6 10 40 30
+$EndElements`)
+)
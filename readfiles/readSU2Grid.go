@@ -74,29 +74,33 @@ func readVertices(reader *bufio.Reader) (VX, VY utils.Vector) {
 }
 
 func readElements(reader *bufio.Reader) (K int, EToV utils.Matrix) {
-	var (
-		n          int
-		nType      int
-		v1, v2, v3 int
-		err        error
-	)
-	// EToV is K x 3
+	// EToV is K x 4, the 4th vertex is -1 for triangles
 	K = readNumber(reader)
-	EToV = utils.NewMatrix(K, 3)
+	EToV = utils.NewMatrix(K, 4)
 	for k := 0; k < K; k++ {
-		line := getLine(reader)
-		if n, err = fmt.Sscanf(line, "%d %d %d %d", &nType, &v1, &v2, &v3); err != nil {
-			panic(err)
+		var (
+			line   = getLine(reader)
+			fields = strings.Fields(line)
+			nv     int
+		)
+		if len(fields) < 4 {
+			panic(fmt.Errorf("unable to read vertices from line: [%s]", line))
 		}
-		if n != 4 {
-			panic("unable to read vertices")
+		switch SU2ElementType(atoi(fields[0])) {
+		case ELType_Triangle:
+			nv = 3
+			EToV.Set(k, 3, -1)
+		case ELType_Quadrilateral:
+			nv = 4
+			if len(fields) < 5 {
+				panic(fmt.Errorf("unable to read vertices from line: [%s]", line))
+			}
+		default:
+			panic("unable to deal with elements other than triangles and quadrilaterals")
 		}
-		if SU2ElementType(nType) != ELType_Triangle {
-			panic("unable to deal with non-triangular elements right now")
+		for i := 0; i < nv; i++ {
+			EToV.Set(k, i, float64(atoi(fields[i+1])))
 		}
-		EToV.Set(k, 0, float64(v1))
-		EToV.Set(k, 1, float64(v2))
-		EToV.Set(k, 2, float64(v3))
 	}
 	return
 }
@@ -173,5 +177,10 @@ func ReadSU2(filename string, verbose bool) (K int, VX, VY utils.Vector, EToV ut
 	K, EToV = readElements(reader)
 	VX, VY = readVertices(reader)
 	BCEdges = readBCs(reader)
+	var NQuads int
+	K, EToV, NQuads = SplitQuads(VX, VY, EToV)
+	if verbose && NQuads != 0 {
+		fmt.Printf("Split %d quadrilaterals into %d triangles, K = %d\n", NQuads, 2*NQuads, K)
+	}
 	return
 }
@@ -3,6 +3,7 @@ package readfiles
 import (
 	"bufio"
 	"bytes"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -148,3 +149,74 @@ MARKER_ELEMS= 4
 % Comments can appear outside of data areas
 `)
 )
+
+func TestReadSU2Mixed(t *testing.T) {
+	/*
+		A boundary layer row of quadrilaterals below two triangles:
+			4 --- 5
+			| \ 2 |
+			| 1 \ |
+			2 --- 3
+			|  0  |
+			0 --- 1
+		The quadrilateral is a trapezoid with the shorter diagonal from 0 to 3
+	*/
+	reader := bufio.NewReader(bytes.NewReader(mixedFile))
+	_ = readNumber(reader)
+	K, EToV := readElements(reader)
+	assert.Equal(t, 3, K)
+	assert.Equal(t, []float64{0, 1, 3, 2, 2, 3, 4, -1, 3, 5, 4, -1}, EToV.DataP)
+	VX, VY := readVertices(reader)
+	BCEdges := readBCs(reader)
+	K, EToV, NQuads := SplitQuads(VX, VY, EToV)
+	assert.Equal(t, 1, NQuads)
+	assert.Equal(t, 4, K)
+	assert.Equal(t, []float64{0, 1, 3, 0, 3, 2, 2, 3, 4, 3, 5, 4}, EToV.DataP)
+	// Boundary edges of the quadrilateral are edges of the triangles
+	assert.Equal(t, [2]int{0, 1}, BCEdges["wall"][0].GetVertices())
+	assert.Equal(t, [2]int{1, 3}, BCEdges["far"][0].GetVertices())
+	assert.Equal(t, [2]int{2, 0}, BCEdges["far"][3].GetVertices())
+}
+
+func TestWriteSU2(t *testing.T) {
+	// A mesh written and read again is unchanged, the quadrilateral is split when read
+	reader := bufio.NewReader(bytes.NewReader(mixedFile))
+	_ = readNumber(reader)
+	_, EToV := readElements(reader)
+	VX, VY := readVertices(reader)
+	BCEdges := readBCs(reader)
+	fileName := filepath.Join(t.TempDir(), "mixed.su2")
+	assert.Nil(t, WriteSU2(fileName, VX, VY, EToV, BCEdges))
+	K, VXr, VYr, EToVr, BCEdgesr := ReadSU2(fileName, false)
+	assert.Equal(t, 4, K)
+	assert.Equal(t, []float64{0, 1, 3, 0, 3, 2, 2, 3, 4, 3, 5, 4}, EToVr.DataP)
+	assert.Equal(t, VX.DataP, VXr.DataP)
+	assert.Equal(t, VY.DataP, VYr.DataP)
+	assert.Equal(t, BCEdges, BCEdgesr)
+}
+
+var (
+	mixedFile = []byte(`NDIME= 2
+NELEM= 3
+9 0 1 3 2 0
+5 2 3 4 1
+5 3 5 4 2
+NPOIN= 6
+0 0 0
+2 0 1
+0 0.2 2
+1.8 0.2 3
+0 2 4
+2 2 5
+NMARK= 2
+MARKER_TAG= wall
+MARKER_ELEMS= 1
+3 0 1
+MARKER_TAG= far
+MARKER_ELEMS= 4
+3 1 3
+3 3 5
+3 4 2
+3 2 0
+`)
+)
@@ -0,0 +1,66 @@
+package readfiles
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/notargets/gocfd/types"
+
+	"github.com/notargets/gocfd/utils"
+)
+
+func WriteSU2(filename string, VX, VY utils.Vector, EToV utils.Matrix, BCEdges types.BCMAP) (err error) {
+	/*
+		Writes a 2D mesh in the format read by ReadSU2. EToV is K x 4, the 4th vertex is -1 for triangles, and the
+		vertices of each element are counter clockwise. The BC markers are written in sorted order of their tags.
+	*/
+	var (
+		file   *os.File
+		K, _   = EToV.Dims()
+		Nv     = VX.Len()
+		writer *bufio.Writer
+		tags   = make(sort.StringSlice, 0, len(BCEdges))
+	)
+	if file, err = os.Create(filename); err != nil {
+		return
+	}
+	defer func() {
+		if errC := file.Close(); err == nil {
+			err = errC
+		}
+	}()
+	writer = bufio.NewWriter(file)
+	fmt.Fprintf(writer, "NDIME= 2\n")
+	fmt.Fprintf(writer, "NELEM= %d\n", K)
+	for k := 0; k < K; k++ {
+		if EToV.At(k, 3) < 0 {
+			fmt.Fprintf(writer, "%d %d %d %d %d\n", ELType_Triangle,
+				int(EToV.At(k, 0)), int(EToV.At(k, 1)), int(EToV.At(k, 2)), k)
+		} else {
+			fmt.Fprintf(writer, "%d %d %d %d %d %d\n", ELType_Quadrilateral,
+				int(EToV.At(k, 0)), int(EToV.At(k, 1)), int(EToV.At(k, 2)), int(EToV.At(k, 3)), k)
+		}
+	}
+	fmt.Fprintf(writer, "NPOIN= %d\n", Nv)
+	for i := 0; i < Nv; i++ {
+		fmt.Fprintf(writer, "%.16g %.16g %d\n", VX.AtVec(i), VY.AtVec(i), i)
+	}
+	for tag := range BCEdges {
+		tags = append(tags, string(tag))
+	}
+	tags.Sort()
+	fmt.Fprintf(writer, "NMARK= %d\n", len(tags))
+	for _, tag := range tags {
+		edges := BCEdges[types.BCTAG(tag)]
+		fmt.Fprintf(writer, "MARKER_TAG= %s\n", tag)
+		fmt.Fprintf(writer, "MARKER_ELEMS= %d\n", len(edges))
+		for _, e := range edges {
+			verts := e.GetVertices()
+			fmt.Fprintf(writer, "%d %d %d\n", ELType_LINE, verts[0], verts[1])
+		}
+	}
+	err = writer.Flush()
+	return
+}
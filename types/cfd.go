@@ -23,6 +23,7 @@ const (
 	BC_IVortex
 	BC_Periodic
 	BC_PeriodicReversed
+	BC_Exact // Exact state of an analytic or manufactured solution
 )
 
 var BCNameMap = map[string]BCFLAG{
@@ -50,18 +51,24 @@ func NewBCTAG(label string) (bf BCTAG) {
 }
 
 func (bt BCTAG) GetFLAG() (bf BCFLAG) {
+	var err error
+	if bf, err = bt.FindFLAG(); err != nil {
+		panic(err)
+	}
+	return
+}
+
+func (bt BCTAG) FindFLAG() (bf BCFLAG, err error) {
 	var (
 		base = string(bt)
 		ind  = strings.Index(base, "-")
 		ok   bool
-		err  error
 	)
 	if ind > 0 {
 		base = base[0:ind]
 	}
 	if bf, ok = BCNameMap[base]; !ok {
-		err = fmt.Errorf("unable to find BC with base name: [%s], full tag: [%s]\n", base, string(bt))
-		panic(err)
+		err = fmt.Errorf("unable to find BC with base name: [%s], full tag: [%s]", base, string(bt))
 	}
 	return
 }
@@ -138,6 +138,49 @@ func (c Curve) Print() {
 	}
 }
 
+func (c Curve) Components() (cs []Curve) {
+	/*
+		Splits the line segments into connected curves, segments sharing a vertex are in the same curve. The curves are
+		in order of their first segment in c, and the segments of each curve keep their order in c
+	*/
+	var (
+		vertEdges = make(map[int][]int) // Indices of the segments using each vertex
+		comp      = make([]int, len(c))
+		nComp     int
+	)
+	for i, e := range c {
+		for _, v := range e.GetVertices() {
+			vertEdges[v] = append(vertEdges[v], i)
+		}
+		comp[i] = -1
+	}
+	for i := range c {
+		if comp[i] != -1 {
+			continue
+		}
+		comp[i] = nComp
+		stack := []int{i}
+		for len(stack) != 0 {
+			j := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, v := range c[j].GetVertices() {
+				for _, jj := range vertEdges[v] {
+					if comp[jj] == -1 {
+						comp[jj] = nComp
+						stack = append(stack, jj)
+					}
+				}
+			}
+		}
+		nComp++
+	}
+	cs = make([]Curve, nComp)
+	for i, e := range c {
+		cs[comp[i]] = append(cs[comp[i]], e)
+	}
+	return
+}
+
 func (c Curve) ReOrder(reverse bool) (cc Curve, unordered bool) {
 	/*
 	   Orders a curve's line segments to form a connected curve
@@ -173,7 +216,16 @@ func (c Curve) ReOrder(reverse bool) (cc Curve, unordered bool) {
 		}
 	}
 	//fmt.Printf("End vertex index keys = %v\n", endKeys)
-	if endCount != 2 {
+	switch endCount {
+	case 0: // Closed curve, begin with the first edge and optionally traverse in the opposite direction
+		cc = AssembleCurve(bm, first)
+		if reverse {
+			for i, j := 1, len(cc)-1; i < j; i, j = i+1, j-1 {
+				cc[i], cc[j] = cc[j], cc[i]
+			}
+		}
+		return
+	case 1:
 		panic("unable to find two unconnected endKeys")
 	}
 	// Default is to use the first edge to begin the curve, assuming the first/last edges are really the endKeys
@@ -203,6 +255,7 @@ func AssembleCurve(bm bucketMap, start EdgeInt) (c Curve) {
 		verts [2]int
 		end   int
 		b     *vertEdgeBucket
+		first = start
 	)
 	verts = start.GetVertices()
 	// Check if conn is connectable, or is the "dangling" end
@@ -210,32 +263,26 @@ func AssembleCurve(bm bucketMap, start EdgeInt) (c Curve) {
 	if !bm.Connectable(end) {
 		end = verts[1]
 	}
-	// Begin connecting edges
-	c = make(Curve, len(bm)-1)
-	var ii int
-	c[ii] = start
-	ii++
-	for {
-		if !bm.Connectable(end) { // When we reach an unconnectable end, stop
+	// Begin connecting edges, an open curve has one more vertex than edges, a closed curve has the same number
+	c = make(Curve, 0, len(bm))
+	c = append(c, start)
+	for bm.Connectable(end) { // When we reach an unconnectable end, stop
+		b = bm[end]
+		e := b.vertEdge[0]
+		if e == start {
+			e = b.vertEdge[1]
+		}
+		if e == first { // The curve is closed and we have returned to the beginning
 			break
 		}
-		b = bm[end]
-		for i := 0; i < 2; i++ {
-			e := b.vertEdge[i]
-			if e != start {
-				c[ii] = e
-				ii++
-				verts = e.GetVertices()
-				if verts[0] == end {
-					end = verts[1]
-				} else {
-					end = verts[0]
-				}
-				start = e
-				goto Next
-			}
+		c = append(c, e)
+		verts = e.GetVertices()
+		if verts[0] == end {
+			end = verts[1]
+		} else {
+			end = verts[0]
 		}
-	Next:
+		start = e
 	}
 	return
 }
@@ -55,6 +55,9 @@ func TestTypes(t *testing.T) {
 			assert.Equal(t, flags[i], bt.GetFLAG())
 			assert.Equal(t, labels[i], bt.GetLabel())
 		}
+		_, err := NewBCTAG("Bogus-1").FindFLAG()
+		assert.NotNil(t, err)
+		assert.Panics(t, func() { NewBCTAG("Bogus-1").GetFLAG() })
 	}
 	{ // Test curve ordering/reordering
 		// combo of int1, int2 equals edges
@@ -74,4 +77,45 @@ func TestTypes(t *testing.T) {
 		assert.Equal(t, [2]int{40, 0}, edges[0].GetVertices())
 		assert.Equal(t, [2]int{37, 38}, edges[3].GetVertices())
 	}
+	{ // Test closed curve ordering
+		ind1 := []int{1, 3, 0, 2}
+		ind2 := []int{2, 0, 1, 3}
+		edges := make(Curve, len(ind1))
+		for i := range ind1 {
+			edges[i] = NewEdgeInt([2]int{ind1[i], ind2[i]})
+		}
+		cc, unordered := edges.ReOrder(false)
+		assert.Equal(t, false, unordered)
+		assert.Equal(t, 4, len(cc))
+		assert.Equal(t, edges[0], cc[0])
+		// Each edge shares a vertex with the next, including the last with the first
+		shared := func(e1, e2 EdgeInt) bool {
+			v1, v2 := e1.GetVertices(), e2.GetVertices()
+			return v1[0] == v2[0] || v1[0] == v2[1] || v1[1] == v2[0] || v1[1] == v2[1]
+		}
+		for i := range cc {
+			assert.True(t, shared(cc[i], cc[(i+1)%4]))
+		}
+		ccR, _ := edges.ReOrder(true)
+		assert.Equal(t, Curve{cc[0], cc[3], cc[2], cc[1]}, ccR)
+	}
+	{ // Test splitting into connected curves: two closed loops and an open chain, interleaved
+		ind1 := []int{10, 1, 20, 11, 3, 12, 0, 21, 2}
+		ind2 := []int{11, 2, 21, 12, 0, 10, 1, 22, 3}
+		edges := make(Curve, len(ind1))
+		for i := range ind1 {
+			edges[i] = NewEdgeInt([2]int{ind1[i], ind2[i]})
+		}
+		cs := edges.Components()
+		assert.Equal(t, []Curve{
+			{edges[0], edges[3], edges[5]},
+			{edges[1], edges[4], edges[6], edges[8]},
+			{edges[2], edges[7]},
+		}, cs)
+		assert.Equal(t, []Curve{{edges[0]}}, Curve{edges[0]}.Components())
+		for _, c := range cs {
+			cc, _ := c.ReOrder(false)
+			assert.Equal(t, len(c), len(cc))
+		}
+	}
 }
@@ -115,7 +115,7 @@ func (bm *BlockMatrix) LUPDecompose() (err error) {
 	for i := 0; i < N; i++ {
 		maxA = 0.
 		imax = i
-		for k := 0; k < N; k++ {
+		for k := i; k < N; k++ {
 			absA = math.Abs(mat.Det(A[k][i]))
 			if absA > maxA {
 				maxA = absA
@@ -134,10 +134,10 @@ func (bm *BlockMatrix) LUPDecompose() (err error) {
 			// counting pivots starting from N
 			bm.Pcount++
 		}
+		if Scratch, err = A[i][i].Inverse(); err != nil {
+			return
+		}
 		for j := i + 1; j < N; j++ {
-			if Scratch, err = A[i][i].Inverse(); err != nil {
-				return
-			}
 			A[j][i] = A[j][i].Mul(Scratch)
 			for k := i + 1; k < N; k++ {
 				A[j][k] = A[j][k].Subtract(A[j][i].Mul(A[i][k]))
@@ -202,7 +202,7 @@ func (bm BlockMatrix) LUPSolve(b []Matrix) (Bx BlockMatrix, err error) {
 		if Scratch, err = A[i][i].Inverse(); err != nil {
 			panic(err)
 		}
-		X[i][0] = X[i][0].Transpose().Mul(Scratch)
+		X[i][0] = Scratch.Mul(X[i][0]).Transpose()
 	}
 	for i := 0; i < N; i++ {
 		X[i][0] = X[i][0].Transpose()
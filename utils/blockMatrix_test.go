@@ -203,4 +203,39 @@ func TestBlockMatrix(t *testing.T) {
 			}
 		}
 	}
+	// [Matrix]: Test LU decomposition and solve with non symmetric blocks that require pivoting
+	{
+		N := 3
+		Bm := NewBlockMatrix(N, N)
+		for i := 0; i < N; i++ {
+			for j := 0; j < N; j++ {
+				data := make([]float64, 16)
+				for ii := range data {
+					data[ii] = float64((ii*7+i*5+j*3)%11) - 5.
+				}
+				if i == (j+1)%N { // Dominant blocks off the diagonal force a pivot
+					for ii := 0; ii < 4; ii++ {
+						data[ii*5] += 40. + float64(ii)
+					}
+				}
+				Bm.M[i][j] = NewMatrix(4, 4, data)
+			}
+		}
+		BmOrig := Bm.Copy()
+		b := make([]Matrix, N)
+		for i := 0; i < N; i++ {
+			b[i] = NewMatrix(4, 1, []float64{float64(i + 1), -2., 0.5, float64(3 - i)})
+		}
+		err := Bm.LUPDecompose()
+		assert.Nil(t, err)
+		x, err := Bm.LUPSolve(b)
+		assert.Nil(t, err)
+		for i := 0; i < N; i++ {
+			sum := NewMatrix(4, 1)
+			for j := 0; j < N; j++ {
+				sum = sum.Add(BmOrig.M[i][j].Mul(x.M[j][0]))
+			}
+			assert.InDeltaSlicef(t, b[i].DataP, sum.DataP, 0.0000001, "err msg %s")
+		}
+	}
 }